@@ -0,0 +1,463 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// Period is a span of time [start, end)
+type Period struct {
+	start, end time.Time
+}
+
+// NewPeriod creates a new period from start to end
+//
+// If end is not after start, NewPeriod returns domain_errors.ErrInvalidRange.
+func NewPeriod(start, end time.Time) (Period, error) {
+	if !end.After(start) {
+		return Period{}, domain_errors.ErrInvalidRange
+	}
+
+	return Period{start: start, end: end}, nil
+}
+
+// GetStart returns the time the period starts
+func (p Period) GetStart() time.Time {
+	return p.start
+}
+
+// GetEnd returns the time the period ends, excluded from it
+func (p Period) GetEnd() time.Time {
+	return p.end
+}
+
+// GetDuration returns how long the period lasts
+func (p Period) GetDuration() time.Duration {
+	return p.end.Sub(p.start)
+}
+
+// clip returns the part of the period within [from, to), and false if there is none
+func (p Period) clip(from, to time.Time) (Period, bool) {
+	if p.start.Before(from) {
+		p.start = from
+	}
+	if p.end.After(to) {
+		p.end = to
+	}
+
+	return p, p.end.After(p.start)
+}
+
+// mergePeriods sorts the periods and merges those which overlap or touch
+func mergePeriods(periods []Period) []Period {
+	slices.SortFunc(periods, func(a, b Period) int {
+		return a.start.Compare(b.start)
+	})
+
+	merged := periods[:0]
+	for _, p := range periods {
+		if last := len(merged) - 1; last >= 0 && !p.start.After(merged[last].end) {
+			if p.end.After(merged[last].end) {
+				merged[last].end = p.end
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+
+	return merged
+}
+
+// subtractPeriods returns the parts of the window not within any of the
+// periods, which must be merged
+func subtractPeriods(window Period, periods []Period) []Period {
+	var free []Period
+
+	start := window.start
+	for _, p := range periods {
+		if !p.end.After(start) {
+			continue
+		}
+		if !p.start.Before(window.end) {
+			break
+		}
+
+		if p.start.After(start) {
+			free = append(free, Period{start: start, end: p.start})
+		}
+		start = p.end
+	}
+
+	if window.end.After(start) {
+		free = append(free, Period{start: start, end: window.end})
+	}
+
+	return free
+}
+
+// FreeBusyQuery selects the busy and free time of calendars within [from, to)
+//
+// A task makes its time busy from its start to its end. Tasks without an
+// end, like reminders, don't make any time busy, nor do all-day tasks, like
+// holidays, unless WithAllDay is set. Deleted occurrences are not in a
+// calendar, so they are never busy.
+type FreeBusyQuery struct {
+	from, to time.Time
+	filter   busyFilter
+}
+
+// NewFreeBusyQuery creates a new query of the busy and free time within [from, to)
+//
+// If to is not after from, NewFreeBusyQuery returns domain_errors.ErrInvalidRange.
+func NewFreeBusyQuery(from, to time.Time) (*FreeBusyQuery, error) {
+	q := &FreeBusyQuery{from: from, to: to}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// WithoutCompleted makes completed tasks free their time
+func (q *FreeBusyQuery) WithoutCompleted() *FreeBusyQuery {
+	q.filter.skipCompleted = true
+	return q
+}
+
+// WithAllDay makes all-day tasks busy for their whole days
+func (q *FreeBusyQuery) WithAllDay() *FreeBusyQuery {
+	q.filter.allDay = true
+	return q
+}
+
+// Validate validates the query
+func (q *FreeBusyQuery) Validate() (errc error) {
+	if !q.to.After(q.from) {
+		errc = errors.Join(domain_errors.ErrInvalidRange, errc)
+	}
+
+	return errc
+}
+
+// GetBusy returns the time within the query's range when any of the calendars is busy
+//
+// The periods are sorted and merged: no two of them overlap or touch.
+// If a calendar is nil, GetBusy returns domain_errors.ErrCalendarCannotBeNil.
+func GetBusy(q *FreeBusyQuery, calendars ...*Calendar) ([]Period, error) {
+	if q == nil {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return busyPeriods(q.from, q.to, 0, q.filter, calendars)
+}
+
+// GetFree returns the time within the query's range when every one of the calendars is free
+//
+// If a calendar is nil, GetFree returns domain_errors.ErrCalendarCannotBeNil.
+func GetFree(q *FreeBusyQuery, calendars ...*Calendar) ([]Period, error) {
+	busy, err := GetBusy(q, calendars...)
+	if err != nil {
+		return nil, err
+	}
+
+	return subtractPeriods(Period{start: q.from, end: q.to}, busy), nil
+}
+
+// busyFilter selects the tasks which make their time busy
+type busyFilter struct {
+	skipCompleted bool
+	allDay        bool
+}
+
+// isBusy returns true if the task makes its time busy
+func (f busyFilter) isBusy(task *Task) bool {
+	if task.GetDuration() == 0 || (task.IsAllDay() && !f.allDay) {
+		return false
+	}
+
+	return !f.skipCompleted || !task.IsCompleted()
+}
+
+// busyPeriods returns the merged busy time of the calendars within [from, to),
+// every task making busy the buffer before and after it as well
+func busyPeriods(from, to time.Time, buffer time.Duration, filter busyFilter, calendars []*Calendar) ([]Period, error) {
+	var busy []Period
+
+	for _, c := range calendars {
+		if c == nil {
+			return nil, domain_errors.ErrCalendarCannotBeNil
+		}
+
+		c.index.overlapping(from.Add(-buffer), to.Add(buffer), nil, func(task *Task) bool {
+			if !filter.isBusy(task) {
+				return true
+			}
+
+			p := Period{start: task.GetTime().Add(-buffer), end: task.GetEndTime().Add(buffer)}
+			if p, ok := p.clip(from, to); ok {
+				busy = append(busy, p)
+			}
+
+			return true
+		})
+	}
+
+	return mergePeriods(busy), nil
+}
+
+// WorkingHours are the hours of some days of the week slots can be found in
+//
+// The hours are a wall clock time in the zone, so they follow its daylight
+// saving transitions.
+type WorkingHours struct {
+	start, end time.Duration
+	weekdays   []time.Weekday
+	zone       *time.Location
+}
+
+// NewWorkingHours creates working hours from start to end on the weekdays
+//
+// start and end are the wall clock times since midnight, e.g. 9*time.Hour and
+// 17*time.Hour for 9:00 to 17:00. A nil zone is UTC.
+// If the hours are not within a day, or there is no weekday,
+// NewWorkingHours returns domain_errors.ErrInvalidWorkingHours.
+func NewWorkingHours(start, end time.Duration, zone *time.Location, weekdays ...time.Weekday) (*WorkingHours, error) {
+	if zone == nil {
+		zone = time.UTC
+	}
+
+	wh := &WorkingHours{start: start, end: end, weekdays: weekdays, zone: zone}
+
+	if err := wh.Validate(); err != nil {
+		return nil, err
+	}
+
+	return wh, nil
+}
+
+// GetStart returns the wall clock time the working hours start at, since midnight
+func (wh *WorkingHours) GetStart() time.Duration {
+	return wh.start
+}
+
+// GetEnd returns the wall clock time the working hours end at, since midnight
+func (wh *WorkingHours) GetEnd() time.Duration {
+	return wh.end
+}
+
+// GetWeekdays returns the days of the week of the working hours
+func (wh *WorkingHours) GetWeekdays() []time.Weekday {
+	return slices.Clone(wh.weekdays)
+}
+
+// GetTimeZone returns the time zone of the working hours
+func (wh *WorkingHours) GetTimeZone() *time.Location {
+	return wh.zone
+}
+
+// Validate validates the working hours
+func (wh *WorkingHours) Validate() (errc error) {
+	if wh.start < 0 || wh.end > 24*time.Hour || wh.end <= wh.start {
+		errc = errors.Join(domain_errors.ErrInvalidWorkingHours, errc)
+	}
+
+	if len(wh.weekdays) == 0 {
+		errc = errors.Join(domain_errors.ErrInvalidWorkingHours, errc)
+	}
+
+	for _, weekday := range wh.weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			errc = errors.Join(domain_errors.ErrInvalidWorkingHours, errc)
+		}
+	}
+
+	return errc
+}
+
+// windows returns the working hours within [from, to)
+func (wh *WorkingHours) windows(from, to time.Time) []Period {
+	var windows []Period
+
+	year, month, day := from.In(wh.zone).Date()
+	for ; ; day++ {
+		start, _ := LocalTime(year, month, day, 0, 0, 0, int(wh.start), wh.zone, ShiftLocalTime)
+		if !start.Before(to) {
+			return windows
+		}
+
+		if !slices.Contains(wh.weekdays, time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday()) {
+			continue
+		}
+
+		end, _ := LocalTime(year, month, day, 0, 0, 0, int(wh.end), wh.zone, ShiftLocalTime)
+		if window, ok := (Period{start: start, end: end}).clip(from, to); ok {
+			windows = append(windows, window)
+		}
+	}
+}
+
+// SlotQuery searches calendars for free slots within [from, to)
+//
+// A slot is free when every calendar is free, as FreeBusyQuery tells, and it
+// is within the working hours, if there are any. Slots are at least as long
+// as the duration searched for, and keep a buffer away from busy time.
+type SlotQuery struct {
+	from, to time.Time
+	duration time.Duration
+	hours    []*WorkingHours
+	buffer   time.Duration
+	filter   busyFilter
+	limit    int
+}
+
+// NewSlotQuery creates a new search for slots of at least the duration within [from, to)
+//
+// The working hours, buffer and number of slots can be set through the With* methods.
+// If to is not after from, NewSlotQuery returns domain_errors.ErrInvalidRange;
+// if the duration is not positive, domain_errors.ErrInvalidDuration.
+func NewSlotQuery(from, to time.Time, duration time.Duration) (*SlotQuery, error) {
+	q := &SlotQuery{from: from, to: to, duration: duration}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// WithWorkingHours restricts the slots to the working hours, none for any time
+func (q *SlotQuery) WithWorkingHours(hours ...*WorkingHours) *SlotQuery {
+	q.hours = hours
+	return q
+}
+
+// WithBuffer keeps the slots at least the buffer away from busy time
+func (q *SlotQuery) WithBuffer(buffer time.Duration) *SlotQuery {
+	q.buffer = buffer
+	return q
+}
+
+// WithoutCompleted makes completed tasks free their time
+func (q *SlotQuery) WithoutCompleted() *SlotQuery {
+	q.filter.skipCompleted = true
+	return q
+}
+
+// WithAllDay makes all-day tasks busy for their whole days
+func (q *SlotQuery) WithAllDay() *SlotQuery {
+	q.filter.allDay = true
+	return q
+}
+
+// WithLimit sets the maximum number of slots found, 0 for no limit
+func (q *SlotQuery) WithLimit(limit int) *SlotQuery {
+	q.limit = limit
+	return q
+}
+
+// Validate validates the query
+func (q *SlotQuery) Validate() (errc error) {
+	if !q.to.After(q.from) {
+		errc = errors.Join(domain_errors.ErrInvalidRange, errc)
+	}
+
+	if q.duration <= 0 || q.buffer < 0 {
+		errc = errors.Join(domain_errors.ErrInvalidDuration, errc)
+	}
+
+	if q.limit < 0 {
+		errc = errors.Join(domain_errors.ErrInvalidPageSize, errc)
+	}
+
+	for _, wh := range q.hours {
+		if wh == nil {
+			errc = errors.Join(domain_errors.ErrInvalidWorkingHours, errc)
+		} else if err := wh.Validate(); err != nil {
+			errc = errors.Join(err, errc)
+		}
+	}
+
+	return errc
+}
+
+// FindSlots returns the free slots of the calendars, sorted by time
+//
+// Every slot is the whole free time between two busy periods or the edges
+// of the working hours, at least as long as the query's duration.
+// If a calendar is nil, FindSlots returns domain_errors.ErrCalendarCannotBeNil.
+func FindSlots(q *SlotQuery, calendars ...*Calendar) ([]Period, error) {
+	if q == nil {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	busy, err := busyPeriods(q.from, q.to, q.buffer, q.filter, calendars)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := []Period{{start: q.from, end: q.to}}
+	if len(q.hours) > 0 {
+		windows = nil
+		for _, wh := range q.hours {
+			windows = append(windows, wh.windows(q.from, q.to)...)
+		}
+		windows = mergePeriods(windows)
+	}
+
+	var slots []Period
+	for _, window := range windows {
+		for _, slot := range subtractPeriods(window, busy) {
+			if slot.GetDuration() < q.duration {
+				continue
+			}
+
+			slots = append(slots, slot)
+			if q.limit > 0 && len(slots) == q.limit {
+				return slots, nil
+			}
+		}
+	}
+
+	return slots, nil
+}
+
+// FindFirstSlot returns the earliest free slot of the calendars lasting exactly the query's duration
+//
+// If no slot is long enough, FindFirstSlot returns domain_errors.ErrNoAvailableSlot.
+// If a calendar is nil, it returns domain_errors.ErrCalendarCannotBeNil.
+func FindFirstSlot(q *SlotQuery, calendars ...*Calendar) (Period, error) {
+	if q == nil {
+		return Period{}, domain_errors.ErrInvalidRange
+	}
+
+	slots, err := FindSlots(&SlotQuery{
+		from:     q.from,
+		to:       q.to,
+		duration: q.duration,
+		hours:    q.hours,
+		buffer:   q.buffer,
+		filter:   q.filter,
+		limit:    1,
+	}, calendars...)
+	if err != nil {
+		return Period{}, err
+	}
+
+	if len(slots) == 0 {
+		return Period{}, domain_errors.ErrNoAvailableSlot
+	}
+
+	return Period{start: slots[0].start, end: slots[0].start.Add(q.duration)}, nil
+}
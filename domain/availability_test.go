@@ -0,0 +1,445 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestPeriod creates the period from start to end
+func newTestPeriod(t *testing.T, start, end time.Time) Period {
+	t.Helper()
+
+	p, err := NewPeriod(start, end)
+	assert.NoError(t, err)
+
+	return p
+}
+
+// newDailyTask creates a task repeating every day count times, lasting the given duration
+func newDailyTask(t *testing.T, at time.Time, duration time.Duration, count int) *Task {
+	t.Helper()
+
+	rule, err := NewRecurrenceRule(Daily, 1)
+	assert.NoError(t, err)
+
+	task, err := NewRecurringTask(NewTaskID(), "title", "description", rule.WithCount(count), at)
+	assert.NoError(t, err)
+	assert.NoError(t, task.SetDuration(duration))
+
+	return task
+}
+
+func TestNewPeriod(t *testing.T) {
+	start := time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC)
+
+	p, err := NewPeriod(start, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, start, p.GetStart())
+	assert.Equal(t, start.Add(time.Hour), p.GetEnd())
+	assert.Equal(t, time.Hour, p.GetDuration())
+
+	_, err = NewPeriod(start, start)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+}
+
+func TestMergePeriods(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, time.January, 10, hour, 0, 0, 0, time.UTC)
+	}
+
+	got := mergePeriods([]Period{
+		{start: at(14), end: at(15)},
+		{start: at(9), end: at(11)},
+		{start: at(10), end: at(12)},
+		{start: at(12), end: at(13)},
+		{start: at(9), end: at(10)},
+	})
+
+	assert.Equal(t, []Period{{start: at(9), end: at(13)}, {start: at(14), end: at(15)}}, got)
+}
+
+func TestGetBusy(t *testing.T) {
+	ctx := context.Background()
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.January, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// Alice has a daily stand-up, a long workshop and a completed review;
+	// Bob has a meeting overlapping the workshop, a reminder and a holiday
+	alice, bob := NewCalendar(), NewCalendar()
+
+	standUp := newDailyTask(t, at(8, 9, 0), 15*time.Minute, 5)
+	assert.NoError(t, alice.AddTask(ctx, standUp))
+	assert.NoError(t, alice.AddTask(ctx, newTimedTask(t, at(9, 13, 0), 2*time.Hour)))
+
+	review := newTimedTask(t, at(10, 16, 0), time.Hour)
+	review.Complete()
+	assert.NoError(t, alice.AddTask(ctx, review))
+
+	assert.NoError(t, bob.AddTask(ctx, newTimedTask(t, at(9, 14, 30), time.Hour)))
+	assert.NoError(t, bob.AddTask(ctx, newTimedTask(t, at(9, 11, 0), 0)))
+
+	holiday := newTestTask(t, at(11, 0, 0), false, 0)
+	assert.NoError(t, holiday.SetAllDay(1))
+	assert.NoError(t, bob.AddTask(ctx, holiday))
+
+	tests := []struct {
+		name          string
+		from, to      time.Time
+		skipCompleted bool
+		allDay        bool
+		want          []Period
+	}{
+		{
+			name: "Both calendars",
+			from: at(9, 0, 0),
+			to:   at(11, 0, 0),
+			want: []Period{
+				{start: at(9, 9, 0), end: at(9, 9, 15)},
+				{start: at(9, 13, 0), end: at(9, 15, 30)},
+				{start: at(10, 9, 0), end: at(10, 9, 15)},
+				{start: at(10, 16, 0), end: at(10, 17, 0)},
+			},
+		},
+		{
+			name:          "Without completed tasks",
+			from:          at(10, 0, 0),
+			to:            at(11, 0, 0),
+			skipCompleted: true,
+			want:          []Period{{start: at(10, 9, 0), end: at(10, 9, 15)}},
+		},
+		{
+			name: "Clipped to the range",
+			from: at(9, 14, 0),
+			to:   at(9, 15, 0),
+			want: []Period{{start: at(9, 14, 0), end: at(9, 15, 0)}},
+		},
+		{
+			name: "Holiday",
+			from: at(11, 10, 0),
+			to:   at(11, 18, 0),
+		},
+		{
+			name:   "Holiday counted as busy",
+			from:   at(10, 12, 0),
+			to:     at(13, 0, 0),
+			allDay: true,
+			want: []Period{
+				{start: at(10, 16, 0), end: at(10, 17, 0)},
+				{start: at(11, 0, 0), end: at(12, 0, 0)},
+				{start: at(12, 9, 0), end: at(12, 9, 15)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewFreeBusyQuery(tt.from, tt.to)
+			assert.NoError(t, err)
+			if tt.skipCompleted {
+				q.WithoutCompleted()
+			}
+			if tt.allDay {
+				q.WithAllDay()
+			}
+
+			got, err := GetBusy(q, alice, bob)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("Free", func(t *testing.T) {
+		q, err := NewFreeBusyQuery(at(9, 12, 0), at(9, 18, 0))
+		assert.NoError(t, err)
+
+		got, err := GetFree(q, alice, bob)
+		assert.NoError(t, err)
+		assert.Equal(t, []Period{{start: at(9, 12, 0), end: at(9, 13, 0)}, {start: at(9, 15, 30), end: at(9, 18, 0)}}, got)
+	})
+
+	t.Run("Nil calendar", func(t *testing.T) {
+		q, err := NewFreeBusyQuery(at(9, 0, 0), at(10, 0, 0))
+		assert.NoError(t, err)
+
+		_, err = GetBusy(q, alice, nil)
+		assert.ErrorIs(t, err, domain_errors.ErrCalendarCannotBeNil)
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		_, err := NewFreeBusyQuery(at(10, 0, 0), at(9, 0, 0))
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+
+		_, err = GetBusy(nil, alice)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+	})
+}
+
+func TestNewWorkingHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end time.Duration
+		weekdays   []time.Weekday
+		err        error
+	}{
+		{
+			name:     "Office hours",
+			start:    9 * time.Hour,
+			end:      17 * time.Hour,
+			weekdays: []time.Weekday{time.Monday, time.Friday},
+		},
+		{
+			name:     "Whole day",
+			end:      24 * time.Hour,
+			weekdays: []time.Weekday{time.Sunday},
+		},
+		{
+			name:     "Ending before starting",
+			start:    17 * time.Hour,
+			end:      9 * time.Hour,
+			weekdays: []time.Weekday{time.Monday},
+			err:      domain_errors.ErrInvalidWorkingHours,
+		},
+		{
+			name:     "Past midnight",
+			start:    22 * time.Hour,
+			end:      26 * time.Hour,
+			weekdays: []time.Weekday{time.Monday},
+			err:      domain_errors.ErrInvalidWorkingHours,
+		},
+		{
+			name:  "Without weekdays",
+			start: 9 * time.Hour,
+			end:   17 * time.Hour,
+			err:   domain_errors.ErrInvalidWorkingHours,
+		},
+		{
+			name:     "Invalid weekday",
+			start:    9 * time.Hour,
+			end:      17 * time.Hour,
+			weekdays: []time.Weekday{7},
+			err:      domain_errors.ErrInvalidWorkingHours,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh, err := NewWorkingHours(tt.start, tt.end, nil, tt.weekdays...)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.start, wh.GetStart())
+			assert.Equal(t, tt.end, wh.GetEnd())
+			assert.Equal(t, tt.weekdays, wh.GetWeekdays())
+			assert.Equal(t, time.UTC, wh.GetTimeZone())
+		})
+	}
+}
+
+func TestWorkingHours_windows(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	wh, err := NewWorkingHours(9*time.Hour, 17*time.Hour, berlin, time.Saturday, time.Monday)
+	assert.NoError(t, err)
+
+	// Clocks move forward on Sunday, March 31, 2024 in Berlin
+	from := time.Date(2024, time.March, 30, 12, 0, 0, 0, berlin)
+	to := time.Date(2024, time.April, 1, 10, 0, 0, 0, berlin)
+
+	assert.Equal(t, []Period{
+		{start: from, end: time.Date(2024, time.March, 30, 17, 0, 0, 0, berlin)},
+		{start: time.Date(2024, time.April, 1, 9, 0, 0, 0, berlin), end: to},
+	}, wh.windows(from, to))
+
+	// The UTC offset of the working hours follows the zone
+	windows := wh.windows(from, to)
+	assert.Equal(t, 16, windows[0].GetEnd().UTC().Hour())
+	assert.Equal(t, 7, windows[1].GetStart().UTC().Hour())
+}
+
+func TestFindSlots(t *testing.T) {
+	ctx := context.Background()
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.January, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// Monday, January 8 to Friday, January 12, 2024
+	from, to := at(8, 0, 0), at(13, 0, 0)
+
+	weekdays, err := NewWorkingHours(9*time.Hour, 17*time.Hour, time.UTC, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	assert.NoError(t, err)
+
+	// The week is busy from 9:00 to 16:30 every day, but for 30 minutes at
+	// 12:00 on Monday and 45 minutes at 11:15 on Wednesday
+	alice, bob := NewCalendar(), NewCalendar()
+
+	morning := newDailyTask(t, at(8, 9, 0), 3*time.Hour, 5)
+	assert.NoError(t, alice.AddTask(ctx, morning))
+
+	afternoon := newDailyTask(t, at(8, 12, 30), 4*time.Hour, 5)
+	assert.NoError(t, bob.AddTask(ctx, afternoon))
+
+	assert.NoError(t, alice.DeleteTask(ctx, findTask(t, alice, morning.id.primaryId, 10), ThisOccurrence))
+	assert.NoError(t, alice.AddTask(ctx, newTimedTask(t, at(10, 9, 0), 2*time.Hour+15*time.Minute)))
+	assert.NoError(t, bob.AddTask(ctx, newTimedTask(t, at(10, 12, 0), 30*time.Minute)))
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		hours    []*WorkingHours
+		buffer   time.Duration
+		limit    int
+		want     []Period
+	}{
+		{
+			name:     "45 minutes on weekdays",
+			duration: 45 * time.Minute,
+			hours:    []*WorkingHours{weekdays},
+			want: []Period{
+				{start: at(10, 11, 15), end: at(10, 12, 0)},
+			},
+		},
+		{
+			name:     "30 minutes on weekdays",
+			duration: 30 * time.Minute,
+			hours:    []*WorkingHours{weekdays},
+			want: []Period{
+				{start: at(8, 12, 0), end: at(8, 12, 30)},
+				{start: at(8, 16, 30), end: at(8, 17, 0)},
+				{start: at(9, 12, 0), end: at(9, 12, 30)},
+				{start: at(9, 16, 30), end: at(9, 17, 0)},
+				{start: at(10, 11, 15), end: at(10, 12, 0)},
+				{start: at(10, 16, 30), end: at(10, 17, 0)},
+				{start: at(11, 12, 0), end: at(11, 12, 30)},
+				{start: at(11, 16, 30), end: at(11, 17, 0)},
+				{start: at(12, 12, 0), end: at(12, 12, 30)},
+				{start: at(12, 16, 30), end: at(12, 17, 0)},
+			},
+		},
+		{
+			name:     "Limited",
+			duration: 30 * time.Minute,
+			hours:    []*WorkingHours{weekdays},
+			limit:    2,
+			want: []Period{
+				{start: at(8, 12, 0), end: at(8, 12, 30)},
+				{start: at(8, 16, 30), end: at(8, 17, 0)},
+			},
+		},
+		{
+			name:     "With a buffer",
+			duration: 30 * time.Minute,
+			hours:    []*WorkingHours{weekdays},
+			buffer:   5 * time.Minute,
+			want: []Period{
+				{start: at(10, 11, 20), end: at(10, 11, 55)},
+			},
+		},
+		{
+			name:     "Without working hours",
+			duration: 16 * time.Hour,
+			want: []Period{
+				{start: at(8, 16, 30), end: at(9, 9, 0)},
+				{start: at(9, 16, 30), end: at(10, 9, 0)},
+				{start: at(10, 16, 30), end: at(11, 9, 0)},
+				{start: at(11, 16, 30), end: at(12, 9, 0)},
+			},
+		},
+		{
+			name:     "Too long",
+			duration: time.Hour,
+			hours:    []*WorkingHours{weekdays},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewSlotQuery(from, to, tt.duration)
+			assert.NoError(t, err)
+			q.WithWorkingHours(tt.hours...).WithBuffer(tt.buffer).WithLimit(tt.limit)
+
+			got, err := FindSlots(q, alice, bob)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("First slot", func(t *testing.T) {
+		q, err := NewSlotQuery(from, to, 45*time.Minute)
+		assert.NoError(t, err)
+		q.WithWorkingHours(weekdays)
+
+		got, err := FindFirstSlot(q, alice, bob)
+		assert.NoError(t, err)
+		assert.Equal(t, newTestPeriod(t, at(10, 11, 15), at(10, 12, 0)), got)
+
+		// The slot can be booked under a conflict policy
+		alice.WithConflictPolicy(RejectConflicts)
+		defer alice.WithConflictPolicy(AllowConflicts)
+		assert.NoError(t, alice.AddTask(ctx, newTimedTask(t, got.GetStart(), got.GetDuration())))
+
+		_, err = FindFirstSlot(q, alice, bob)
+		assert.ErrorIs(t, err, domain_errors.ErrNoAvailableSlot)
+	})
+
+	t.Run("Without completed tasks", func(t *testing.T) {
+		c := NewCalendar()
+		done := newTimedTask(t, at(8, 9, 0), time.Hour)
+		done.Complete()
+		assert.NoError(t, c.AddTask(ctx, done))
+
+		q, err := NewSlotQuery(at(8, 9, 0), at(8, 10, 0), time.Hour)
+		assert.NoError(t, err)
+
+		_, err = FindFirstSlot(q, c)
+		assert.ErrorIs(t, err, domain_errors.ErrNoAvailableSlot)
+
+		got, err := FindFirstSlot(q.WithoutCompleted(), c)
+		assert.NoError(t, err)
+		assert.Equal(t, at(8, 9, 0), got.GetStart())
+	})
+
+	t.Run("All-day tasks", func(t *testing.T) {
+		c := NewCalendar()
+		holiday := newTestTask(t, at(8, 0, 0), false, 0)
+		assert.NoError(t, holiday.SetAllDay(1))
+		assert.NoError(t, c.AddTask(ctx, holiday))
+
+		q, err := NewSlotQuery(at(8, 0, 0), at(10, 0, 0), time.Hour)
+		assert.NoError(t, err)
+		q.WithWorkingHours(weekdays)
+
+		got, err := FindFirstSlot(q, c)
+		assert.NoError(t, err)
+		assert.Equal(t, at(8, 9, 0), got.GetStart())
+
+		got, err = FindFirstSlot(q.WithAllDay(), c)
+		assert.NoError(t, err)
+		assert.Equal(t, at(9, 9, 0), got.GetStart())
+	})
+
+	t.Run("Invalid query", func(t *testing.T) {
+		_, err := NewSlotQuery(from, to, 0)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidDuration)
+
+		_, err = NewSlotQuery(to, from, time.Hour)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+
+		q, err := NewSlotQuery(from, to, time.Hour)
+		assert.NoError(t, err)
+
+		_, err = FindSlots(q.WithBuffer(-time.Minute), alice)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidDuration)
+
+		_, err = FindSlots(q.WithBuffer(0).WithWorkingHours(nil), alice)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidWorkingHours)
+	})
+}
@@ -0,0 +1,919 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// Calendar is the aggregate root which owns the years and months of a calendar
+//
+// A Calendar is safe for concurrent use. Locking is fine grained: the
+// calendar only guards its maps, every Month and Day guards its own content,
+// and edits to a series are serialized per series. Tasks placed in the
+// calendar are never mutated; editing a series replaces its original task
+// with an updated copy.
+//
+// The tasks a calendar returns are shared with it, so they are read-only:
+// completing or changing them in place would race with its readers and
+// break its ordering. Use CompleteOccurrence, UpdateTask and DeleteTask.
+type Calendar struct {
+	id uuid.UUID
+	// mu guards years and series
+	mu    sync.RWMutex
+	years map[int]map[time.Month]*Month
+	// series holds the original tasks added to the calendar by primaryId
+	series map[uuid.UUID]*calendarSeries
+	// idSource generates the IDs of the tasks the calendar creates
+	idSource IDSource
+	// zone is the time zone tasks are placed in, nil for their own
+	zone *time.Location
+	// index holds every placed task by the time it spans
+	index *intervalIndex
+	// conflicts is the policy for tasks added or moved on top of others
+	conflicts ConflictPolicy
+	// booking serializes checking and placing tasks under a conflict policy
+	booking sync.Mutex
+	// placing is held for reading while tasks are placed on or looked up by
+	// their days, and for writing while WithTimeZone moves all of them
+	placing sync.RWMutex
+	// horizon is how far unbounded series added with AddTask are placed at least
+	horizon time.Time
+}
+
+// DefaultHorizon is how far from its start an unbounded series added with
+// AddTask is placed, see Calendar.ExtendHorizon
+const DefaultHorizon = 365 * 24 * time.Hour
+
+// MaxPlacedOccurrences is how many occurrences of an unbounded series are
+// placed at most at once, by AddTask or by every call to ExtendHorizon
+//
+// A series repeating every minute or second reaches it long before its
+// horizon, the following occurrences are placed as the horizon is extended.
+const MaxPlacedOccurrences = 10000
+
+// calendarSeries is an original task together with the window its
+// occurrences were generated for
+type calendarSeries struct {
+	// mu serializes the edits of the series and guards task
+	mu       sync.Mutex
+	task     *Task
+	windowed bool
+	from, to time.Time
+	// stored holds the restored occurrences which replace the expanded ones
+	stored map[OccurrenceKey]*Task
+	// horizon is the end of the placed occurrences of an unbounded series
+	// added with AddTask
+	horizon time.Time
+}
+
+// NewCalendar creates a new calendar
+func NewCalendar() *Calendar {
+	return &Calendar{
+		id:       uuid.New(),
+		years:    make(map[int]map[time.Month]*Month),
+		series:   make(map[uuid.UUID]*calendarSeries),
+		idSource: defaultIDSource,
+		index:    newIntervalIndex(),
+	}
+}
+
+// WithIDSource sets the source of the IDs of the tasks the calendar creates
+//
+// With a DeterministicIDSource, every calendar expanding a series creates
+// the same copy IDs for its occurrences. A nil source restores random IDs.
+func (c *Calendar) WithIDSource(source IDSource) *Calendar {
+	if source == nil {
+		source = defaultIDSource
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.idSource = source
+
+	return c
+}
+
+// WithTimeZone sets the time zone the days and months of the calendar are computed in
+//
+// By default every task is placed on the day of its own time zone. With a
+// viewer's zone, a task at 23:00 in New York is placed on the next day for a
+// viewer in Berlin. The tasks already placed move to the days of the new
+// zone, waiting for the edits in progress. A nil zone restores the default.
+// If any task cannot be placed on its new days, the calendar keeps its zone
+// and days, and WithTimeZone returns a *domain_errors.OccurrenceError for
+// every such task.
+func (c *Calendar) WithTimeZone(zone *time.Location) error {
+	c.placing.Lock()
+	defer c.placing.Unlock()
+
+	var tasks []*Task
+	seen := make(map[*Task]bool)
+	for _, m := range c.getMonths() {
+		for _, d := range m.getDays() {
+			for _, task := range d.getTasks() {
+				// A task spanning several days is placed on each of them
+				if !seen[task] {
+					seen[task] = true
+					tasks = append(tasks, task)
+				}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	previousZone, previousYears := c.zone, c.years
+	c.zone = zone
+	c.years = make(map[int]map[time.Month]*Month)
+	c.mu.Unlock()
+
+	var errc error
+	for _, task := range tasks {
+		if err := c.placeOnDays(task); err != nil {
+			errc = errors.Join(errc, &domain_errors.OccurrenceError{Occurrence: task.GetOccurrenceTime(), Err: err})
+		}
+	}
+
+	// The days of the previous zone were left untouched
+	if errc != nil {
+		c.mu.Lock()
+		c.zone, c.years = previousZone, previousYears
+		c.mu.Unlock()
+	}
+
+	return errc
+}
+
+// WithConflictPolicy sets what happens when a task is added or moved on top of others
+//
+// See ConflictPolicy. By default conflicts are allowed and not checked.
+func (c *Calendar) WithConflictPolicy(policy ConflictPolicy) *Calendar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conflicts = policy
+
+	return c
+}
+
+// GetConflictPolicy returns what happens when a task is added or moved on top of others
+func (c *Calendar) GetConflictPolicy() ConflictPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.conflicts
+}
+
+// GetTimeZone returns the time zone the calendar places tasks in, or nil
+// when every task is placed in its own
+func (c *Calendar) GetTimeZone() *time.Location {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.zone
+}
+
+// inZone returns the time in the calendar's time zone
+func (c *Calendar) inZone(t time.Time) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.zone == nil {
+		return t
+	}
+
+	return t.In(c.zone)
+}
+
+// getIDSource returns the source of the IDs of the tasks the calendar creates
+func (c *Calendar) getIDSource() IDSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.idSource
+}
+
+// RehydrateCalendar recreates an empty persisted calendar with its ID
+//
+// The series of the calendar are restored through AddTask and AddTaskBetween.
+// If the ID is nil, RehydrateCalendar returns domain_errors.ErrInvalidCalendarID.
+func RehydrateCalendar(id uuid.UUID) (*Calendar, error) {
+	if id == uuid.Nil {
+		return nil, domain_errors.ErrInvalidCalendarID
+	}
+
+	c := NewCalendar()
+	c.id = id
+
+	return c, nil
+}
+
+// GetID returns the calendar ID
+func (c *Calendar) GetID() uuid.UUID {
+	return c.id
+}
+
+// addMonth adds a month to the calendar
+//
+// If the month already exists, addMonth returns the existing month.
+// If the month is invalid, addMonth returns domain_errors.ErrAddMonth.
+func (c *Calendar) addMonth(month time.Month, year int) (*Month, error) {
+	if m, err := c.getMonth(month, year); err == nil {
+		return m, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	months, exists := c.years[year]
+	if !exists {
+		months = make(map[time.Month]*Month, 12)
+	}
+
+	if m, exists := months[month]; exists {
+		return m, nil
+	}
+
+	m, err := NewMonth(month, year)
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrAddMonth, err)
+	}
+
+	months[month] = m
+	c.years[year] = months
+
+	return m, nil
+}
+
+// getMonth returns the month of the given year
+func (c *Calendar) getMonth(month time.Month, year int) (*Month, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	m, exists := c.years[year][month]
+	if !exists {
+		return nil, domain_errors.ErrMonthNotFound
+	}
+
+	return m, nil
+}
+
+// GetMonth returns the month of the given year
+//
+// If the calendar has no tasks in that month, GetMonth returns domain_errors.ErrMonthNotFound.
+func (c *Calendar) GetMonth(month time.Month, year int) (*Month, error) {
+	return c.getMonth(month, year)
+}
+
+// AddTask adds a task and every one of its repetitions to the calendar
+//
+// The task itself is placed on its own day, and a copy is created through
+// the calendar's IDSource for every other date returned by its repetition.
+// A series without COUNT or UNTIL is placed up to the calendar's horizon, at
+// least DefaultHorizon from its start, and no more than MaxPlacedOccurrences
+// occurrences of it, see ExtendHorizon.
+// If the task is nil, AddTask returns domain_errors.ErrTaskCannotBeNil.
+// If any occurrence cannot be created or placed, AddTask still places the
+// others and returns domain_errors.ErrAddTask joined with a
+// *domain_errors.OccurrenceError for every failed occurrence.
+// Under a conflict policy, AddTask returns a *ConflictError listing the tasks
+// the occurrences overlap, see ConflictPolicy.
+func (c *Calendar) AddTask(ctx context.Context, task *Task) error {
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	return c.addSeries(ctx, &calendarSeries{task: task})
+}
+
+// AddTaskBetween adds the occurrences of a task within [from, to) to the calendar
+//
+// The window may span any number of months and years. The task itself is
+// only placed when its own time falls within the window.
+// If to is not after from, AddTaskBetween returns domain_errors.ErrInvalidRange.
+func (c *Calendar) AddTaskBetween(ctx context.Context, task *Task, from, to time.Time) error {
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	if !to.After(from) {
+		return errors.Join(domain_errors.ErrAddTask, domain_errors.ErrInvalidRange)
+	}
+
+	return c.addSeries(ctx, &calendarSeries{task: task, windowed: true, from: from, to: to})
+}
+
+// addSeries registers the series and places its occurrences
+//
+// Occurrences are pulled from the task lazily, so nothing is left running
+// when addSeries returns early. A series rejected by the conflict policy is
+// not registered.
+func (c *Calendar) addSeries(ctx context.Context, s *calendarSeries) error {
+	task := s.task
+
+	if err := task.Validate(); err != nil {
+		return errors.Join(domain_errors.ErrAddTask, domain_errors.ErrInvalidTask, err)
+	}
+
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defer c.lockBooking()()
+
+	planned := c.GetHorizon()
+	s.horizon = c.horizonOf(task, planned)
+
+	plan, err := c.planSeries(ctx, s, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.registerSeries(ctx, s, plan, planned)
+}
+
+// registerSeries adds the series to the calendar and places its plan
+//
+// The plan was made for the calendar's horizon planned; a horizon extended
+// while the series was planned is applied to it.
+// The caller must hold the series lock and the booking.
+func (c *Calendar) registerSeries(ctx context.Context, s *calendarSeries, plan *seriesPlan, planned time.Time) error {
+	c.mu.Lock()
+	c.series[s.task.id.primaryId] = s
+	horizon := c.horizon
+	c.mu.Unlock()
+
+	err := c.placePlan(plan)
+	if horizon.After(planned) {
+		err = errors.Join(err, c.extendSeries(ctx, s, horizon))
+	}
+
+	return err
+}
+
+// GetHorizon returns the time up to which ExtendHorizon placed unbounded
+// series, or the zero time
+func (c *Calendar) GetHorizon() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.horizon
+}
+
+// ExtendHorizon places the occurrences of the unbounded series added with
+// AddTask up to the given time
+//
+// Such series are only placed DefaultHorizon from their start, so the horizon
+// is meant to roll forward as time passes. It applies to the series added
+// afterwards as well, and a time before the current horizon leaves it as is.
+// Every call places no more than MaxPlacedOccurrences occurrences of each
+// series, the next call with the same time places the following ones.
+// Occurrences are checked against the conflict policy like AddTask checks
+// them, and the errors of every series are joined.
+func (c *Calendar) ExtendHorizon(ctx context.Context, until time.Time) error {
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	c.mu.Lock()
+	if until.After(c.horizon) {
+		c.horizon = until
+	}
+
+	series := make([]*calendarSeries, 0, len(c.series))
+	for _, s := range c.series {
+		series = append(series, s)
+	}
+	c.mu.Unlock()
+
+	var errc error
+	for _, s := range series {
+		errc = errors.Join(errc, c.extendRegistered(ctx, s, until))
+	}
+
+	return errc
+}
+
+// extendRegistered extends the series to the horizon unless it was removed meanwhile
+func (c *Calendar) extendRegistered(ctx context.Context, s *calendarSeries, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.mu.RLock()
+	current := c.series[s.task.id.primaryId]
+	c.mu.RUnlock()
+
+	if current != s {
+		return nil
+	}
+
+	defer c.lockBooking()()
+
+	return c.extendSeries(ctx, s, until)
+}
+
+// extendSeries places the occurrences of an unbounded series added with
+// AddTask from its horizon up to the given time, or its next
+// MaxPlacedOccurrences occurrences
+//
+// The caller must hold the series lock and the booking.
+func (c *Calendar) extendSeries(ctx context.Context, s *calendarSeries, until time.Time) error {
+	if s.windowed || !until.After(s.horizon) {
+		return nil
+	}
+
+	if rule := s.task.GetRecurrenceRule(); rule == nil || rule.IsBounded() {
+		return nil
+	}
+
+	until = s.task.placementEnd(s.horizon, until)
+
+	plan, err := c.planSeries(ctx, &calendarSeries{task: s.task, windowed: true, from: s.horizon, to: until, stored: s.stored}, nil)
+	if err != nil {
+		return err
+	}
+	s.horizon = until
+
+	return c.placePlan(plan)
+}
+
+// horizonOf returns how far the task is placed when it is an unbounded series
+// added with AddTask, under the calendar's horizon given
+//
+// The horizon is cut short after MaxPlacedOccurrences occurrences.
+func (c *Calendar) horizonOf(task *Task, calendarHorizon time.Time) time.Time {
+	horizon := task.GetTime().Add(DefaultHorizon)
+	if calendarHorizon.After(horizon) {
+		horizon = calendarHorizon
+	}
+
+	return task.placementEnd(task.GetTime(), horizon)
+}
+
+// seriesPlan holds the tasks placing a series adds to the calendar
+type seriesPlan struct {
+	// original is the series' task, nil when it is not placed
+	original *Task
+	copies   []*Task
+	// errc holds the occurrences which could not be created
+	errc error
+	// conflict is the *ConflictError reported by WarnConflicts
+	conflict error
+}
+
+// planSeries returns the series' task, when within the series' window, and
+// a copy for every other occurrence within it, checked against the conflict policy
+//
+// Tasks of the series itself, and those ignore matches, are not conflicts.
+// If the policy rejects the tasks, planSeries returns domain_errors.ErrAddTask
+// joined with a *ConflictError.
+// The caller must hold the series lock.
+func (c *Calendar) planSeries(ctx context.Context, s *calendarSeries, ignore func(*Task) bool) (*seriesPlan, error) {
+	task := s.task
+	plan := &seriesPlan{}
+
+	from, to := s.window()
+
+	plan.original, plan.copies, plan.errc = task.mergeOccurrences(ctx, from, to, s.stored, NewCopyTaskIDFactory(c.getIDSource()))
+
+	tasks := plan.copies
+	if plan.original != nil {
+		tasks = append([]*Task{plan.original}, tasks...)
+	}
+
+	conflict, err := c.checkConflicts(task, tasks, ignore)
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrAddTask, err)
+	}
+	plan.conflict = conflict
+
+	return plan, nil
+}
+
+// window returns the window the occurrences of the series are placed within
+//
+// A zero from or to leaves that side of the window open.
+func (s *calendarSeries) window() (from, to time.Time) {
+	if s.windowed {
+		return s.from, s.to
+	}
+
+	if s.horizon.IsZero() {
+		return s.task.defaultWindow()
+	}
+
+	if rule := s.task.GetRecurrenceRule(); rule == nil || rule.IsBounded() {
+		return time.Time{}, time.Time{}
+	}
+
+	return s.task.GetTime(), s.horizon
+}
+
+// span returns the range the tasks of the series are placed within
+//
+// It is the window of the series from its start, widened to its overrides
+// which are moved out of it. A zero to leaves the range open.
+func (s *calendarSeries) span() (from, to time.Time) {
+	from, to = s.window()
+	if start := s.task.GetTime(); start.After(from) {
+		from = start
+	}
+
+	for _, override := range s.task.overrides {
+		start := override.GetTime()
+		if start.Before(from) {
+			from = start
+		}
+
+		if !to.IsZero() && !start.Before(to) {
+			to = start.Add(time.Nanosecond)
+		}
+	}
+
+	return from, to
+}
+
+// placesOriginal reports whether the series' task is placed at its own time
+//
+// It is not when its time is out of the series' window, or when its own
+// occurrence was cancelled or overridden.
+func (s *calendarSeries) placesOriginal() bool {
+	from, to := s.window()
+	return s.task.placesOriginalWithin(from, to)
+}
+
+// placePlan places the tasks of the plan
+//
+// If the original task cannot be placed, placePlan returns
+// domain_errors.ErrAddTask joined with the error. Otherwise every copy is
+// placed, and those which cannot be are reported as a
+// *domain_errors.OccurrenceError joined with domain_errors.ErrAddTask.
+// Conflicts WarnConflicts reports are returned along with them.
+func (c *Calendar) placePlan(plan *seriesPlan) error {
+	if plan.original != nil {
+		if err := c.placeTask(plan.original); err != nil {
+			return errors.Join(domain_errors.ErrAddTask, err)
+		}
+	}
+
+	errc := plan.errc
+	for _, t := range plan.copies {
+		if err := c.placeTask(t); err != nil {
+			errc = errors.Join(errc, &domain_errors.OccurrenceError{Occurrence: t.GetOccurrenceTime(), Err: err})
+		}
+	}
+
+	if errc != nil {
+		return errors.Join(domain_errors.ErrAddTask, errc, plan.conflict)
+	}
+
+	return plan.conflict
+}
+
+// placeTask adds the task to every day it spans, creating months and days if needed
+//
+// A task spanning several days is the same task on each of them.
+func (c *Calendar) placeTask(task *Task) error {
+	if err := c.placeOnDays(task); err != nil {
+		return err
+	}
+
+	c.index.insert(task)
+
+	return nil
+}
+
+// placeOnDays adds the task to every day it spans, or to none of them
+//
+// Every day is looked up, or created, before the task is added to any of
+// them, and the days it was added to are undone if one of them fails.
+func (c *Calendar) placeOnDays(task *Task) error {
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	dates := c.daysOf(task)
+	days := make([]*Day, 0, len(dates))
+	for _, date := range dates {
+		d, err := c.addDayOn(date)
+		if err != nil {
+			return err
+		}
+		days = append(days, d)
+	}
+
+	for i, d := range days {
+		if err := d.addTask(task); err != nil {
+			for _, placed := range days[:i] {
+				placed.removeTasks(func(t *Task) bool { return t == task })
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addDayOn returns the day of the date, creating it and its month if needed
+func (c *Calendar) addDayOn(date civilDate) (*Day, error) {
+	m, err := c.addMonth(date.month, date.year)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, err := m.getDay(date.day); err == nil {
+		return d, nil
+	}
+
+	return m.addDay(date.day)
+}
+
+// daysOf returns the dates of the days the task spans
+//
+// The days are the ones the task falls on in the calendar's time zone, while
+// an all-day task keeps the dates of its own time zone. A task is on the day
+// it starts, and on every following day starting before it ends.
+func (c *Calendar) daysOf(task *Task) []civilDate {
+	start, end := c.inZone(task.GetTime()), c.inZone(task.GetEndTime())
+	if task.IsAllDay() {
+		start, end = task.GetTime(), task.GetEndTime()
+	}
+
+	year, month, day := start.Date()
+	dates := []civilDate{{year: year, month: month, day: day}}
+
+	for {
+		next := newCivilDate(year, month, day+len(dates))
+
+		midnight, _ := LocalTime(next.year, next.month, next.day, 0, 0, 0, 0, start.Location(), ShiftLocalTime)
+		if !midnight.Before(end) {
+			return dates
+		}
+
+		dates = append(dates, next)
+	}
+}
+
+// replacePlaced replaces the placed task with its new version on every day it spans
+//
+// Days the task is not on are skipped.
+func (c *Calendar) replacePlaced(old, task *Task) error {
+	for _, date := range c.daysOf(old) {
+		m, err := c.getMonth(date.month, date.year)
+		if err != nil {
+			continue
+		}
+
+		d, err := m.getDay(date.day)
+		if err != nil {
+			continue
+		}
+
+		if err := d.replaceTask(old, task); err != nil && !errors.Is(err, domain_errors.ErrTaskNotFound) {
+			return err
+		}
+	}
+
+	c.index.replace(old, task)
+
+	return nil
+}
+
+// getSeries returns the series the task belongs to, locked
+//
+// The caller must unlock the series. If the series is not in the calendar,
+// getSeries returns domain_errors.ErrTaskNotFound.
+func (c *Calendar) getSeries(task *Task) (*calendarSeries, error) {
+	c.mu.RLock()
+	s, exists := c.series[task.id.primaryId]
+	c.mu.RUnlock()
+
+	if !exists {
+		return nil, domain_errors.ErrTaskNotFound
+	}
+
+	s.mu.Lock()
+
+	// The series may have been deleted while waiting for its lock
+	c.mu.RLock()
+	current := c.series[task.id.primaryId]
+	c.mu.RUnlock()
+
+	if current != s {
+		s.mu.Unlock()
+		return nil, domain_errors.ErrTaskNotFound
+	}
+
+	return s, nil
+}
+
+// getMonths returns a snapshot of the months of the calendar
+func (c *Calendar) getMonths() []*Month {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var months []*Month
+	for _, yearMonths := range c.years {
+		for _, m := range yearMonths {
+			months = append(months, m)
+		}
+	}
+
+	return months
+}
+
+// GetTasksOn returns a snapshot of the tasks placed on the day of the given date, sorted by time
+//
+// The day is the one the date falls on in the calendar's time zone.
+// If the calendar has no such month or day, GetTasksOn returns
+// domain_errors.ErrMonthNotFound or domain_errors.ErrDayNotFound.
+func (c *Calendar) GetTasksOn(date time.Time) ([]*Task, error) {
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	d, err := c.getDayOf(date)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.getTasks(), nil
+}
+
+// GetSeries returns the original task of every series in the calendar, sorted by time
+func (c *Calendar) GetSeries() []*Task {
+	c.mu.RLock()
+	series := make([]*calendarSeries, 0, len(c.series))
+	for _, s := range c.series {
+		series = append(series, s)
+	}
+	c.mu.RUnlock()
+
+	tasks := make([]*Task, 0, len(series))
+	for _, s := range series {
+		s.mu.Lock()
+		tasks = append(tasks, s.task)
+		s.mu.Unlock()
+	}
+
+	slices.SortFunc(tasks, CompareTasks)
+
+	return tasks
+}
+
+// GetSeriesWindow returns the window the series was added for with AddTaskBetween
+//
+// If the series was added with AddTask or is not in the calendar, ok is false.
+func (c *Calendar) GetSeriesWindow(primaryId uuid.UUID) (from, to time.Time, ok bool) {
+	c.mu.RLock()
+	s, exists := c.series[primaryId]
+	c.mu.RUnlock()
+
+	if !exists {
+		return time.Time{}, time.Time{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.from, s.to, s.windowed
+}
+
+// RestoreOccurrences replaces the expanded occurrences of the calendar's
+// series with the stored ones, matched by primaryId and occurrence time
+//
+// The stored occurrences are kept with their series, so they are placed again
+// whenever the series is. An expanded occurrence which is completed while the
+// stored one is not is kept, and overridden occurrences keep their override.
+// Every occurrence which cannot be restored is reported as a
+// *domain_errors.OccurrenceError, joined with domain_errors.ErrTaskNotFound
+// when its series is not in the calendar, domain_errors.ErrInvalidTaskID when
+// it is not a copy of its series or domain_errors.ErrOccurrenceNotFound when
+// its series no longer has it.
+func (c *Calendar) RestoreOccurrences(stored ...*Task) error {
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	var errc error
+	for _, task := range stored {
+		if task == nil {
+			errc = errors.Join(errc, domain_errors.ErrTaskCannotBeNil)
+			continue
+		}
+
+		if err := c.restoreOccurrence(task); err != nil {
+			errc = errors.Join(errc, &domain_errors.OccurrenceError{Occurrence: task.GetOccurrenceTime(), Err: err})
+		}
+	}
+
+	return errc
+}
+
+// restoreOccurrence replaces the expanded occurrence of the task's series with the task
+func (c *Calendar) restoreOccurrence(task *Task) error {
+	if task.id == nil {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	s, err := c.getSeries(task)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	return c.storeOccurrence(s, task)
+}
+
+// storeOccurrence keeps the task with its series in place of the expanded
+// occurrence, and replaces the placed one with it
+//
+// The caller must hold the series lock.
+func (c *Calendar) storeOccurrence(s *calendarSeries, task *Task) error {
+	if err := s.task.validateStoredOccurrence(task); err != nil {
+		return err
+	}
+
+	occurrence := task.GetOccurrenceTime()
+	if !s.task.hasOccurrence(occurrence) {
+		return domain_errors.ErrOccurrenceNotFound
+	}
+
+	if _, overridden := s.task.GetOverride(occurrence); overridden {
+		return nil
+	}
+
+	key := s.task.occurrenceKey(occurrence)
+	if existing, exists := s.stored[key]; exists {
+		task = preferredOccurrence(existing, task)
+	}
+
+	if s.stored == nil {
+		s.stored = make(map[OccurrenceKey]*Task)
+	}
+	s.stored[key] = task
+
+	// The occurrence is only placed if it is within the series' window
+	d, err := c.getDayOf(occurrence)
+	if err != nil {
+		return nil
+	}
+
+	for _, placed := range d.getTasks() {
+		if placed.id.primaryId != task.id.primaryId || placed.id.original || !placed.GetOccurrenceTime().Equal(occurrence) {
+			continue
+		}
+
+		if preferred := preferredOccurrence(placed, task); preferred != placed {
+			return c.replacePlaced(placed, preferred)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// GetCompletedOccurrences returns the completed copies placed in the calendar, sorted by time
+//
+// Together with the series these are all that needs to be stored, see
+// RestoreOccurrences. Completed overrides are stored with their series.
+func (c *Calendar) GetCompletedOccurrences() []*Task {
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	series := make(map[uuid.UUID]*Task)
+	for _, task := range c.GetSeries() {
+		series[task.id.primaryId] = task
+	}
+
+	var tasks []*Task
+	seen := make(map[*Task]bool)
+	for _, m := range c.getMonths() {
+		for _, d := range m.getDays() {
+			for _, task := range d.getTasks() {
+				// A task spanning several days is placed on each of them
+				if task.id.original || !task.IsCompleted() || seen[task] {
+					continue
+				}
+				seen[task] = true
+
+				if master, exists := series[task.id.primaryId]; exists && master.isOverride(task) {
+					continue
+				}
+
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	slices.SortFunc(tasks, CompareTasks)
+
+	return tasks
+}
@@ -0,0 +1,536 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// EditScope selects which occurrences of a recurring series an edit applies to
+type EditScope int
+
+const (
+	// ThisOccurrence edits only the selected occurrence
+	ThisOccurrence EditScope = iota
+	// ThisAndFollowing splits the series at the selected occurrence and edits
+	// it together with every later one
+	ThisAndFollowing
+	// AllOccurrences rewrites the whole series
+	AllOccurrences
+)
+
+// UpdateTask applies the edited task to the occurrences of target's series selected by scope
+//
+// target is any task placed in the calendar, the original or one of its copies.
+// The title, description, priority, time and recurrence rule are taken from
+// edited, its ID is ignored. When edited has no recurrence rule the series
+// keeps its own.
+//
+//   - ThisOccurrence turns the edited task into an override of the target's
+//     occurrence, so only that occurrence changes and may move.
+//   - ThisAndFollowing ends the series before the target's occurrence and
+//     starts a new series, with a new primaryId, at the edited time.
+//   - AllOccurrences rewrites the series keeping its primaryId; moving the
+//     target moves every occurrence by the same offset. Exceptions and
+//     overrides of the series are discarded.
+//
+// If the target's series is not in the calendar, UpdateTask returns domain_errors.ErrTaskNotFound.
+// Under a conflict policy, the occurrences the edit places are checked like
+// AddTask checks them; a rejected edit leaves the series unchanged.
+func (c *Calendar) UpdateTask(ctx context.Context, target, edited *Task, scope EditScope) error {
+	if target == nil || edited == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	s, err := c.getSeries(target)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	defer c.lockBooking()()
+
+	occurrence := target.GetOccurrenceTime()
+
+	if scope == ThisAndFollowing && occurrence.Equal(s.task.GetTime()) {
+		scope = AllOccurrences
+	}
+
+	switch scope {
+	case ThisOccurrence:
+		return c.updateOccurrence(s, target, edited)
+	case ThisAndFollowing:
+		return c.splitSeries(ctx, s, occurrence, edited)
+	case AllOccurrences:
+		return c.rewriteSeries(ctx, s, occurrence, edited)
+	default:
+		return domain_errors.ErrInvalidEditScope
+	}
+}
+
+// DeleteTask deletes the occurrences of target's series selected by scope
+//
+//   - ThisOccurrence excludes the target's occurrence from the series. When it
+//     is the first one, the series' original task moves to the next
+//     occurrence instead; a series left without occurrences is removed.
+//   - ThisAndFollowing ends the series before the target's occurrence.
+//   - AllOccurrences removes the whole series from the calendar.
+//
+// If the target's series is not in the calendar, DeleteTask returns domain_errors.ErrTaskNotFound.
+// If the context is done, DeleteTask returns its error and deletes nothing.
+func (c *Calendar) DeleteTask(ctx context.Context, target *Task, scope EditScope) error {
+	if target == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	s, err := c.getSeries(target)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	defer c.lockBooking()()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	occurrence := target.GetOccurrenceTime()
+
+	if scope == ThisAndFollowing && occurrence.Equal(s.task.GetTime()) {
+		scope = AllOccurrences
+	}
+
+	switch scope {
+	case ThisOccurrence:
+		return c.deleteOccurrence(s, occurrence)
+	case ThisAndFollowing:
+		return c.truncateSeries(s, occurrence)
+	case AllOccurrences:
+		c.removeSeries(s)
+	default:
+		return domain_errors.ErrInvalidEditScope
+	}
+
+	return nil
+}
+
+// CompleteOccurrence marks the target's occurrence of its series as completed
+//
+// target is any task placed in the calendar. Placed tasks are read-only, so
+// the occurrence is replaced with a completed copy: a copy is kept with its
+// series like a restored occurrence, while an override or the original
+// complete through an updated series.
+// If the target's series is not in the calendar, CompleteOccurrence returns
+// domain_errors.ErrTaskNotFound; if it no longer has the occurrence,
+// domain_errors.ErrOccurrenceNotFound.
+// If the context is done, CompleteOccurrence returns its error.
+func (c *Calendar) CompleteOccurrence(ctx context.Context, target *Task) error {
+	if target == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	if target.id == nil {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.placing.RLock()
+	defer c.placing.RUnlock()
+
+	s, err := c.getSeries(target)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+
+	occurrence := target.GetOccurrenceTime()
+	override, overridden := s.task.GetOverride(occurrence)
+
+	switch {
+	case target.id.original:
+		return c.updateSeriesTask(s, func(master *Task) error {
+			master.Complete()
+			return nil
+		})
+	case overridden:
+		completed := override.clone()
+		completed.Complete()
+
+		err := c.updateSeriesTask(s, func(master *Task) error {
+			return master.OverrideOccurrence(occurrence, completed)
+		})
+		if err != nil {
+			return err
+		}
+		completed, _ = s.task.GetOverride(occurrence)
+
+		return c.replacePlaced(override, completed)
+	default:
+		completed := target.clone()
+		completed.Complete()
+
+		return c.storeOccurrence(s, completed)
+	}
+}
+
+// deleteOccurrence excludes the occurrence from the series and removes it from the calendar
+func (c *Calendar) deleteOccurrence(s *calendarSeries, occurrence time.Time) error {
+	if occurrence.Equal(s.task.GetTime()) {
+		return c.deleteFirstOccurrence(s)
+	}
+
+	override, overridden := s.task.GetOverride(occurrence)
+
+	err := c.updateSeriesTask(s, func(master *Task) error {
+		if err := master.ExcludeOccurrence(occurrence); err != nil {
+			return err
+		}
+		master.removeOverride(occurrence)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(s.stored, s.task.occurrenceKey(occurrence))
+
+	if overridden {
+		c.removePlaced(override)
+	}
+	c.removeOccurrence(s.task, occurrence, func(t *Task) bool { return true })
+
+	return nil
+}
+
+// deleteFirstOccurrence moves the series' original task to its next
+// occurrence, which it then occupies instead of a copy
+//
+// A completed stored copy of the next occurrence keeps it completed. The
+// original of a series without a next occurrence of its rule is only
+// excluded, and a series left without occurrences is removed.
+func (c *Calendar) deleteFirstOccurrence(s *calendarSeries) error {
+	old := s.task
+	occurrence := old.GetTime()
+
+	override, overridden := old.GetOverride(occurrence)
+
+	master := old.clone()
+	master.removeOverride(occurrence)
+
+	if !master.advanceStart() {
+		if err := master.ExcludeOccurrence(occurrence); err != nil {
+			return err
+		}
+
+		if _, ok := master.Occurrences().Next(); !ok {
+			c.removeSeries(s)
+			return nil
+		}
+	}
+
+	next := master.GetTime()
+	nextKey := master.occurrenceKey(next)
+	if stored, exists := s.stored[nextKey]; exists {
+		master.completed = stored.IsCompleted()
+	}
+
+	delete(s.stored, old.occurrenceKey(occurrence))
+	delete(s.stored, nextKey)
+	s.task = master
+
+	if overridden {
+		c.removePlaced(override)
+	}
+	c.removeOccurrence(master, occurrence, func(t *Task) bool { return true })
+	// The override of the next occurrence stays in place of the original
+	c.removeOccurrence(master, next, func(t *Task) bool { return !master.isOverride(t) })
+
+	if !s.placesOriginal() {
+		return nil
+	}
+
+	return c.placeTask(master)
+}
+
+// removeSeries removes the series and every one of its tasks from the calendar
+func (c *Calendar) removeSeries(s *calendarSeries) {
+	primaryId := s.task.id.primaryId
+	from, to := s.span()
+	c.removeTasks(from, to, func(t *Task) bool { return t.id.primaryId == primaryId })
+
+	c.mu.Lock()
+	delete(c.series, primaryId)
+	c.mu.Unlock()
+}
+
+// updateOccurrence replaces the target with an override built from edited
+func (c *Calendar) updateOccurrence(s *calendarSeries, target, edited *Task) error {
+	id := target.id
+	if id.original {
+		// The original's own occurrence needs a copy ID to be overridden
+		copyID, err := NewCopyTaskIDFactory(c.getIDSource()).CreateOccurrenceTaskID(id.primaryId, target.GetOccurrenceTime())
+		if err != nil {
+			return err
+		}
+		id = copyID
+	}
+
+	override, err := edited.occurrence(id, edited.GetTime())
+	if err != nil {
+		return err
+	}
+	override.recurrence = s.task.recurrence
+
+	conflict, err := c.checkConflicts(override, []*Task{override}, nil)
+	if err != nil {
+		return err
+	}
+
+	occurrence := target.GetOccurrenceTime()
+	err = c.updateSeriesTask(s, func(master *Task) error {
+		return master.OverrideOccurrence(occurrence, override)
+	})
+	if err != nil {
+		return err
+	}
+	override, _ = s.task.GetOverride(occurrence)
+
+	delete(s.stored, s.task.occurrenceKey(occurrence))
+
+	if target.id.original {
+		// The original stays as the series master, only out of the calendar
+		c.removePlaced(s.task)
+	} else {
+		c.removePlaced(target)
+	}
+
+	if err := c.placeTask(override); err != nil {
+		return err
+	}
+
+	return conflict
+}
+
+// splitSeries ends the series before the occurrence and adds a new series
+// built from edited starting at the edited time
+func (c *Calendar) splitSeries(ctx context.Context, s *calendarSeries, occurrence time.Time, edited *Task) error {
+	rule := s.task.GetRecurrenceRule()
+	if edited.recurrence != nil {
+		rule = edited.recurrence
+	}
+
+	var following *RecurrenceRule
+	if rule != nil {
+		following = rule.clone()
+		if rule.count > 0 {
+			following.count = rule.count - rule.countBefore(s.task.GetTime(), occurrence)
+		}
+	}
+
+	task, err := NewRecurringTask(
+		NewTaskIDFrom(c.getIDSource()),
+		edited.title, edited.description,
+		following,
+		edited.GetTime())
+	if err != nil {
+		return err
+	}
+	task.duration, task.allDay, task.priority = edited.duration, edited.allDay, edited.priority
+
+	planned := c.GetHorizon()
+	next := &calendarSeries{task: task, windowed: s.windowed, from: s.from, to: s.to, horizon: c.horizonOf(task, planned)}
+	next.mu.Lock()
+	defer next.mu.Unlock()
+
+	// The occurrences the new series replaces are not conflicts
+	primaryId := s.task.id.primaryId
+	plan, err := c.planSeries(ctx, next, func(t *Task) bool {
+		return t.id.primaryId == primaryId && !t.GetOccurrenceTime().Before(occurrence)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.truncateSeries(s, occurrence); err != nil {
+		return err
+	}
+
+	return c.registerSeries(ctx, next, plan, planned)
+}
+
+// truncateSeries ends the series right before the occurrence and removes
+// every placed occurrence from then on
+func (c *Calendar) truncateSeries(s *calendarSeries, occurrence time.Time) error {
+	// The dropped overrides are placed within the span of the series as it was
+	from, to := s.span()
+
+	err := c.updateSeriesTask(s, func(master *Task) error {
+		rule := master.GetRecurrenceRule()
+		if rule != nil {
+			truncated := rule.clone()
+			if n := rule.countBefore(master.GetTime(), occurrence); rule.count > 0 && n > 0 {
+				truncated.count = n
+			} else {
+				truncated.count = 0
+				truncated.until = occurrence.Add(-time.Second)
+			}
+
+			master.recurrence = truncated
+			master.repeating = true
+		}
+
+		master.dropExceptionsFrom(occurrence)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, stored := range s.stored {
+		if !stored.GetOccurrenceTime().Before(occurrence) {
+			delete(s.stored, key)
+		}
+	}
+
+	primaryId := s.task.id.primaryId
+	c.removeTasks(from, to, func(t *Task) bool {
+		return t.id.primaryId == primaryId && !t.GetOccurrenceTime().Before(occurrence)
+	})
+
+	return nil
+}
+
+// updateSeriesTask replaces the series' original task with an updated copy
+//
+// Tasks placed in the calendar are never mutated, so readers holding the
+// previous original keep a consistent view of it.
+func (c *Calendar) updateSeriesTask(s *calendarSeries, mutate func(master *Task) error) error {
+	master := s.task.clone()
+	if err := mutate(master); err != nil {
+		return err
+	}
+
+	old := s.task
+	s.task = master
+
+	// The original is only placed on its own days, if at all
+	return c.replacePlaced(old, master)
+}
+
+// rewriteSeries replaces the series with one built from edited, keeping its ID
+func (c *Calendar) rewriteSeries(ctx context.Context, s *calendarSeries, occurrence time.Time, edited *Task) error {
+	rule := s.task.recurrence
+	if edited.recurrence != nil {
+		rule = edited.recurrence
+	}
+
+	task := &Task{
+		id:                s.task.id,
+		title:             edited.title,
+		repeating:         s.task.repeating || rule != nil,
+		repeatingInterval: s.task.repeatingInterval,
+		recurrence:        rule,
+		description:       edited.description,
+		// Moving one occurrence moves the whole series by the same offset
+		time:     s.task.GetTime().Add(edited.GetTime().Sub(occurrence)),
+		duration: edited.duration,
+		allDay:   edited.allDay,
+		priority: edited.priority,
+	}
+
+	if err := task.Validate(); err != nil {
+		return errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	// The series stays placed at least as far as it was
+	placed := c.GetHorizon()
+	if s.horizon.After(placed) {
+		placed = s.horizon
+	}
+	horizon := c.horizonOf(task, placed)
+
+	// Stored occurrences belong to the discarded times of the series
+	plan, err := c.planSeries(ctx, &calendarSeries{task: task, windowed: s.windowed, from: s.from, to: s.to, horizon: horizon}, nil)
+	if err != nil {
+		return err
+	}
+
+	primaryId := s.task.id.primaryId
+	from, to := s.span()
+	c.removeTasks(from, to, func(t *Task) bool { return t.id.primaryId == primaryId })
+
+	s.task = task
+	s.stored = nil
+	s.horizon = horizon
+
+	return c.placePlan(plan)
+}
+
+// removeTasks removes the placed tasks within [from, to) matching the condition
+//
+// The tasks are looked up in the index by time and removed from the days
+// they span. A zero to leaves the end of the range open.
+func (c *Calendar) removeTasks(from, to time.Time, match func(*Task) bool) int {
+	tasks := c.index.find(from, to, match)
+	for _, task := range tasks {
+		c.removePlaced(task)
+	}
+
+	return len(tasks)
+}
+
+// removeOccurrence removes the placed tasks of the series' occurrence at the
+// given time, where it is not overridden, which match the condition
+func (c *Calendar) removeOccurrence(series *Task, occurrence time.Time, match func(*Task) bool) int {
+	primaryId := series.id.primaryId
+
+	return c.removeTasks(occurrence, occurrence.Add(time.Nanosecond), func(t *Task) bool {
+		return t.id.primaryId == primaryId && t.GetOccurrenceTime().Equal(occurrence) && match(t)
+	})
+}
+
+// removePlaced removes the task from the days it spans and from the index
+//
+// Days the task is not on are skipped.
+func (c *Calendar) removePlaced(task *Task) {
+	for _, date := range c.daysOf(task) {
+		m, err := c.getMonth(date.month, date.year)
+		if err != nil {
+			continue
+		}
+
+		d, err := m.getDay(date.day)
+		if err != nil {
+			continue
+		}
+
+		d.removeTasks(func(t *Task) bool { return t == task })
+	}
+
+	c.index.remove(task)
+}
+
+// getDayOf returns the day of the calendar the given time falls on in its time zone
+func (c *Calendar) getDayOf(date time.Time) (*Day, error) {
+	date = c.inZone(date)
+
+	m, err := c.getMonth(date.Month(), date.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	return m.getDay(date.Day())
+}
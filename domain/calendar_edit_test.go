@@ -0,0 +1,484 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// calendarTasks returns every task of the calendar in January 2024 by day
+func calendarTasks(t *testing.T, c *Calendar) map[int][]*Task {
+	t.Helper()
+
+	tasks := make(map[int][]*Task)
+
+	m, err := c.getMonth(time.January, 2024)
+	if err != nil {
+		return tasks
+	}
+
+	for day, d := range m.days {
+		if len(d.getTasks()) > 0 {
+			tasks[day] = d.getTasks()
+		}
+	}
+
+	return tasks
+}
+
+// seriesTask returns the current original task of the task's series
+//
+// Edits replace the original with an updated copy, so the task added to the
+// calendar no longer reflects the series after an edit.
+func seriesTask(t *testing.T, c *Calendar, task *Task) *Task {
+	t.Helper()
+
+	s, exists := c.series[task.id.primaryId]
+	if !assert.True(t, exists) {
+		return task
+	}
+
+	return s.task
+}
+
+// newEditedTask creates the edited version of a task at the given time
+func newEditedTask(t *testing.T, title string, at time.Time) *Task {
+	t.Helper()
+
+	edited, err := NewTask(NewTaskID(), title, "edited", false, 0, at)
+	assert.NoError(t, err)
+
+	return edited
+}
+
+func TestCalendar_UpdateTask(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	tests := []struct {
+		name      string
+		targetDay int
+		edited    func(t *testing.T) *Task
+		scope     EditScope
+		check     func(t *testing.T, series *Task, tasks map[int][]*Task)
+		wantErr   error
+	}{
+		{
+			name:      "This occurrence moves it to another day",
+			targetDay: 5,
+			edited: func(t *testing.T) *Task {
+				return newEditedTask(t, "moved", day(6).Add(time.Hour))
+			},
+			scope: ThisOccurrence,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.Len(t, tasks, 30)
+				assert.NotContains(t, tasks, 5)
+				assert.Len(t, tasks[6], 2)
+				assert.Equal(t, "title", tasks[6][0].GetTitle())
+				assert.Equal(t, "moved", tasks[6][1].GetTitle())
+				assert.Equal(t, day(5), tasks[6][1].GetOccurrenceTime())
+				assert.Equal(t, series.id.primaryId, tasks[6][1].id.primaryId)
+
+				override, exists := series.GetOverride(day(5))
+				assert.True(t, exists)
+				assert.Same(t, tasks[6][1], override)
+			},
+		},
+		{
+			name:      "This occurrence of the original",
+			targetDay: 1,
+			edited: func(t *testing.T) *Task {
+				return newEditedTask(t, "renamed", day(1))
+			},
+			scope: ThisOccurrence,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.Len(t, tasks, 31)
+				assert.Len(t, tasks[1], 1)
+				assert.Equal(t, "renamed", tasks[1][0].GetTitle())
+				assert.False(t, tasks[1][0].id.original)
+			},
+		},
+		{
+			name:      "This and following splits the series",
+			targetDay: 10,
+			edited: func(t *testing.T) *Task {
+				return newEditedTask(t, "later", day(10).Add(2*time.Hour))
+			},
+			scope: ThisAndFollowing,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.Len(t, tasks, 31)
+				for n := 1; n < 10; n++ {
+					assert.Equal(t, "title", tasks[n][0].GetTitle())
+					assert.Equal(t, series.id.primaryId, tasks[n][0].id.primaryId)
+				}
+
+				later := tasks[10][0]
+				assert.Equal(t, "later", later.GetTitle())
+				assert.True(t, later.id.original)
+				assert.NotEqual(t, series.id.primaryId, later.id.primaryId)
+				for n := 11; n <= 31; n++ {
+					assert.Equal(t, later.id.primaryId, tasks[n][0].id.primaryId)
+					assert.Equal(t, day(n).Add(2*time.Hour), tasks[n][0].GetTime())
+				}
+
+				assert.Equal(t, day(10).Add(-time.Second), series.GetRecurrenceRule().GetUntil())
+			},
+		},
+		{
+			name:      "All occurrences moves the series",
+			targetDay: 3,
+			edited: func(t *testing.T) *Task {
+				return newEditedTask(t, "all", day(3).Add(time.Hour))
+			},
+			scope: AllOccurrences,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.Len(t, tasks, 31)
+				for n, dayTasks := range tasks {
+					assert.Len(t, dayTasks, 1)
+					assert.Equal(t, "all", dayTasks[0].GetTitle())
+					assert.Equal(t, series.id.primaryId, dayTasks[0].id.primaryId)
+					assert.Equal(t, day(n).Add(time.Hour), dayTasks[0].GetTime())
+				}
+				assert.True(t, tasks[1][0].id.original)
+			},
+		},
+		{
+			name:      "Unknown scope",
+			targetDay: 3,
+			edited: func(t *testing.T) *Task {
+				return newEditedTask(t, "all", day(3))
+			},
+			scope:   EditScope(42),
+			wantErr: domain_errors.ErrInvalidEditScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalendar()
+			series := newTestTask(t, start, true, 24*time.Hour)
+			assert.NoError(t, c.AddTask(context.Background(), series))
+
+			target := calendarTasks(t, c)[tt.targetDay][0]
+			err := c.UpdateTask(context.Background(), target, tt.edited(t), tt.scope)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr == nil {
+				tt.check(t, seriesTask(t, c, series), calendarTasks(t, c))
+			}
+		})
+	}
+}
+
+func TestCalendar_UpdateTask_count(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=10")
+	assert.NoError(t, err)
+
+	series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+	assert.NoError(t, err)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(context.Background(), series))
+
+	target := calendarTasks(t, c)[4][0]
+	edited := newEditedTask(t, "later", target.GetTime())
+	assert.NoError(t, c.UpdateTask(context.Background(), target, edited, ThisAndFollowing))
+
+	tasks := calendarTasks(t, c)
+	assert.Len(t, tasks, 10)
+	assert.Equal(t, 3, seriesTask(t, c, series).GetRecurrenceRule().GetCount())
+	assert.Equal(t, 7, tasks[4][0].GetRecurrenceRule().GetCount())
+}
+
+func TestCalendar_DeleteTask(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	tests := []struct {
+		name      string
+		targetDay int
+		scope     EditScope
+		wantDays  int
+		check     func(t *testing.T, series *Task, tasks map[int][]*Task)
+	}{
+		{
+			name:      "This occurrence",
+			targetDay: 5,
+			scope:     ThisOccurrence,
+			wantDays:  30,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.NotContains(t, tasks, 5)
+				assert.Equal(t, []time.Time{day(5)}, series.GetExceptionDates())
+			},
+		},
+		{
+			name:      "The first occurrence",
+			targetDay: 1,
+			scope:     ThisOccurrence,
+			wantDays:  30,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.NotContains(t, tasks, 1)
+				assert.Equal(t, day(2), series.GetTime())
+				assert.Equal(t, []*Task{series}, tasks[2])
+			},
+		},
+		{
+			name:      "This and following",
+			targetDay: 5,
+			scope:     ThisAndFollowing,
+			wantDays:  4,
+			check: func(t *testing.T, series *Task, tasks map[int][]*Task) {
+				assert.Equal(t, day(5).Add(-time.Second), series.GetRecurrenceRule().GetUntil())
+			},
+		},
+		{
+			name:      "This and following from the first occurrence",
+			targetDay: 1,
+			scope:     ThisAndFollowing,
+			wantDays:  0,
+		},
+		{
+			name:      "All occurrences",
+			targetDay: 12,
+			scope:     AllOccurrences,
+			wantDays:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalendar()
+			series := newTestTask(t, start, true, 24*time.Hour)
+			assert.NoError(t, c.AddTask(context.Background(), series))
+
+			target := calendarTasks(t, c)[tt.targetDay][0]
+			assert.NoError(t, c.DeleteTask(context.Background(), target, tt.scope))
+
+			tasks := calendarTasks(t, c)
+			assert.Len(t, tasks, tt.wantDays)
+			if tt.check != nil {
+				tt.check(t, seriesTask(t, c, series), tasks)
+			}
+		})
+	}
+}
+
+func TestCalendar_DeleteTask_movedOverride(t *testing.T) {
+	start := time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC)
+	moved := time.Date(2024, time.January, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		targetDay int
+		scope     EditScope
+		// onlyOverride tells the override is the only task removed
+		onlyOverride bool
+	}{
+		{name: "This occurrence", targetDay: 3, scope: ThisOccurrence, onlyOverride: true},
+		{name: "This and following", targetDay: 11, scope: ThisAndFollowing},
+		{name: "All occurrences", targetDay: 20, scope: AllOccurrences},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalendar()
+			series := newTestTask(t, start, true, 24*time.Hour)
+			assert.NoError(t, c.AddTask(context.Background(), series))
+
+			// The occurrence of the 12th is moved before the start of the series
+			target := calendarTasks(t, c)[12][0]
+			assert.NoError(t, c.UpdateTask(context.Background(), target, newEditedTask(t, "moved", moved), ThisOccurrence))
+			assert.Len(t, calendarTasks(t, c)[3], 1)
+			placed := c.index.len()
+
+			target = calendarTasks(t, c)[tt.targetDay][0]
+			assert.NoError(t, c.DeleteTask(context.Background(), target, tt.scope))
+
+			assert.NotContains(t, calendarTasks(t, c), 3)
+			if tt.onlyOverride {
+				assert.Equal(t, placed-1, c.index.len())
+			}
+
+			// Every task left in the index is placed on its days, and the other way around
+			indexed := c.index.find(time.Time{}, time.Time{}, func(*Task) bool { return true })
+			onDays := make(map[*Task]bool)
+			for _, m := range c.getMonths() {
+				for _, d := range m.getDays() {
+					for _, task := range d.getTasks() {
+						onDays[task] = true
+					}
+				}
+			}
+			assert.Len(t, onDays, len(indexed))
+			for _, task := range indexed {
+				assert.True(t, onDays[task])
+			}
+		})
+	}
+}
+
+func TestCalendar_DeleteTask_unknownSeries(t *testing.T) {
+	c := NewCalendar()
+	task := newTestTask(t, time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC), false, 0)
+
+	assert.ErrorIs(t, c.DeleteTask(context.Background(), task, AllOccurrences), domain_errors.ErrTaskNotFound)
+	assert.ErrorIs(t, c.DeleteTask(context.Background(), nil, AllOccurrences), domain_errors.ErrTaskCannotBeNil)
+}
+
+func TestCalendar_DeleteTask_firstOccurrence(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Counted series", func(t *testing.T) {
+		rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+		assert.NoError(t, err)
+
+		series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+		assert.NoError(t, err)
+
+		c := NewCalendar()
+		assert.NoError(t, c.AddTask(ctx, series))
+
+		// The second occurrence was already cancelled
+		assert.NoError(t, c.DeleteTask(ctx, calendarTasks(t, c)[2][0], ThisOccurrence))
+		assert.NoError(t, c.DeleteTask(ctx, series, ThisOccurrence))
+
+		master := seriesTask(t, c, series)
+		assert.Equal(t, start.AddDate(0, 0, 2), master.GetTime())
+		assert.Equal(t, 1, master.GetRecurrenceRule().GetCount())
+		assert.Equal(t, map[int][]*Task{3: {master}}, calendarTasks(t, c))
+		assert.Equal(t, 1, c.index.len())
+	})
+
+	t.Run("Completed next occurrence", func(t *testing.T) {
+		series := newTestTask(t, start, true, 24*time.Hour)
+
+		c := NewCalendar()
+		assert.NoError(t, c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 3)))
+
+		completed := calendarTasks(t, c)[2][0].clone()
+		completed.Complete()
+		assert.NoError(t, c.RestoreOccurrences(completed))
+
+		assert.NoError(t, c.DeleteTask(ctx, series, ThisOccurrence))
+
+		master := seriesTask(t, c, series)
+		assert.True(t, master.IsCompleted())
+		assert.Equal(t, []*Task{master}, calendarTasks(t, c)[2])
+	})
+
+	t.Run("Single task", func(t *testing.T) {
+		task := newTestTask(t, start, false, 0)
+
+		c := NewCalendar()
+		assert.NoError(t, c.AddTask(ctx, task))
+		assert.NoError(t, c.DeleteTask(ctx, task, ThisOccurrence))
+
+		assert.Empty(t, calendarTasks(t, c))
+		assert.Empty(t, c.GetSeries())
+		assert.Zero(t, c.index.len())
+	})
+}
+
+func TestCalendar_DeleteTask_cancelled(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	series := newTestTask(t, start, true, 24*time.Hour)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(context.Background(), series))
+	before := calendarTasks(t, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, c.DeleteTask(ctx, series, AllOccurrences), context.Canceled)
+	assert.Equal(t, before, calendarTasks(t, c))
+}
+
+func TestCalendar_CompleteOccurrence(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	// setup adds a daily series for ten days, its 3rd occurrence moved to 12:00
+	setup := func(t *testing.T) (*Calendar, *Task) {
+		c := NewCalendar()
+		series := newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 10)))
+
+		edited := newEditedTask(t, "moved", start.AddDate(0, 0, 2).Add(3*time.Hour))
+		assert.NoError(t, c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, 3), edited, ThisOccurrence))
+
+		return c, series
+	}
+
+	tests := []struct {
+		name string
+		day  int
+		// stored reports whether the completed task is stored apart from its series
+		stored bool
+	}{
+		{name: "Copy", day: 5, stored: true},
+		{name: "Override", day: 3},
+		{name: "Original", day: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, series := setup(t)
+			target := findTask(t, c, series.id.primaryId, tt.day)
+
+			assert.NoError(t, c.CompleteOccurrence(ctx, target))
+
+			// The placed task is replaced, never completed in place
+			assert.False(t, target.IsCompleted())
+
+			completed := findTask(t, c, series.id.primaryId, tt.day)
+			assert.True(t, completed.IsCompleted())
+			assert.Equal(t, target.GetID(), completed.GetID())
+			assert.Equal(t, target.GetTime(), completed.GetTime())
+			assert.Equal(t, 10, c.index.len())
+
+			if tt.stored {
+				assert.Equal(t, []*Task{completed}, c.GetCompletedOccurrences())
+			} else {
+				assert.Empty(t, c.GetCompletedOccurrences())
+			}
+		})
+	}
+
+	t.Run("Excluded occurrence", func(t *testing.T) {
+		c, series := setup(t)
+		target := findTask(t, c, series.id.primaryId, 5)
+		assert.NoError(t, c.DeleteTask(ctx, target, ThisOccurrence))
+
+		assert.ErrorIs(t, c.CompleteOccurrence(ctx, target), domain_errors.ErrOccurrenceNotFound)
+	})
+
+	t.Run("Concurrently with readers", func(t *testing.T) {
+		c, series := setup(t)
+
+		var wg sync.WaitGroup
+		for day := 4; day <= 10; day++ {
+			wg.Add(2)
+
+			go func(day int) {
+				defer wg.Done()
+				assert.NoError(t, c.CompleteOccurrence(ctx, findTask(t, c, series.id.primaryId, day)))
+			}(day)
+
+			go func() {
+				defer wg.Done()
+				c.GetCompletedOccurrences()
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, c.GetCompletedOccurrences(), 7)
+	})
+}
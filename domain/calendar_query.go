@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// TaskQuery selects the tasks of a calendar within [from, to), one page at a time
+//
+// A task is within the range if it starts within it, or if it starts before
+// and ends after from. Tasks are ordered by time, then priority, then ID.
+type TaskQuery struct {
+	from, to time.Time
+	after    *TaskCursor
+	limit    int
+}
+
+// NewTaskQuery creates a new query of the tasks within [from, to)
+//
+// The page size and the position to resume from can be set through the With* methods.
+// If to is not after from, NewTaskQuery returns domain_errors.ErrInvalidRange.
+func NewTaskQuery(from, to time.Time) (*TaskQuery, error) {
+	q := &TaskQuery{from: from, to: to}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// WithLimit sets the maximum number of tasks of a page, 0 for no limit
+func (q *TaskQuery) WithLimit(limit int) *TaskQuery {
+	q.limit = limit
+	return q
+}
+
+// WithCursor resumes the query after the position of the cursor, nil for the first page
+func (q *TaskQuery) WithCursor(cursor *TaskCursor) *TaskQuery {
+	q.after = cursor
+	return q
+}
+
+// Validate validates the query
+func (q *TaskQuery) Validate() (errc error) {
+	if !q.to.After(q.from) {
+		errc = errors.Join(domain_errors.ErrInvalidRange, errc)
+	}
+
+	if q.limit < 0 {
+		errc = errors.Join(domain_errors.ErrInvalidPageSize, errc)
+	}
+
+	return errc
+}
+
+// includes reports whether the task is within the range of the query
+func (q *TaskQuery) includes(task *Task) bool {
+	start, end := task.GetTime(), task.GetEndTime()
+	if !start.Before(q.to) {
+		return false
+	}
+
+	return !start.Before(q.from) || end.After(q.from)
+}
+
+// TaskCursor is the position of a task within the order of a query
+//
+// Its String form is opaque and URL safe; ParseTaskCursor reads it back.
+type TaskCursor struct {
+	time     time.Time
+	priority int
+	id       TaskID
+}
+
+// newTaskCursor returns the position of the task
+func newTaskCursor(task *Task) *TaskCursor {
+	return &TaskCursor{time: task.GetTime(), priority: task.GetPriority(), id: task.GetID()}
+}
+
+// String returns the opaque form of the cursor
+func (tc *TaskCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s %d %s", tc.time.UTC().Format(time.RFC3339Nano), tc.priority, tc.id.String())))
+}
+
+// ParseTaskCursor parses a cursor from its String form
+//
+// If s is not a cursor, ParseTaskCursor returns domain_errors.ErrInvalidCursor.
+func ParseTaskCursor(s string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidCursor, err)
+	}
+
+	parts := strings.Split(string(data), " ")
+	if len(parts) != 3 {
+		return nil, errors.Join(domain_errors.ErrInvalidCursor, fmt.Errorf("malformed cursor %q", s))
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidCursor, err)
+	}
+
+	priority, err := strconv.Atoi(parts[1])
+	if err != nil || priority < 0 || priority > 9 {
+		return nil, errors.Join(domain_errors.ErrInvalidCursor, fmt.Errorf("malformed cursor priority %q", parts[1]))
+	}
+
+	taskId, err := ParseTaskID(parts[2])
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidCursor, err)
+	}
+
+	return &TaskCursor{time: t, priority: priority, id: *taskId}, nil
+}
+
+// MarshalText encodes the cursor as its String form
+func (tc *TaskCursor) MarshalText() ([]byte, error) {
+	return []byte(tc.String()), nil
+}
+
+// UnmarshalText decodes a cursor from its String form
+//
+// If the text is not a cursor, UnmarshalText returns domain_errors.ErrInvalidCursor.
+func (tc *TaskCursor) UnmarshalText(text []byte) error {
+	parsed, err := ParseTaskCursor(string(text))
+	if err != nil {
+		return err
+	}
+
+	*tc = *parsed
+
+	return nil
+}
+
+// compare returns -1, 0 or +1 as the task is before, at or after the cursor
+func (tc *TaskCursor) compare(task *Task) int {
+	return compareOrder(task.time, task.priority, task.id, tc.time, tc.priority, &tc.id)
+}
+
+// TaskPage is a page of the tasks selected by a TaskQuery
+type TaskPage struct {
+	tasks []*Task
+	next  *TaskCursor
+}
+
+// GetTasks returns the tasks of the page, in the order of the query
+func (p *TaskPage) GetTasks() []*Task {
+	return p.tasks
+}
+
+// GetNextCursor returns the cursor the next page resumes from, nil on the last page
+func (p *TaskPage) GetNextCursor() *TaskCursor {
+	return p.next
+}
+
+// QueryTasks returns a page of the tasks placed in the calendar selected by the query
+//
+// A task spanning several days is returned once. The range may span any
+// number of days, months and years; the tasks are found through an interval
+// index of the calendar rather than by walking its days.
+// If the query is nil or invalid, QueryTasks returns domain_errors.ErrInvalidRange
+// or domain_errors.ErrInvalidPageSize.
+func (c *Calendar) QueryTasks(q *TaskQuery) (*TaskPage, error) {
+	if q == nil {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	// One task more than the page tells whether there is a next page
+	var tasks []*Task
+	c.index.overlapping(q.from, q.to, q.after, func(task *Task) bool {
+		tasks = append(tasks, task)
+		return q.limit == 0 || len(tasks) <= q.limit
+	})
+
+	page := &TaskPage{tasks: tasks}
+	if q.limit > 0 && len(tasks) > q.limit {
+		page.tasks = tasks[:q.limit:q.limit]
+		page.next = newTaskCursor(page.tasks[q.limit-1])
+	}
+
+	return page, nil
+}
+
+// GetTasksBetween returns a snapshot of the tasks placed in the calendar within [from, to), sorted by time
+//
+// See QueryTasks for which tasks are within the range.
+// If to is not after from, GetTasksBetween returns domain_errors.ErrInvalidRange.
+func (c *Calendar) GetTasksBetween(from, to time.Time) ([]*Task, error) {
+	q, err := NewTaskQuery(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := c.QueryTasks(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.GetTasks(), nil
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar_GetTasksBetween(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, time.December, 30, 9, 0, 0, 0, time.UTC)
+
+	daily := newTestTask(t, start, true, 24*time.Hour)
+
+	// Starts before the range and ends within it
+	overlapping := newTestTask(t, time.Date(2023, time.December, 29, 22, 0, 0, 0, time.UTC), false, 0)
+	assert.NoError(t, overlapping.SetDuration(48*time.Hour))
+
+	// Ends when the range starts
+	ended := newTestTask(t, time.Date(2023, time.December, 30, 23, 0, 0, 0, time.UTC), false, 0)
+	assert.NoError(t, ended.SetDuration(time.Hour))
+
+	// Starts when the range ends
+	atEnd := newTestTask(t, time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC), false, 0)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTaskBetween(ctx, daily, start, start.AddDate(0, 0, 7)))
+	for _, task := range []*Task{overlapping, ended, atEnd} {
+		assert.NoError(t, c.AddTask(ctx, task))
+	}
+
+	from := time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC)
+
+	got, err := c.GetTasksBetween(from, to)
+	assert.NoError(t, err)
+
+	var times []time.Time
+	for _, task := range got {
+		times = append(times, task.GetTime())
+	}
+	assert.Equal(t, []time.Time{
+		overlapping.GetTime(),
+		time.Date(2023, time.December, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 2, 9, 0, 0, 0, time.UTC),
+	}, times)
+
+	_, err = c.GetTasksBetween(to, from)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+
+	none, err := NewCalendar().GetTasksBetween(from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestCalendar_GetTasksBetween_timeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	// 01:00 in Tokyo is the day before in UTC, so the task is placed there
+	task := newTestTask(t, time.Date(2024, time.March, 1, 1, 0, 0, 0, tokyo), false, 0)
+
+	for _, c := range []*Calendar{NewCalendar(), newZonedCalendar(t, time.UTC)} {
+		assert.NoError(t, c.AddTask(context.Background(), task))
+
+		got, err := c.GetTasksBetween(
+			time.Date(2024, time.February, 29, 15, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 29, 17, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, []*Task{task}, got)
+	}
+}
+
+func TestCalendar_QueryTasks(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 29, 9, 0, 0, 0, time.UTC)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTaskBetween(ctx, newTestTask(t, start, true, 24*time.Hour), start, start.AddDate(0, 0, 5)))
+
+	// Tasks at the same time are ordered by priority, then by ID
+	assert.NoError(t, c.AddTask(ctx, newTestTask(t, start.AddDate(0, 0, 2), false, 0)))
+	for _, priority := range []int{5, 1, 5} {
+		task := newTestTask(t, start.AddDate(0, 0, 2), false, 0)
+		assert.NoError(t, task.SetPriority(priority))
+		assert.NoError(t, c.AddTask(ctx, task))
+	}
+
+	from, to := start, start.AddDate(0, 0, 5)
+
+	all, err := c.GetTasksBetween(from, to)
+	assert.NoError(t, err)
+	assert.Len(t, all, 9)
+	assert.Equal(t, []int{1, 5, 5}, []int{all[2].GetPriority(), all[3].GetPriority(), all[4].GetPriority()})
+
+	for _, limit := range []int{1, 2, 3, 4, 9, 10} {
+		var (
+			got    []*Task
+			cursor *TaskCursor
+			pages  int
+		)
+
+		for {
+			q, err := NewTaskQuery(from, to)
+			assert.NoError(t, err)
+
+			page, err := c.QueryTasks(q.WithLimit(limit).WithCursor(cursor))
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, len(page.GetTasks()), limit)
+
+			got = append(got, page.GetTasks()...)
+			pages++
+
+			if page.GetNextCursor() == nil {
+				break
+			}
+
+			// Cursors travel in their String form
+			cursor, err = ParseTaskCursor(page.GetNextCursor().String())
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, all, got, "limit %d", limit)
+		assert.Equal(t, (len(all)+limit-1)/limit, pages, "limit %d", limit)
+	}
+
+	for i := 1; i < len(all); i++ {
+		assert.Negative(t, CompareTasks(all[i-1], all[i]))
+	}
+}
+
+func TestCalendar_QueryTasks_invalid(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := NewTaskQuery(from, from)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+
+	q, err := NewTaskQuery(from, from.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+
+	_, err = NewCalendar().QueryTasks(q.WithLimit(-1))
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidPageSize)
+
+	_, err = NewCalendar().QueryTasks(nil)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidRange)
+}
+
+func TestParseTaskCursor(t *testing.T) {
+	task := newTestTask(t, time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC), false, 0)
+	cursor := newTaskCursor(task)
+
+	got, err := ParseTaskCursor(cursor.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.compare(task))
+
+	data, err := json.Marshal(cursor)
+	assert.NoError(t, err)
+
+	var decoded TaskCursor
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 0, decoded.compare(task))
+
+	for _, invalid := range []string{"", "!!", "bm90IGEgY3Vyc29y", "MjAyNC0wMS0wMVQwOTowMDowMFogbm90LWFuLWlk"} {
+		_, err := ParseTaskCursor(invalid)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidCursor, invalid)
+	}
+}
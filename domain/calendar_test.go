@@ -0,0 +1,787 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTask(t *testing.T, taskTime time.Time, repeating bool, interval time.Duration) *Task {
+	t.Helper()
+
+	task, err := NewTask(
+		NewTaskID(),
+		"title", "description",
+		repeating, interval,
+		taskTime)
+	assert.NoError(t, err)
+
+	return task
+}
+
+func TestCalendar_addMonth(t *testing.T) {
+	tests := []struct {
+		name    string
+		month   time.Month
+		year    int
+		wantErr error
+	}{
+		{
+			name:  "Valid month",
+			month: time.May,
+			year:  2024,
+		},
+		{
+			name:    "Invalid month",
+			month:   13,
+			year:    2024,
+			wantErr: domain_errors.ErrAddMonth,
+		},
+		{
+			name:    "Invalid year",
+			month:   time.May,
+			year:    0,
+			wantErr: domain_errors.ErrAddMonth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalendar()
+			m, err := c.addMonth(tt.month, tt.year)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, m)
+				return
+			}
+
+			assert.NoError(t, err)
+			existing, err := c.addMonth(tt.month, tt.year)
+			assert.NoError(t, err)
+			assert.Same(t, m, existing)
+		})
+	}
+}
+
+func TestCalendar_getMonth(t *testing.T) {
+	c := NewCalendar()
+	m, err := c.addMonth(time.May, 2024)
+	assert.NoError(t, err)
+
+	got, err := c.getMonth(time.May, 2024)
+	assert.NoError(t, err)
+	assert.Same(t, m, got)
+
+	_, err = c.getMonth(time.June, 2024)
+	assert.ErrorIs(t, err, domain_errors.ErrMonthNotFound)
+}
+
+func TestCalendar_AddTask(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		task     func(t *testing.T) *Task
+		cancel   bool
+		wantDays int
+		wantErr  error
+	}{
+		{
+			name: "Non-repeating task",
+			task: func(t *testing.T) *Task {
+				return newTestTask(t, start, false, 0)
+			},
+			wantDays: 1,
+		},
+		{
+			name: "Daily repeating task",
+			task: func(t *testing.T) *Task {
+				return newTestTask(t, start, true, 24*time.Hour)
+			},
+			wantDays: 31,
+		},
+		{
+			name: "Nil task",
+			task: func(t *testing.T) *Task {
+				return nil
+			},
+			wantErr: domain_errors.ErrTaskCannotBeNil,
+		},
+		{
+			name: "Invalid task",
+			task: func(t *testing.T) *Task {
+				id := uuid.New()
+				return &Task{id: &TaskID{primaryId: id, secondaryId: id, original: true}}
+			},
+			wantErr: domain_errors.ErrAddTask,
+		},
+		{
+			name: "Cancelled context",
+			task: func(t *testing.T) *Task {
+				return newTestTask(t, start, true, 24*time.Hour)
+			},
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.cancel {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+
+			c := NewCalendar()
+			task := tt.task(t)
+			err := c.AddTask(ctx, task)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			m, err := c.getMonth(time.January, 2024)
+			assert.NoError(t, err)
+			assert.Len(t, m.days, tt.wantDays)
+
+			first, err := m.getDay(1)
+			assert.NoError(t, err)
+			assert.Len(t, first.getTasks(), 1)
+			assert.Same(t, task, first.getTasks()[0])
+
+			for day, d := range m.days {
+				if day == 1 {
+					continue
+				}
+
+				copied := d.getTasks()[0]
+				assert.Equal(t, task.id.primaryId, copied.id.primaryId)
+				assert.False(t, copied.id.original)
+			}
+		})
+	}
+}
+
+func TestCalendar_AddTask_weekday(t *testing.T) {
+	// Sunday 23:30 in UTC is already Monday in Madrid
+	sunday := time.Date(2024, time.January, 7, 23, 30, 0, 0, time.UTC)
+	task := newTestTask(t, sunday, false, 0)
+	assert.Equal(t, time.Sunday, task.GetDayOfWeek())
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		name        string
+		c           *Calendar
+		wantDay     int
+		wantWeekday time.Weekday
+	}{
+		{name: "UTC calendar", c: NewCalendar(), wantDay: 7, wantWeekday: time.Sunday},
+		{name: "Madrid calendar", c: newZonedCalendar(t, madrid), wantDay: 8, wantWeekday: time.Monday},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.c.AddTask(context.Background(), task))
+
+			m, err := tt.c.getMonth(time.January, 2024)
+			assert.NoError(t, err)
+
+			d, err := m.getDay(tt.wantDay)
+			assert.NoError(t, err)
+
+			day, weekday := d.getDay()
+			assert.Equal(t, tt.wantDay, day)
+			assert.Equal(t, tt.wantWeekday, weekday)
+		})
+	}
+}
+
+func TestCalendar_AddTaskBetween(t *testing.T) {
+	start := time.Date(2024, time.December, 30, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		from       time.Time
+		to         time.Time
+		wantMonths map[int]map[time.Month]int
+		wantErr    error
+	}{
+		{
+			name: "Window across the end of the year",
+			from: start,
+			to:   time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantMonths: map[int]map[time.Month]int{
+				2024: {time.December: 2},
+				2025: {time.January: 31, time.February: 28},
+			},
+		},
+		{
+			name: "Window excluding the original",
+			from: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC),
+			wantMonths: map[int]map[time.Month]int{
+				2025: {time.January: 2},
+			},
+		},
+		{
+			name:    "Invalid window",
+			from:    start,
+			to:      start,
+			wantErr: domain_errors.ErrInvalidRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCalendar()
+			task := newTestTask(t, start, true, 24*time.Hour)
+
+			err := c.AddTaskBetween(context.Background(), task, tt.from, tt.to)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, c.years, len(tt.wantMonths))
+			for year, months := range tt.wantMonths {
+				assert.Len(t, c.years[year], len(months))
+				for month, days := range months {
+					m, err := c.getMonth(month, year)
+					assert.NoError(t, err)
+					assert.Len(t, m.days, days)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendar_concurrentUse(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	c := NewCalendar()
+
+	// Series edited while others are being added and read
+	edited := make([]*Task, 8)
+	updateTargets := make([]*Task, len(edited))
+	deleteTargets := make([]*Task, len(edited))
+	for i := range edited {
+		edited[i] = newTestTask(t, start.Add(time.Duration(i)*time.Minute), true, 24*time.Hour)
+		assert.NoError(t, c.AddTask(ctx, edited[i]))
+
+		updateTargets[i] = findTask(t, c, edited[i].id.primaryId, 5)
+		deleteTargets[i] = findTask(t, c, edited[i].id.primaryId, 20)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(4)
+
+		go func(i int) {
+			defer wg.Done()
+			task := newTestTask(t, start.Add(time.Duration(i)*time.Hour), true, 24*time.Hour)
+			assert.NoError(t, c.AddTask(ctx, task))
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			for day := 1; day <= 31; day++ {
+				tasks, err := c.GetTasksOn(start.AddDate(0, 0, day-1))
+				if err != nil {
+					continue
+				}
+
+				for _, task := range tasks {
+					task.GetTitle()
+					task.GetTime()
+				}
+			}
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			edit := newEditedTask(t, "edited", start.AddDate(0, 0, 4).Add(time.Duration(i)*time.Minute))
+			assert.NoError(t, c.UpdateTask(ctx, updateTargets[i], edit, ThisOccurrence))
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, c.DeleteTask(ctx, deleteTargets[i], ThisAndFollowing))
+		}(i)
+	}
+
+	// Changing the zone back and forth waits for the edits
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, c.WithTimeZone(time.FixedZone("UTC+1", 3600)))
+		assert.NoError(t, c.WithTimeZone(nil))
+	}()
+	wg.Wait()
+
+	for _, task := range edited {
+		master := seriesTask(t, c, task)
+		_, overridden := master.GetOverride(start.AddDate(0, 0, 4).Add(master.GetTime().Sub(start)))
+		assert.True(t, overridden)
+		assert.Equal(t, start.AddDate(0, 0, 19).Add(master.GetTime().Sub(start)).Add(-time.Second), master.GetRecurrenceRule().GetUntil())
+	}
+
+	for day := 1; day <= 31; day++ {
+		tasks, err := c.GetTasksOn(start.AddDate(0, 0, day-1))
+		assert.NoError(t, err)
+
+		want := 8
+		if day < 20 {
+			want += len(edited)
+		}
+		assert.Len(t, tasks, want, "day %d", day)
+	}
+}
+
+// findTask returns the task of the series placed on the given day of January 2024
+func findTask(t *testing.T, c *Calendar, primaryId uuid.UUID, day int) *Task {
+	t.Helper()
+
+	tasks, err := c.GetTasksOn(time.Date(2024, time.January, day, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	for _, task := range tasks {
+		if task.id.primaryId == primaryId {
+			return task
+		}
+	}
+
+	t.Fatalf("no task of series %s on day %d", primaryId, day)
+	return nil
+}
+
+func TestCalendar_horizon(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(DefaultHorizon)
+
+	// countIn returns how many tasks of the series are placed within [from, to)
+	countIn := func(t *testing.T, c *Calendar, task *Task, from, to time.Time) int {
+		t.Helper()
+
+		tasks, err := c.GetTasksBetween(from, to)
+		assert.NoError(t, err)
+
+		n := 0
+		for _, placed := range tasks {
+			if placed.id.primaryId == task.id.primaryId {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	t.Run("Unbounded series", func(t *testing.T) {
+		c := NewCalendar()
+		daily := newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, c.AddTask(ctx, daily))
+
+		// The series goes on after the month it starts in
+		assert.Equal(t, 29, countIn(t, c, daily, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, 365, countIn(t, c, daily, start, end.AddDate(1, 0, 0)))
+		assert.Equal(t, 365, c.index.len())
+	})
+
+	t.Run("Extended horizon", func(t *testing.T) {
+		c := NewCalendar()
+		daily := newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, c.AddTask(ctx, daily))
+
+		rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+		assert.NoError(t, err)
+		bounded, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+		assert.NoError(t, err)
+		assert.NoError(t, c.AddTask(ctx, bounded))
+
+		extended := end.AddDate(0, 0, 10)
+		assert.NoError(t, c.ExtendHorizon(ctx, extended))
+		assert.Equal(t, extended, c.GetHorizon())
+		assert.Equal(t, 10, countIn(t, c, daily, end, end.AddDate(1, 0, 0)))
+		assert.Equal(t, 3, countIn(t, c, bounded, start, end.AddDate(1, 0, 0)))
+
+		// An earlier horizon places nothing more
+		assert.NoError(t, c.ExtendHorizon(ctx, end))
+		assert.Equal(t, extended, c.GetHorizon())
+		assert.Equal(t, 375+3, c.index.len())
+
+		// Series added afterwards are placed up to the horizon
+		later := newTestTask(t, start.Add(time.Hour), true, 24*time.Hour)
+		assert.NoError(t, c.AddTask(ctx, later))
+		assert.Equal(t, 375, countIn(t, c, later, start, end.AddDate(1, 0, 0)))
+	})
+
+	t.Run("Conflicts", func(t *testing.T) {
+		c := NewCalendar()
+		daily := newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, daily.SetDuration(time.Hour))
+		assert.NoError(t, c.AddTask(ctx, daily))
+
+		meeting := newTimedTask(t, end.Add(24*time.Hour), time.Hour)
+		assert.NoError(t, c.AddTask(ctx, meeting))
+		c.WithConflictPolicy(RejectConflicts)
+
+		assert.ErrorIs(t, c.ExtendHorizon(ctx, end.AddDate(0, 0, 10)), domain_errors.ErrConflict)
+		assert.Zero(t, countIn(t, c, daily, end, end.AddDate(1, 0, 0)))
+	})
+
+	t.Run("Short periods", func(t *testing.T) {
+		for _, value := range []string{"FREQ=MINUTELY", "FREQ=SECONDLY"} {
+			t.Run(value, func(t *testing.T) {
+				rule, err := ParseRecurrenceRule(value)
+				assert.NoError(t, err)
+
+				c := NewCalendar()
+				series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+				assert.NoError(t, err)
+
+				// Only the first occurrences are placed at once
+				assert.NoError(t, c.AddTask(ctx, series))
+				assert.Equal(t, MaxPlacedOccurrences, c.index.len())
+
+				// Every extension places the following ones
+				assert.NoError(t, c.ExtendHorizon(ctx, end))
+				assert.Equal(t, 2*MaxPlacedOccurrences, c.index.len())
+				assert.NoError(t, c.ExtendHorizon(ctx, end))
+				assert.Equal(t, 3*MaxPlacedOccurrences, c.index.len())
+			})
+		}
+	})
+}
+
+func TestRehydrateCalendar(t *testing.T) {
+	id := uuid.New()
+
+	c, err := RehydrateCalendar(id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, c.GetID())
+	assert.Empty(t, c.GetSeries())
+
+	_, err = RehydrateCalendar(uuid.Nil)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidCalendarID)
+
+	assert.NotEqual(t, NewCalendar().GetID(), NewCalendar().GetID())
+}
+
+func TestCalendar_GetSeries(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	c := NewCalendar()
+
+	later := newTestTask(t, start.AddDate(0, 0, 3), false, 0)
+	earlier := newTestTask(t, start, true, 24*time.Hour)
+	from, to := start, start.AddDate(0, 0, 7)
+
+	assert.NoError(t, c.AddTask(ctx, later))
+	assert.NoError(t, c.AddTaskBetween(ctx, earlier, from, to))
+
+	assert.Equal(t, []*Task{earlier, later}, c.GetSeries())
+
+	gotFrom, gotTo, ok := c.GetSeriesWindow(earlier.id.primaryId)
+	assert.True(t, ok)
+	assert.Equal(t, from, gotFrom)
+	assert.Equal(t, to, gotTo)
+
+	_, _, ok = c.GetSeriesWindow(later.id.primaryId)
+	assert.False(t, ok)
+
+	_, _, ok = c.GetSeriesWindow(uuid.New())
+	assert.False(t, ok)
+}
+
+func TestCalendar_WithIDSource(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=5")
+	assert.NoError(t, err)
+
+	task, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+	assert.NoError(t, err)
+
+	// Two nodes expanding the same series
+	first := NewCalendar().WithIDSource(NewDeterministicIDSource(nil))
+	second := NewCalendar().WithIDSource(NewDeterministicIDSource(nil))
+	random := NewCalendar()
+
+	for _, c := range []*Calendar{first, second, random} {
+		assert.NoError(t, c.AddTask(ctx, task))
+	}
+
+	for day := 2; day <= 5; day++ {
+		want := findTask(t, first, task.id.primaryId, day)
+		assert.False(t, want.id.original)
+		assert.Equal(t, want.id, findTask(t, second, task.id.primaryId, day).id, "day %d", day)
+		assert.NotEqual(t, want.id, findTask(t, random, task.id.primaryId, day).id, "day %d", day)
+	}
+
+	// The series split off an edit takes its ID from the source too
+	want := uuid.New()
+	c := NewCalendar().WithIDSource(NewDeterministicIDSource(&sequenceIDSource{ids: []uuid.UUID{want}}))
+	assert.NoError(t, c.AddTask(ctx, task))
+
+	target := findTask(t, c, task.id.primaryId, 3)
+	edited, err := NewTask(NewTaskID(), "edited", "description", false, 0, target.GetTime())
+	assert.NoError(t, err)
+	assert.NoError(t, c.UpdateTask(ctx, target, edited, ThisAndFollowing))
+
+	assert.Equal(t, want, findTask(t, c, want, 3).id.primaryId)
+}
+
+func TestCalendar_WithTimeZone(t *testing.T) {
+	ctx := context.Background()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// 23:00 in New York is 05:00 of the next day in Berlin
+	task := newTestTask(t, time.Date(2024, time.January, 31, 23, 0, 0, 0, newYork), false, 0)
+
+	tests := []struct {
+		name      string
+		zone      *time.Location
+		wantDay   time.Time
+		wantMonth time.Month
+	}{
+		{
+			name:      "Task's own zone",
+			wantDay:   time.Date(2024, time.January, 31, 0, 0, 0, 0, newYork),
+			wantMonth: time.January,
+		},
+		{
+			name:      "Viewer's zone",
+			zone:      berlin,
+			wantDay:   time.Date(2024, time.February, 1, 0, 0, 0, 0, berlin),
+			wantMonth: time.February,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newZonedCalendar(t, tt.zone)
+			assert.Equal(t, tt.zone, c.GetTimeZone())
+			assert.NoError(t, c.AddTask(ctx, task))
+
+			tasks, err := c.GetTasksOn(tt.wantDay)
+			assert.NoError(t, err)
+			assert.Equal(t, []*Task{task}, tasks)
+
+			m, err := c.GetMonth(tt.wantMonth, 2024)
+			assert.NoError(t, err)
+			assert.Len(t, m.GetTasks(), 1)
+
+			// Edits find the original on the day it was placed on
+			assert.NoError(t, c.DeleteTask(ctx, task, ThisOccurrence))
+			tasks, err = c.GetTasksOn(tt.wantDay)
+			assert.NoError(t, err)
+			assert.Empty(t, tasks)
+			assert.Empty(t, c.GetSeries())
+		})
+	}
+}
+
+// newZonedCalendar creates a calendar placing tasks on the days of the zone
+func newZonedCalendar(t *testing.T, zone *time.Location) *Calendar {
+	t.Helper()
+
+	c := NewCalendar()
+	assert.NoError(t, c.WithTimeZone(zone))
+
+	return c
+}
+
+func TestCalendar_WithTimeZone_failure(t *testing.T) {
+	// The first day of year 1 in UTC is in year 0 an hour west of it
+	task := newTestTask(t, time.Date(1, time.January, 1, 0, 30, 0, 0, time.UTC), false, 0)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(context.Background(), task))
+
+	err := c.WithTimeZone(time.FixedZone("UTC-1", -3600))
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidYear)
+
+	var occurrenceErr *domain_errors.OccurrenceError
+	assert.ErrorAs(t, err, &occurrenceErr)
+	assert.Equal(t, task.GetTime(), occurrenceErr.Occurrence)
+
+	// The calendar keeps its zone and days
+	assert.Nil(t, c.GetTimeZone())
+	tasks, err := c.GetTasksOn(task.GetTime())
+	assert.NoError(t, err)
+	assert.Equal(t, []*Task{task}, tasks)
+}
+
+func TestCalendar_WithTimeZone_placedTasks(t *testing.T) {
+	ctx := context.Background()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// Daily at 23:00 in New York, 05:00 of the next day in Berlin
+	start := time.Date(2024, time.January, 1, 23, 0, 0, 0, newYork)
+	series := newTestTask(t, start, true, 24*time.Hour)
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 5)))
+
+	assert.NoError(t, c.WithTimeZone(berlin))
+
+	// The original moved to the 2nd and nothing is left on the 1st
+	_, err = c.GetTasksOn(time.Date(2024, time.January, 1, 12, 0, 0, 0, berlin))
+	assert.ErrorIs(t, err, domain_errors.ErrDayNotFound)
+
+	for day := 2; day <= 6; day++ {
+		tasks, err := c.GetTasksOn(time.Date(2024, time.January, day, 12, 0, 0, 0, berlin))
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1, "day %d", day)
+	}
+
+	// Edits find the tasks on their new days
+	target, err := c.GetTasksOn(time.Date(2024, time.January, 4, 12, 0, 0, 0, berlin))
+	assert.NoError(t, err)
+	assert.NoError(t, c.DeleteTask(ctx, target[0], ThisOccurrence))
+
+	tasks, err := c.GetTasksOn(time.Date(2024, time.January, 4, 12, 0, 0, 0, berlin))
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// Restoring the default moves them back
+	assert.NoError(t, c.WithTimeZone(nil))
+
+	tasks, err = c.GetTasksOn(start)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Task{seriesTask(t, c, series)}, tasks)
+	assert.Equal(t, 4, c.index.len())
+}
+
+func TestCalendar_AddTask_multiDay(t *testing.T) {
+	ctx := context.Background()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	overnight := newTestTask(t, time.Date(2024, time.January, 30, 22, 0, 0, 0, time.UTC), false, 0)
+	assert.NoError(t, overnight.SetDuration(30*time.Hour))
+
+	// Ends exactly at midnight, so the next day is not spanned
+	evening := newTestTask(t, time.Date(2024, time.January, 10, 20, 0, 0, 0, time.UTC), false, 0)
+	assert.NoError(t, evening.SetDuration(4*time.Hour))
+
+	allDay := newTestTask(t, time.Date(2024, time.January, 5, 0, 0, 0, 0, newYork), false, 0)
+	assert.NoError(t, allDay.SetAllDay(2))
+
+	tests := []struct {
+		name     string
+		calendar *Calendar
+		task     *Task
+		wantDays []time.Time
+		notDays  []time.Time
+	}{
+		{
+			name:     "Across months",
+			calendar: NewCalendar(),
+			task:     overnight,
+			wantDays: []time.Time{day(time.January, 30), day(time.January, 31), day(time.February, 1)},
+			notDays:  []time.Time{day(time.February, 2)},
+		},
+		{
+			name:     "Ending at midnight",
+			calendar: NewCalendar(),
+			task:     evening,
+			wantDays: []time.Time{day(time.January, 10)},
+			notDays:  []time.Time{day(time.January, 11)},
+		},
+		{
+			name:     "All-day keeps its dates in the viewer's zone",
+			calendar: newZonedCalendar(t, berlin),
+			task:     allDay,
+			wantDays: []time.Time{
+				time.Date(2024, time.January, 5, 12, 0, 0, 0, berlin),
+				time.Date(2024, time.January, 6, 12, 0, 0, 0, berlin),
+			},
+			notDays: []time.Time{time.Date(2024, time.January, 7, 12, 0, 0, 0, berlin)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.calendar.AddTask(ctx, tt.task))
+
+			for _, date := range tt.wantDays {
+				tasks, err := tt.calendar.GetTasksOn(date)
+				assert.NoError(t, err)
+				// The same task is on every day, not a copy of it
+				assert.Equal(t, []*Task{tt.task}, tasks, date)
+			}
+
+			for _, date := range tt.notDays {
+				_, err := tt.calendar.GetTasksOn(date)
+				assert.Error(t, err, date)
+			}
+
+			start := tt.wantDays[0]
+			m, err := tt.calendar.GetMonth(start.Month(), start.Year())
+			assert.NoError(t, err)
+			assert.Equal(t, []*Task{tt.task}, m.GetTasks())
+		})
+	}
+}
+
+func TestCalendar_multiDaySeries(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	rule, err := ParseRecurrenceRule("FREQ=WEEKLY;COUNT=3")
+	assert.NoError(t, err)
+
+	series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+	assert.NoError(t, err)
+	assert.NoError(t, series.SetAllDay(2))
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(ctx, series))
+
+	second := findTask(t, c, series.id.primaryId, 9)
+	assert.Equal(t, second, findTask(t, c, series.id.primaryId, 8))
+	assert.NoError(t, c.CompleteOccurrence(ctx, second))
+
+	// A completed occurrence spanning two days is stored once
+	assert.Len(t, c.GetCompletedOccurrences(), 1)
+
+	// Editing the series replaces its original on both of its days
+	assert.NoError(t, c.DeleteTask(ctx, findTask(t, c, series.id.primaryId, 15), ThisOccurrence))
+	master := seriesTask(t, c, series)
+	assert.Equal(t, master, findTask(t, c, series.id.primaryId, 1))
+	assert.Equal(t, master, findTask(t, c, series.id.primaryId, 2))
+
+	// The excluded occurrence is removed from both of its days
+	tasks, err := c.GetTasksOn(start.AddDate(0, 0, 15))
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
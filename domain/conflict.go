@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// ConflictPolicy decides what happens when a task is added or moved on top of others
+//
+// Two tasks conflict when each starts before the other ends; a task without
+// an end only occupies its start. Occurrences of the same series don't
+// conflict with each other, and all-day tasks, like holidays, don't conflict
+// with anything.
+type ConflictPolicy int
+
+const (
+	// AllowConflicts places tasks without checking them
+	AllowConflicts ConflictPolicy = iota
+	// WarnConflicts places tasks and reports their conflicts as a *ConflictError
+	WarnConflicts
+	// RejectConflicts leaves the calendar unchanged and returns a *ConflictError
+	RejectConflicts
+)
+
+// ConflictError is returned when a task added or moved overlaps other tasks
+//
+// It matches domain_errors.ErrConflict with errors.Is.
+type ConflictError struct {
+	// Task is the ID of the task added or moved, the original of a series
+	Task TaskID
+	// Conflicts are the IDs of the tasks it overlaps, ordered by time
+	Conflicts []TaskID
+}
+
+// Error returns the error message including the clashing task IDs
+func (e *ConflictError) Error() string {
+	ids := make([]string, len(e.Conflicts))
+	for i, id := range e.Conflicts {
+		ids[i] = id.String()
+	}
+
+	return fmt.Sprintf("task %s conflicts with %s", e.Task.String(), strings.Join(ids, ", "))
+}
+
+// Unwrap returns domain_errors.ErrConflict
+func (e *ConflictError) Unwrap() error {
+	return domain_errors.ErrConflict
+}
+
+// lockBooking locks the calendar's booking, under a conflict policy, and returns its unlock
+//
+// Checking tasks for conflicts and placing them is then atomic with respect
+// to other tasks being added or moved.
+func (c *Calendar) lockBooking() (unlock func()) {
+	if c.GetConflictPolicy() == AllowConflicts {
+		return func() {}
+	}
+
+	c.booking.Lock()
+
+	return c.booking.Unlock
+}
+
+// checkConflicts checks the tasks about to be placed for task against the conflict policy
+//
+// With WarnConflicts the conflicts are returned as a warning; with
+// RejectConflicts they are returned as err. Tasks of task's series, and those
+// ignore matches, are not conflicts.
+func (c *Calendar) checkConflicts(task *Task, tasks []*Task, ignore func(*Task) bool) (warning, err error) {
+	policy := c.GetConflictPolicy()
+	if policy == AllowConflicts {
+		return nil, nil
+	}
+
+	conflicts := c.conflictsOf(task.id.primaryId, tasks, ignore)
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+
+	conflict := &ConflictError{Task: task.GetID(), Conflicts: conflicts}
+	if policy == RejectConflicts {
+		return nil, conflict
+	}
+
+	return conflict, nil
+}
+
+// conflictsOf returns the IDs of the placed tasks the tasks overlap, ordered by time
+//
+// Tasks of the series with the given primaryId, all-day tasks and the tasks
+// ignore matches are skipped.
+func (c *Calendar) conflictsOf(primaryId uuid.UUID, tasks []*Task, ignore func(*Task) bool) []TaskID {
+	var clashing []*Task
+	seen := make(map[*Task]bool)
+
+	for _, task := range tasks {
+		if task.IsAllDay() {
+			continue
+		}
+
+		start, end := task.GetTime(), task.GetEndTime()
+		if !end.After(start) {
+			end = start.Add(time.Nanosecond)
+		}
+
+		c.index.overlapping(start, end, nil, func(placed *Task) bool {
+			if seen[placed] || placed.id.primaryId == primaryId || placed.IsAllDay() || (ignore != nil && ignore(placed)) {
+				return true
+			}
+			seen[placed] = true
+
+			clashing = append(clashing, placed)
+
+			return true
+		})
+	}
+
+	slices.SortFunc(clashing, CompareTasks)
+
+	ids := make([]TaskID, len(clashing))
+	for i, task := range clashing {
+		ids[i] = task.GetID()
+	}
+
+	return ids
+}
@@ -0,0 +1,287 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTimedTask creates a task at the given time lasting the given duration, without an end if it is 0
+func newTimedTask(t *testing.T, at time.Time, duration time.Duration) *Task {
+	t.Helper()
+
+	task := newTestTask(t, at, false, 0)
+	if duration > 0 {
+		assert.NoError(t, task.SetDuration(duration))
+	}
+
+	return task
+}
+
+func TestConflictError(t *testing.T) {
+	task, first, second := NewTaskID(), NewTaskID(), NewTaskID()
+	err := error(&ConflictError{Task: *task, Conflicts: []TaskID{*first, *second}})
+
+	assert.ErrorIs(t, err, domain_errors.ErrConflict)
+	assert.Equal(t, "task "+task.String()+" conflicts with "+first.String()+", "+second.String(), err.Error())
+}
+
+func TestCalendar_AddTask_conflicts(t *testing.T) {
+	ctx := context.Background()
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, time.January, 10, hour, min, 0, 0, time.UTC)
+	}
+
+	type placed struct {
+		at       time.Time
+		duration time.Duration
+	}
+
+	tests := []struct {
+		name      string
+		placed    []placed
+		at        time.Time
+		duration  time.Duration
+		conflicts []int
+	}{
+		{
+			name:      "Overlapping the end",
+			placed:    []placed{{at(9, 0), time.Hour}},
+			at:        at(9, 30),
+			duration:  time.Hour,
+			conflicts: []int{0},
+		},
+		{
+			name:      "Within",
+			placed:    []placed{{at(9, 0), 3 * time.Hour}},
+			at:        at(10, 0),
+			duration:  30 * time.Minute,
+			conflicts: []int{0},
+		},
+		{
+			name:      "Several tasks, ordered by time",
+			placed:    []placed{{at(11, 0), time.Hour}, {at(9, 0), time.Hour}, {at(13, 0), time.Hour}},
+			at:        at(9, 30),
+			duration:  2 * time.Hour,
+			conflicts: []int{1, 0},
+		},
+		{
+			name:     "Back to back",
+			placed:   []placed{{at(9, 0), time.Hour}, {at(11, 0), time.Hour}},
+			at:       at(10, 0),
+			duration: time.Hour,
+		},
+		{
+			name:   "Without an end, at the end of a task",
+			placed: []placed{{at(9, 0), time.Hour}},
+			at:     at(10, 0),
+		},
+		{
+			name:      "Without an end, within a task",
+			placed:    []placed{{at(9, 0), time.Hour}},
+			at:        at(9, 30),
+			conflicts: []int{0},
+		},
+		{
+			name:      "At the same time, without an end",
+			placed:    []placed{{at(9, 0), 0}},
+			at:        at(9, 0),
+			conflicts: []int{0},
+		},
+		{
+			name:     "Ending at a task without an end",
+			placed:   []placed{{at(10, 0), 0}},
+			at:       at(9, 0),
+			duration: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, policy := range []ConflictPolicy{AllowConflicts, WarnConflicts, RejectConflicts} {
+			t.Run(tt.name, func(t *testing.T) {
+				c := NewCalendar()
+
+				ids := make([]TaskID, len(tt.placed))
+				for i, p := range tt.placed {
+					task := newTimedTask(t, p.at, p.duration)
+					assert.NoError(t, c.AddTask(ctx, task))
+					ids[i] = task.GetID()
+				}
+				c.WithConflictPolicy(policy)
+
+				task := newTimedTask(t, tt.at, tt.duration)
+				err := c.AddTask(ctx, task)
+				_, added := c.series[task.id.primaryId]
+
+				if len(tt.conflicts) == 0 || policy == AllowConflicts {
+					assert.NoError(t, err)
+					assert.True(t, added)
+					return
+				}
+
+				var conflict *ConflictError
+				assert.True(t, errors.As(err, &conflict))
+				assert.ErrorIs(t, err, domain_errors.ErrConflict)
+				assert.Equal(t, task.GetID(), conflict.Task)
+
+				want := make([]TaskID, len(tt.conflicts))
+				for i, n := range tt.conflicts {
+					want[i] = ids[n]
+				}
+				assert.Equal(t, want, conflict.Conflicts)
+
+				if policy == WarnConflicts {
+					assert.True(t, added)
+					assert.Contains(t, calendarTasks(t, c)[10], task)
+					return
+				}
+
+				assert.ErrorIs(t, err, domain_errors.ErrAddTask)
+				assert.False(t, added)
+				assert.NotContains(t, calendarTasks(t, c)[10], task)
+				assert.Equal(t, len(tt.placed), c.index.len())
+			})
+		}
+	}
+}
+
+func TestCalendar_AddTask_conflictsIgnored(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Occurrences of the same series", func(t *testing.T) {
+		c := NewCalendar().WithConflictPolicy(RejectConflicts)
+
+		// Every occurrence overlaps the next one
+		series := newTestTask(t, start, true, time.Hour)
+		assert.NoError(t, series.SetDuration(2*time.Hour))
+		assert.NoError(t, c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 1)))
+	})
+
+	t.Run("All-day tasks", func(t *testing.T) {
+		c := NewCalendar().WithConflictPolicy(RejectConflicts)
+
+		holiday := newTestTask(t, start.Truncate(24*time.Hour), false, 0)
+		assert.NoError(t, holiday.SetAllDay(1))
+		assert.NoError(t, c.AddTask(ctx, holiday))
+
+		assert.NoError(t, c.AddTask(ctx, newTimedTask(t, start, time.Hour)))
+
+		other := newTestTask(t, start.Truncate(24*time.Hour), false, 0)
+		assert.NoError(t, other.SetAllDay(1))
+		assert.NoError(t, c.AddTask(ctx, other))
+	})
+
+	t.Run("Occurrences of a new series", func(t *testing.T) {
+		c := NewCalendar()
+		assert.NoError(t, c.AddTask(ctx, newTimedTask(t, start.AddDate(0, 0, 2), time.Hour)))
+		c.WithConflictPolicy(RejectConflicts)
+
+		// The third occurrence of the daily series overlaps the task
+		series := newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, series.SetDuration(time.Hour))
+
+		err := c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 7))
+		assert.ErrorIs(t, err, domain_errors.ErrConflict)
+		assert.Equal(t, 1, c.index.len())
+	})
+}
+
+func TestCalendar_UpdateTask_conflicts(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	// setup adds a daily series from 9:00 to 10:00 and a meeting on the 5th from 14:00 to 15:00
+	setup := func(t *testing.T, policy ConflictPolicy) (c *Calendar, series, meeting *Task) {
+		c = NewCalendar()
+
+		series = newTestTask(t, start, true, 24*time.Hour)
+		assert.NoError(t, series.SetDuration(time.Hour))
+		assert.NoError(t, c.AddTaskBetween(ctx, series, day(1), day(11)))
+
+		meeting = newTimedTask(t, day(5).Add(5*time.Hour), time.Hour)
+		assert.NoError(t, c.AddTask(ctx, meeting))
+
+		return c.WithConflictPolicy(policy), series, meeting
+	}
+
+	// edited creates the edited task at the time, lasting an hour
+	edited := func(t *testing.T, at time.Time) *Task {
+		edited := newEditedTask(t, "edited", at)
+		assert.NoError(t, edited.SetDuration(time.Hour))
+
+		return edited
+	}
+
+	tests := []struct {
+		name   string
+		day    int
+		scope  EditScope
+		moveTo time.Time
+	}{
+		{
+			name:   "This occurrence",
+			day:    5,
+			scope:  ThisOccurrence,
+			moveTo: day(5).Add(5*time.Hour + 30*time.Minute),
+		},
+		{
+			name:   "This and following",
+			day:    3,
+			scope:  ThisAndFollowing,
+			moveTo: day(3).Add(5 * time.Hour),
+		},
+		{
+			name:   "All occurrences",
+			day:    1,
+			scope:  AllOccurrences,
+			moveTo: day(1).Add(5 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Run("Reject", func(t *testing.T) {
+				c, series, meeting := setup(t, RejectConflicts)
+				before := calendarTasks(t, c)
+				original := seriesTask(t, c, series)
+
+				err := c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, tt.day), edited(t, tt.moveTo), tt.scope)
+
+				var conflict *ConflictError
+				assert.True(t, errors.As(err, &conflict))
+				assert.Equal(t, []TaskID{meeting.GetID()}, conflict.Conflicts)
+
+				assert.Equal(t, before, calendarTasks(t, c))
+				assert.Same(t, original, seriesTask(t, c, series))
+				assert.Len(t, c.series, 2)
+			})
+
+			t.Run("Warn", func(t *testing.T) {
+				c, series, meeting := setup(t, WarnConflicts)
+
+				err := c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, tt.day), edited(t, tt.moveTo), tt.scope)
+
+				var conflict *ConflictError
+				assert.True(t, errors.As(err, &conflict))
+				assert.Equal(t, []TaskID{meeting.GetID()}, conflict.Conflicts)
+
+				moved, err := c.GetTasksBetween(day(5).Add(5*time.Hour), day(5).Add(6*time.Hour))
+				assert.NoError(t, err)
+				assert.Len(t, moved, 2)
+			})
+
+			t.Run("Moving clear of other tasks", func(t *testing.T) {
+				c, series, _ := setup(t, RejectConflicts)
+
+				err := c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, tt.day), edited(t, tt.moveTo.Add(-3*time.Hour)), tt.scope)
+				assert.NoError(t, err)
+			})
+		})
+	}
+}
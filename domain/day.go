@@ -2,15 +2,22 @@ package domain
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
 )
 
 // Day represents a day within a month with associated tasks
+//
+// A Day is safe for concurrent use.
 type Day struct {
+	// mu guards tasks
+	mu    sync.RWMutex
 	day   int
 	tasks []*Task
+	// date is the day's date once it belongs to a month, zero before
+	date time.Time
 }
 
 // NewDay creates a new day
@@ -25,15 +32,16 @@ func NewDay(day int) (*Day, error) {
 
 }
 
-// binarySearch searches for the key in the day's tasks slice.
-func binarySearch(day *Day, key time.Time) int {
+// binarySearch returns the position to insert the task at in the day's tasks slice
+//
+// It is the position of the first task ordered after the task by
+// CompareTasks, so a task inserted twice follows its first insertion.
+func binarySearch(day *Day, task *Task) int {
 	low, high := 0, len(day.tasks)-1
 
 	for low <= high {
 		mid := (low + high) / 2
-		if day.tasks[mid].GetTime().Equal(key) {
-			return mid // Found exact match
-		} else if day.tasks[mid].GetTime().Before(key) {
+		if CompareTasks(day.tasks[mid], task) <= 0 {
 			low = mid + 1
 		} else {
 			high = mid - 1
@@ -43,9 +51,52 @@ func binarySearch(day *Day, key time.Time) int {
 	return low // Position for insertion
 }
 
+// searchTime returns the position of the first task at or after the time in the day's tasks slice
+func searchTime(day *Day, key time.Time) int {
+	low, high := 0, len(day.tasks)-1
+
+	for low <= high {
+		mid := (low + high) / 2
+		if day.tasks[mid].GetTime().Before(key) {
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+
+	return low
+}
+
+// indexOf returns the position of the task in the day's tasks slice, -1 if it is not in the day
+//
+// The tasks ordered the same as the task are searched for the task itself,
+// as a task and its updated version are.
+func indexOf(day *Day, task *Task) int {
+	low, high := 0, len(day.tasks)-1
+
+	// Find the first task ordered with or after the task
+	for low <= high {
+		mid := (low + high) / 2
+		if CompareTasks(day.tasks[mid], task) < 0 {
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+
+	for i := low; i < len(day.tasks) && CompareTasks(day.tasks[i], task) == 0; i++ {
+		if day.tasks[i] == task {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // addTask adds a task to the day.
 //
-// Inserts the task into the tasks slice in sorted order by time.
+// Inserts the task into the tasks slice in the order of CompareTasks: by
+// time, then priority, then ID.
 //
 // If the task is nil, AddTask returns domain_errors.ErrTaskCannotBeNil.
 func (d *Day) addTask(task *Task) error {
@@ -54,8 +105,11 @@ func (d *Day) addTask(task *Task) error {
 		return domain_errors.ErrTaskCannotBeNil
 	}
 
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	// Find the correct position for the task
-	position := binarySearch(d, task.GetTime())
+	position := binarySearch(d, task)
 
 	// Insert the task at the correct position
 	d.tasks = append(d.tasks[:position], append([]*Task{task}, d.tasks[position:]...)...)
@@ -63,17 +117,28 @@ func (d *Day) addTask(task *Task) error {
 	return nil
 }
 
-// getTasks returns the tasks for the day
+// getTasks returns a snapshot of the tasks for the day
 func (d *Day) getTasks() []*Task {
-	return d.tasks
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	tasks := make([]*Task, len(d.tasks))
+	copy(tasks, d.tasks)
+
+	return tasks
 }
 
-// sortTasks sorts the tasks for the day by time
+// sortTasks sorts the tasks for the day in the order of CompareTasks
+//
+// The sort is stable, so duplicates of a task keep their order.
 func (d *Day) sortTasks() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	// Insertion sort
 	for i := 1; i < len(d.tasks); i++ {
 		j := i
-		for j > 0 && d.tasks[j-1].GetTime().After(d.tasks[j].GetTime()) {
+		for j > 0 && CompareTasks(d.tasks[j-1], d.tasks[j]) > 0 {
 			d.tasks[j], d.tasks[j-1] = d.tasks[j-1], d.tasks[j]
 			j--
 		}
@@ -82,10 +147,16 @@ func (d *Day) sortTasks() {
 
 // getDay returns the day and the day of the week
 //
-// If there are no tasks, the day of the week is not returned.
-// If there are tasks, the day of the week is returned.
-// The day of the week is the day of the week of the first task.
+// The day of the week is the one of the day's date within its month.
+// A day which doesn't belong to a month takes the day of the week of its
+// first task, and returns time.Sunday if it has no tasks.
 func (d *Day) getDay() (int, time.Weekday) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.date.IsZero() {
+		return d.day, d.date.Weekday()
+	}
 
 	var weekDay time.Weekday
 
@@ -110,8 +181,15 @@ func (d *Day) Validate() (errc error) {
 }
 
 // deleteTask deletes a task from the day
+//
+// position is where the task was found. Another task at the same time may
+// be there instead, e.g. after an insertion, so the task itself must be.
+// If it is not, deleteTask returns domain_errors.ErrTaskNotFound.
 func (d *Day) deleteTask(position int, task *Task) error {
-	if position >= len(d.tasks) || !d.tasks[position].time.Equal(task.time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if position < 0 || position >= len(d.tasks) || d.tasks[position] != task {
 		return domain_errors.ErrTaskNotFound
 	}
 
@@ -121,15 +199,65 @@ func (d *Day) deleteTask(position int, task *Task) error {
 	return nil
 }
 
-// updateTask updates a task in the day
+// updateTask replaces the task at the position with the task, keeping the slice sorted
+//
+// If there is no task at the position, updateTask returns domain_errors.ErrTaskNotFound.
 func (d *Day) updateTask(position int, task *Task) error {
-	// if position >= len(d.tasks) || !d.tasks[position].GetTime().Equal(task.time) {
-	if position >= len(d.tasks) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if position < 0 || position >= len(d.tasks) {
 		return domain_errors.ErrTaskNotFound
 	}
 
-	// Update the task
-	d.tasks[position] = task
+	// Update the task, which may move among the tasks at its time
+	d.tasks = append(d.tasks[:position], d.tasks[position+1:]...)
+	position = binarySearch(d, task)
+	d.tasks = append(d.tasks[:position], append([]*Task{task}, d.tasks[position:]...)...)
+
+	return nil
+}
+
+// removeTasks removes every task matching the condition from the day
+//
+// The remaining tasks keep their order. removeTasks returns how many tasks were removed.
+func (d *Day) removeTasks(match func(*Task) bool) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	kept := d.tasks[:0]
+	for _, task := range d.tasks {
+		if !match(task) {
+			kept = append(kept, task)
+		}
+	}
+
+	removed := len(d.tasks) - len(kept)
+
+	// Clear the tail so removed tasks can be garbage collected
+	for i := len(kept); i < len(d.tasks); i++ {
+		d.tasks[i] = nil
+	}
+	d.tasks = kept
+
+	return removed
+}
+
+// replaceTask replaces the task with its new version, keeping the slice sorted
+//
+// If the task is not in the day, replaceTask returns domain_errors.ErrTaskNotFound.
+func (d *Day) replaceTask(old, task *Task) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := indexOf(d, old)
+	if i == -1 {
+		return domain_errors.ErrTaskNotFound
+	}
+
+	d.tasks = append(d.tasks[:i], d.tasks[i+1:]...)
+	position := binarySearch(d, task)
+	d.tasks = append(d.tasks[:position], append([]*Task{task}, d.tasks[position:]...)...)
 
 	return nil
 }
@@ -145,6 +273,9 @@ var (
 func (d *Day) findTask(findTaskFunc findTaskFunc,
 	title string,
 	time time.Time) (int, *Task, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
 	position := findTaskFunc(d, title, time)
 	if position == -1 {
 		return 0, nil, domain_errors.ErrTaskNotFound
@@ -162,25 +293,21 @@ func (d *Day) findTask(findTaskFunc findTaskFunc,
 // 	return position, d.tasks[position], nil
 // }
 
-// binarySearchTask searches for the task in the day's tasks slice.
+// binarySearchByTitleAndTime searches for the first task with the title at the time in the day's tasks slice.
 func binarySearchByTitleAndTime(day *Day, title string, time time.Time) int {
 	return binarySearchByCondition(day, time, func(t *Task) bool {
-		return t.GetTitle() == title && t.GetTime().Equal(time)
+		return t.GetTitle() == title
 	})
 }
 
-// binarySearchByCondition searches for the task in the day's tasks slice.
+// binarySearchByCondition searches for the first task at the time matching the condition in the day's tasks slice.
+//
+// Every task at the time is checked, in order, so a match is found however
+// many tasks share the time.
 func binarySearchByCondition(day *Day, time time.Time, condition func(*Task) bool) int {
-	low, high := 0, len(day.tasks)-1
-
-	for low <= high {
-		mid := (low + high) / 2
-		if condition(day.tasks[mid]) {
-			return mid // Found exact match
-		} else if day.tasks[mid].GetTime().Before(time) {
-			low = mid + 1
-		} else {
-			high = mid - 1
+	for i := searchTime(day, time); i < len(day.tasks) && day.tasks[i].GetTime().Equal(time); i++ {
+		if condition(day.tasks[i]) {
+			return i
 		}
 	}
 
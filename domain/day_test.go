@@ -1,7 +1,13 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
 	"testing"
+	"testing/quick"
 	"time"
 
 	"github.com/google/uuid"
@@ -130,14 +136,41 @@ func TestDay_getDay(t *testing.T) {
 				day: 1,
 				tasks: []*Task{
 					{
-						time:      time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
-						dayOfWeek: time.Saturday,
+						time: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
 					},
 				},
 			},
 			expectedDay:     1,
 			expectedWeekday: time.Saturday,
 		},
+		{
+			name: "Sunday with tasks",
+			day: &Day{
+				day: 2,
+				tasks: []*Task{
+					{
+						time: time.Date(2022, 1, 2, 9, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			expectedDay:     2,
+			expectedWeekday: time.Sunday,
+		},
+		{
+			name: "Day of a month",
+			day: &Day{
+				day: 3,
+				// The task, in Auckland, is on Tuesday there
+				tasks: []*Task{
+					{
+						time: time.Date(2022, 1, 4, 8, 0, 0, 0, time.FixedZone("NZDT", 13*60*60)),
+					},
+				},
+				date: time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			expectedDay:     3,
+			expectedWeekday: time.Monday,
+		},
 		// Add more test cases as needed
 	}
 
@@ -291,6 +324,28 @@ func TestFindTask(t *testing.T) {
 	}
 }
 
+func TestFindTask_sameTime(t *testing.T) {
+	at := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// Every title is found, however many tasks share its time
+	day := &Day{}
+	for _, title := range []string{"Task1", "Task2", "Task3", "Task4", "Task5"} {
+		assert.NoError(t, day.addTask(&Task{id: NewTaskID(), title: title, time: at}))
+	}
+	assert.NoError(t, day.addTask(&Task{id: NewTaskID(), title: "Task1", time: at.Add(time.Hour)}))
+
+	for _, title := range []string{"Task1", "Task2", "Task3", "Task4", "Task5"} {
+		pos, task, err := day.findTask(binarySearchByTitleAndTime, title, at)
+		assert.NoError(t, err)
+		assert.Equal(t, title, task.GetTitle())
+		assert.Equal(t, at, task.GetTime())
+		assert.Same(t, day.tasks[pos], task)
+	}
+
+	_, _, err := day.findTask(binarySearchByTitleAndTime, "Task2", at.Add(time.Hour))
+	assert.ErrorIs(t, err, domain_errors.ErrTaskNotFound)
+}
+
 func TestDayUpdateTask(t *testing.T) {
 	originalTask := uuid.New()
 	originalTime := time.Now()
@@ -386,3 +441,231 @@ func TestDayDeleteTask(t *testing.T) {
 		})
 	}
 }
+
+func TestDayRemoveTasks(t *testing.T) {
+	seriesId := uuid.New()
+	otherId := uuid.New()
+	baseTime := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	task1 := &Task{id: &TaskID{primaryId: seriesId}, time: baseTime}
+	task2 := &Task{id: &TaskID{primaryId: otherId}, time: baseTime.Add(time.Hour)}
+	task3 := &Task{id: &TaskID{primaryId: seriesId}, time: baseTime.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		day         *Day
+		match       func(*Task) bool
+		wantRemoved int
+		wantTasks   []*Task
+	}{
+		{
+			name:        "Remove series keeping order",
+			day:         &Day{tasks: []*Task{task1, task2, task3}},
+			match:       func(t *Task) bool { return t.id.primaryId == seriesId },
+			wantRemoved: 2,
+			wantTasks:   []*Task{task2},
+		},
+		{
+			name:        "Remove nothing",
+			day:         &Day{tasks: []*Task{task1, task2, task3}},
+			match:       func(t *Task) bool { return false },
+			wantRemoved: 0,
+			wantTasks:   []*Task{task1, task2, task3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := tt.day.removeTasks(tt.match)
+			assert.Equal(t, tt.wantRemoved, removed)
+			assert.Equal(t, tt.wantTasks, tt.day.tasks)
+		})
+	}
+}
+
+func TestDayReplaceTask(t *testing.T) {
+	baseTime := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	task1 := &Task{id: &TaskID{primaryId: uuid.New()}, time: baseTime}
+	task2 := &Task{id: &TaskID{primaryId: uuid.New()}, time: baseTime.Add(time.Hour)}
+	moved := &Task{id: task1.id, time: baseTime.Add(2 * time.Hour)}
+
+	d := &Day{tasks: []*Task{task1, task2}}
+
+	assert.NoError(t, d.replaceTask(task1, moved))
+	assert.Equal(t, []*Task{task2, moved}, d.tasks)
+
+	assert.ErrorIs(t, d.replaceTask(task1, moved), domain_errors.ErrTaskNotFound)
+}
+
+func TestDay_concurrentUse(t *testing.T) {
+	d, err := NewDay(1)
+	assert.NoError(t, err)
+
+	baseTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 24; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, d.addTask(&Task{id: &TaskID{primaryId: uuid.New()}, time: baseTime.Add(time.Duration(i) * time.Hour)}))
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			d.getDay()
+			for _, task := range d.getTasks() {
+				task.GetTime()
+			}
+		}()
+	}
+	wg.Wait()
+
+	tasks := d.getTasks()
+	assert.Len(t, tasks, 24)
+	for i := 1; i < len(tasks); i++ {
+		assert.True(t, tasks[i-1].GetTime().Before(tasks[i].GetTime()))
+	}
+}
+
+// sortedTasks reports whether the tasks are in the order of CompareTasks
+func sortedTasks(tasks []*Task) bool {
+	return slices.IsSortedFunc(tasks, CompareTasks)
+}
+
+func TestDay_properties(t *testing.T) {
+	// newDay adds the tasks to a new day in the order of the permutation
+	newDay := func(tasks []*Task, perm []int) *Day {
+		d := &Day{day: 10}
+		for _, i := range perm {
+			if err := d.addTask(tasks[i]); err != nil {
+				return nil
+			}
+		}
+
+		return d
+	}
+
+	// The tasks of a day are in the same order, whatever order they were added in
+	insert := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		tasks := sameTimeTasks(rnd, 20)
+
+		want := slices.Clone(tasks)
+		slices.SortFunc(want, CompareTasks)
+
+		return slices.Equal(want, newDay(tasks, rnd.Perm(len(tasks))).tasks) &&
+			slices.Equal(want, newDay(tasks, rnd.Perm(len(tasks))).tasks)
+	}
+
+	// Every task is found by its title among those at the same time
+	find := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		tasks := sameTimeTasks(rnd, 20)
+		for i, task := range tasks {
+			task.title = fmt.Sprint("Task", i)
+		}
+
+		d := newDay(tasks, rnd.Perm(len(tasks)))
+		for _, task := range tasks {
+			pos, found, err := d.findTask(binarySearchByTitleAndTime, task.title, task.time)
+			if err != nil || found != task || d.tasks[pos] != task || indexOf(d, task) != pos {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// Deleting a task removes it alone, never another task at the same time
+	remove := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		tasks := sameTimeTasks(rnd, 20)
+
+		d := newDay(tasks, rnd.Perm(len(tasks)))
+		for n, i := range rnd.Perm(len(tasks)) {
+			pos := indexOf(d, tasks[i])
+
+			// The position of a neighbour is not the task's
+			if pos > 0 && !errors.Is(d.deleteTask(pos-1, tasks[i]), domain_errors.ErrTaskNotFound) {
+				return false
+			}
+
+			if d.deleteTask(pos, tasks[i]) != nil || indexOf(d, tasks[i]) != -1 {
+				return false
+			}
+
+			if len(d.tasks) != len(tasks)-n-1 || !sortedTasks(d.tasks) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// Updating or replacing a task keeps the day sorted
+	update := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		tasks := sameTimeTasks(rnd, 20)
+		moved := sameTimeTasks(rnd, len(tasks))
+
+		d := newDay(tasks, rnd.Perm(len(tasks)))
+		for i, task := range tasks {
+			updated := &Task{id: task.id, time: moved[i].time, priority: moved[i].priority}
+
+			if i%2 == 0 {
+				if d.updateTask(indexOf(d, task), updated) != nil {
+					return false
+				}
+			} else if d.replaceTask(task, updated) != nil {
+				return false
+			}
+
+			if len(d.tasks) != len(tasks) || indexOf(d, updated) == -1 || !sortedTasks(d.tasks) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// A task added twice is kept twice, next to itself, and deleted once at a time
+	duplicates := func(seed int64) bool {
+		rnd := rand.New(rand.NewSource(seed))
+		tasks := sameTimeTasks(rnd, 10)
+		twice := tasks[rnd.Intn(len(tasks))]
+
+		d := newDay(append(tasks, twice), rnd.Perm(len(tasks)+1))
+		pos := indexOf(d, twice)
+		if pos == -1 || pos+1 >= len(d.tasks) || d.tasks[pos+1] != twice || !sortedTasks(d.tasks) {
+			return false
+		}
+
+		if d.deleteTask(pos, twice) != nil || indexOf(d, twice) != pos {
+			return false
+		}
+
+		return d.deleteTask(pos, twice) == nil && indexOf(d, twice) == -1
+	}
+
+	tests := []struct {
+		name     string
+		property func(seed int64) bool
+	}{
+		{name: "Insert", property: insert},
+		{name: "Find", property: find},
+		{name: "Delete", property: remove},
+		{name: "Update", property: update},
+		{name: "Duplicates", property: duplicates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := quick.Check(tt.property, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
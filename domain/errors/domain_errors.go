@@ -1,6 +1,10 @@
 package domain_errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrTitleRequired is returned when a task title is required
@@ -9,8 +13,6 @@ var (
 	ErrTimeRequired = errors.New("time is required")
 	// ErrDescriptionRequired is returned when a task description is required
 	ErrDescriptionRequired = errors.New("description is required")
-	// ErrDayOfWeekRequired is returned when a task day of the week is required
-	ErrDayOfWeekRequired = errors.New("day of the week is required")
 	// ErrDayRequired is returned when a day is required
 	ErrDayRequired = errors.New("day is required")
 )
@@ -26,11 +28,49 @@ var (
 	ErrInvalidYear = errors.New("invalid year")
 	// ErrInvalidTaskID is returned when a task ID is invalid
 	ErrInvalidTaskID = errors.New("invalid task ID")
+	// ErrInvalidRecurrenceRule is returned when a recurrence rule is invalid
+	ErrInvalidRecurrenceRule = errors.New("invalid recurrence rule")
+	// ErrInvalidRange is returned when a time range ends before it starts
+	ErrInvalidRange = errors.New("invalid time range")
+	// ErrUnboundedRecurrence is returned when every occurrence of a recurrence without COUNT or UNTIL is requested
+	ErrUnboundedRecurrence = errors.New("recurrence has no end")
+	// ErrInvalidOverride is returned when an occurrence override does not belong to the series
+	ErrInvalidOverride = errors.New("invalid occurrence override")
+	// ErrInvalidEditScope is returned when an edit scope is unknown
+	ErrInvalidEditScope = errors.New("invalid edit scope")
+	// ErrInvalidCalendarID is returned when a calendar ID is invalid
+	ErrInvalidCalendarID = errors.New("invalid calendar ID")
+	// ErrUnsupportedVersion is returned when encoded data has a schema version which is not supported
+	ErrUnsupportedVersion = errors.New("unsupported schema version")
+	// ErrNonexistentLocalTime is returned when a wall clock time is skipped by its time zone
+	ErrNonexistentLocalTime = errors.New("nonexistent local time")
+	// ErrInvalidEndTime is returned when a task would end before it starts
+	ErrInvalidEndTime = errors.New("end time must be after the start time")
+	// ErrAmbiguousLocalTime is returned when a wall clock time occurs twice in its time zone
+	ErrAmbiguousLocalTime = errors.New("ambiguous local time")
+	// ErrInvalidCursor is returned when a pagination cursor is malformed
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidPageSize is returned when a page size is negative
+	ErrInvalidPageSize = errors.New("invalid page size")
+	// ErrConflict is returned when a task overlaps other tasks
+	ErrConflict = errors.New("task conflicts with other tasks")
+	// ErrInvalidPriority is returned when a task priority is not within 0 and 9
+	ErrInvalidPriority = errors.New("invalid priority")
+	// ErrInvalidDuration is returned when a duration searched for is not positive or a buffer is negative
+	ErrInvalidDuration = errors.New("invalid duration")
+	// ErrInvalidWorkingHours is returned when working hours are not within a day or have no weekday
+	ErrInvalidWorkingHours = errors.New("invalid working hours")
+	// ErrUnknownTimeZone is returned when the IANA name of a time zone cannot be resolved
+	ErrUnknownTimeZone = errors.New("unknown time zone")
 )
 
 var (
 	// ErrTaskCannotBeNil is returned when a task is nil
 	ErrTaskCannotBeNil = errors.New("task is nil")
+	// ErrCalendarCannotBeNil is returned when a calendar is nil
+	ErrCalendarCannotBeNil = errors.New("calendar is nil")
+	// ErrMonthCannotBeNil is returned when a month is nil
+	ErrMonthCannotBeNil = errors.New("month is nil")
 )
 
 var (
@@ -38,6 +78,14 @@ var (
 	ErrDayNotFound = errors.New("day not found")
 	// ErrTaskNotFound is returned when a task is not found
 	ErrTaskNotFound = errors.New("task not found")
+	// ErrMonthNotFound is returned when a month is not found
+	ErrMonthNotFound = errors.New("month not found")
+	// ErrOccurrenceNotFound is returned when a time is not an occurrence of a recurring task
+	ErrOccurrenceNotFound = errors.New("occurrence not found")
+	// ErrCalendarNotFound is returned when a calendar is not found
+	ErrCalendarNotFound = errors.New("calendar not found")
+	// ErrNoAvailableSlot is returned when no free slot is long enough
+	ErrNoAvailableSlot = errors.New("no available slot")
 )
 
 var (
@@ -45,4 +93,25 @@ var (
 	ErrAddDay = errors.New("cannot add day")
 	// ErrAddMonth is returned when a month cannot be added
 	ErrAddMonth = errors.New("cannot add month")
+	// ErrAddTask is returned when a task cannot be added
+	ErrAddTask = errors.New("cannot add task")
 )
+
+// OccurrenceError is returned when a single occurrence of a recurring task
+// cannot be created or placed
+type OccurrenceError struct {
+	// Occurrence is the time of the failed occurrence within its series
+	Occurrence time.Time
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the error message including the occurrence time
+func (e *OccurrenceError) Error() string {
+	return fmt.Sprintf("occurrence at %s: %v", e.Occurrence.Format(time.RFC3339), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *OccurrenceError) Unwrap() error {
+	return e.Err
+}
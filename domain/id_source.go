@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDSource generates the identifiers of tasks
+//
+// NewID returns the identifier of a new original task, and NewOccurrenceID
+// the secondaryId of the copy of the series' occurrence at the given time.
+// The occurrence is zero when the copy is not tied to an occurrence.
+type IDSource interface {
+	NewID() uuid.UUID
+	NewOccurrenceID(primaryId uuid.UUID, occurrence time.Time) uuid.UUID
+}
+
+// defaultIDSource is used wherever no IDSource is given
+var defaultIDSource IDSource = RandomIDSource{}
+
+// RandomIDSource generates random UUIDv4 identifiers
+type RandomIDSource struct{}
+
+// NewID returns a random UUIDv4
+func (RandomIDSource) NewID() uuid.UUID {
+	return uuid.New()
+}
+
+// NewOccurrenceID returns a random UUIDv4
+func (RandomIDSource) NewOccurrenceID(uuid.UUID, time.Time) uuid.UUID {
+	return uuid.New()
+}
+
+// SortableIDSource generates UUIDv7 identifiers, which sort by creation time
+type SortableIDSource struct{}
+
+// NewID returns a UUIDv7
+func (SortableIDSource) NewID() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// NewOccurrenceID returns a UUIDv7
+func (SortableIDSource) NewOccurrenceID(uuid.UUID, time.Time) uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// DeterministicIDSource derives the secondaryId of every occurrence from its
+// series, so every node expanding a series generates the same copy IDs
+//
+// The secondaryId is the UUIDv5 of the occurrence time, in UTC, within the
+// namespace of the primaryId. New identifiers, and copies not tied to an
+// occurrence, come from the wrapped IDSource.
+// The zero value is ready to use and takes new identifiers as UUIDv7.
+type DeterministicIDSource struct {
+	ids IDSource
+}
+
+// NewDeterministicIDSource creates a DeterministicIDSource taking new identifiers from ids
+//
+// If ids is nil, new identifiers are UUIDv7.
+func NewDeterministicIDSource(ids IDSource) *DeterministicIDSource {
+	return &DeterministicIDSource{ids: ids}
+}
+
+// source returns the wrapped IDSource, UUIDv7 when there is none
+func (s DeterministicIDSource) source() IDSource {
+	if s.ids == nil {
+		return SortableIDSource{}
+	}
+
+	return s.ids
+}
+
+// NewID returns a new identifier from the wrapped IDSource
+func (s DeterministicIDSource) NewID() uuid.UUID {
+	return s.source().NewID()
+}
+
+// NewOccurrenceID returns the UUIDv5 of the occurrence within the primaryId namespace
+func (s DeterministicIDSource) NewOccurrenceID(primaryId uuid.UUID, occurrence time.Time) uuid.UUID {
+	if occurrence.IsZero() {
+		return s.source().NewOccurrenceID(primaryId, occurrence)
+	}
+
+	return uuid.NewSHA1(primaryId, []byte(occurrence.UTC().Format(time.RFC3339Nano)))
+}
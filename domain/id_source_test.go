@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceIDSource returns its identifiers in order, so tests can predict them
+type sequenceIDSource struct {
+	ids []uuid.UUID
+}
+
+func (s *sequenceIDSource) next() uuid.UUID {
+	id := s.ids[0]
+	s.ids = s.ids[1:]
+	return id
+}
+
+func (s *sequenceIDSource) NewID() uuid.UUID {
+	return s.next()
+}
+
+func (s *sequenceIDSource) NewOccurrenceID(uuid.UUID, time.Time) uuid.UUID {
+	return s.next()
+}
+
+func TestRandomIDSource(t *testing.T) {
+	source := RandomIDSource{}
+	primaryId := uuid.New()
+	occurrence := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	id := source.NewID()
+	assert.Equal(t, uuid.Version(4), id.Version())
+	assert.NotEqual(t, id, source.NewID())
+
+	copyId := source.NewOccurrenceID(primaryId, occurrence)
+	assert.Equal(t, uuid.Version(4), copyId.Version())
+	assert.NotEqual(t, copyId, source.NewOccurrenceID(primaryId, occurrence))
+}
+
+func TestSortableIDSource(t *testing.T) {
+	source := SortableIDSource{}
+
+	previous := source.NewID()
+	assert.Equal(t, uuid.Version(7), previous.Version())
+
+	for i := 0; i < 100; i++ {
+		id := source.NewID()
+		assert.Equal(t, 1, bytes.Compare(id[:], previous[:]), "IDs sort by creation")
+		previous = id
+	}
+
+	copyId := source.NewOccurrenceID(uuid.New(), time.Now())
+	assert.Equal(t, uuid.Version(7), copyId.Version())
+}
+
+func TestDeterministicIDSource(t *testing.T) {
+	primaryId := uuid.New()
+	occurrence := time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	source := NewDeterministicIDSource(nil)
+	other := NewDeterministicIDSource(RandomIDSource{})
+
+	id := source.NewOccurrenceID(primaryId, occurrence)
+	assert.Equal(t, uuid.Version(5), id.Version())
+
+	tests := []struct {
+		name       string
+		primaryId  uuid.UUID
+		occurrence time.Time
+		same       bool
+	}{
+		{
+			name:       "Same occurrence",
+			primaryId:  primaryId,
+			occurrence: occurrence,
+			same:       true,
+		},
+		{
+			name:       "Same instant in another zone",
+			primaryId:  primaryId,
+			occurrence: occurrence.In(paris),
+			same:       true,
+		},
+		{
+			name:       "Another occurrence",
+			primaryId:  primaryId,
+			occurrence: occurrence.AddDate(0, 0, 1),
+		},
+		{
+			name:       "Another series",
+			primaryId:  uuid.New(),
+			occurrence: occurrence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := other.NewOccurrenceID(tt.primaryId, tt.occurrence)
+			if tt.same {
+				assert.Equal(t, id, got)
+			} else {
+				assert.NotEqual(t, id, got)
+			}
+		})
+	}
+
+	assert.Equal(t, uuid.Version(7), source.NewID().Version())
+
+	// New identifiers and copies without an occurrence come from the wrapped source
+	want := []uuid.UUID{uuid.New(), uuid.New()}
+	sequence := NewDeterministicIDSource(&sequenceIDSource{ids: append([]uuid.UUID(nil), want...)})
+	assert.Equal(t, want[0], sequence.NewID())
+	assert.Equal(t, want[1], sequence.NewOccurrenceID(primaryId, time.Time{}))
+
+	// The zero value derives the same IDs and takes new identifiers as UUIDv7
+	var zero IDSource = DeterministicIDSource{}
+	assert.Equal(t, id, zero.NewOccurrenceID(primaryId, occurrence))
+	assert.Equal(t, uuid.Version(7), zero.NewID().Version())
+	assert.Equal(t, uuid.Version(7), zero.NewOccurrenceID(primaryId, time.Time{}).Version())
+}
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+// intervalIndex indexes the tasks placed in a calendar by the time they span
+//
+// It is an AVL tree ordered as CompareTasks orders tasks, where every node
+// tracks the latest end time within its subtree. Finding the tasks which
+// overlap a range takes O(log n + k) for k tasks found, however many days
+// they span. A task is indexed once, however many days it is placed on.
+// An intervalIndex is safe for concurrent use.
+type intervalIndex struct {
+	// mu guards root and tasks
+	mu    sync.RWMutex
+	root  *intervalNode
+	tasks map[*Task]bool
+}
+
+// intervalNode is a task of the index with the latest end time of its subtree
+type intervalNode struct {
+	task        *Task
+	end, maxEnd time.Time
+	height      int
+	left, right *intervalNode
+}
+
+// newIntervalIndex creates an empty index
+func newIntervalIndex() *intervalIndex {
+	return &intervalIndex{tasks: make(map[*Task]bool)}
+}
+
+// insert adds the task to the index, unless it is already indexed
+func (x *intervalIndex) insert(task *Task) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.tasks[task] {
+		return
+	}
+
+	x.tasks[task] = true
+	x.root = x.root.insert(&intervalNode{task: task, end: task.GetEndTime(), maxEnd: task.GetEndTime(), height: 1})
+}
+
+// remove removes the task from the index, if it is indexed
+func (x *intervalIndex) remove(task *Task) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.tasks[task] {
+		return
+	}
+
+	delete(x.tasks, task)
+	x.root = x.root.remove(task)
+}
+
+// replace replaces the indexed task with its new version
+func (x *intervalIndex) replace(old, task *Task) {
+	x.remove(old)
+	x.insert(task)
+}
+
+// find returns, in order, the tasks within [from, to) matching the condition
+//
+// A zero to leaves the end of the range open.
+func (x *intervalIndex) find(from, to time.Time, match func(*Task) bool) []*Task {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.root == nil {
+		return nil
+	}
+
+	if to.IsZero() {
+		to = x.root.maxEnd.Add(time.Nanosecond)
+	}
+
+	var tasks []*Task
+	x.root.overlapping(from, to, nil, func(task *Task) bool {
+		if match(task) {
+			tasks = append(tasks, task)
+		}
+		return true
+	})
+
+	return tasks
+}
+
+// len returns the number of indexed tasks
+func (x *intervalIndex) len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return len(x.tasks)
+}
+
+// overlapping calls visit, in order, for every task within [from, to) as
+// TaskQuery.includes tells, which is ordered after the cursor if there is one
+//
+// The walk stops when visit returns false.
+func (x *intervalIndex) overlapping(from, to time.Time, after *TaskCursor, visit func(*Task) bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	x.root.overlapping(from, to, after, visit)
+}
+
+// overlapping walks the subtree in order, pruning the subtrees which end
+// before from, start at or after to, or are not after the cursor
+func (n *intervalNode) overlapping(from, to time.Time, after *TaskCursor, visit func(*Task) bool) bool {
+	if n == nil || n.maxEnd.Before(from) {
+		return true
+	}
+
+	// Unless the node is after the cursor, neither it nor its left subtree is
+	afterCursor := after == nil || after.compare(n.task) > 0
+	if afterCursor && !n.left.overlapping(from, to, after, visit) {
+		return false
+	}
+
+	start := n.task.GetTime()
+	if !start.Before(to) {
+		return true
+	}
+
+	if afterCursor && (!start.Before(from) || n.end.After(from)) && !visit(n.task) {
+		return false
+	}
+
+	return n.right.overlapping(from, to, after, visit)
+}
+
+// insert adds the node to the subtree and returns its new root
+func (n *intervalNode) insert(node *intervalNode) *intervalNode {
+	if n == nil {
+		return node
+	}
+
+	if CompareTasks(node.task, n.task) < 0 {
+		n.left = n.left.insert(node)
+	} else {
+		n.right = n.right.insert(node)
+	}
+
+	return n.balance()
+}
+
+// remove removes the task from the subtree and returns its new root
+//
+// Tasks ordered the same may be on either side of each other, so both
+// subtrees are searched for them.
+func (n *intervalNode) remove(task *Task) *intervalNode {
+	if n == nil {
+		return nil
+	}
+
+	switch c := CompareTasks(task, n.task); {
+	case c < 0:
+		n.left = n.left.remove(task)
+	case c > 0:
+		n.right = n.right.remove(task)
+	case n.task != task:
+		n.left = n.left.remove(task)
+		n.right = n.right.remove(task)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+
+		// Take the place of the node with its successor
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+
+		n.right = n.right.remove(successor.task)
+		n.task, n.end = successor.task, successor.end
+	}
+
+	return n.balance()
+}
+
+// getHeight returns the height of the subtree, 0 if it is empty
+func (n *intervalNode) getHeight() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.height
+}
+
+// update recomputes the height and the latest end time of the node from its children
+func (n *intervalNode) update() {
+	n.height = 1 + max(n.left.getHeight(), n.right.getHeight())
+
+	n.maxEnd = n.end
+	for _, child := range []*intervalNode{n.left, n.right} {
+		if child != nil && child.maxEnd.After(n.maxEnd) {
+			n.maxEnd = child.maxEnd
+		}
+	}
+}
+
+// balance restores the AVL balance of the node and returns the root of its subtree
+func (n *intervalNode) balance() *intervalNode {
+	n.update()
+
+	switch factor := n.left.getHeight() - n.right.getHeight(); {
+	case factor > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case factor < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+
+	return n
+}
+
+// rotateLeft rotates the subtree left and returns its new root
+func (n *intervalNode) rotateLeft() *intervalNode {
+	root := n.right
+	n.right, root.left = root.left, n
+	n.update()
+	root.update()
+
+	return root
+}
+
+// rotateRight rotates the subtree right and returns its new root
+func (n *intervalNode) rotateRight() *intervalNode {
+	root := n.left
+	n.left, root.right = root.right, n
+	n.update()
+	root.update()
+
+	return root
+}
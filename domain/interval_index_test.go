@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkIntervalNode asserts the AVL balance, order and latest end times of
+// the subtree and returns its height
+func checkIntervalNode(t *testing.T, n *intervalNode) int {
+	t.Helper()
+
+	if n == nil {
+		return 0
+	}
+
+	left, right := checkIntervalNode(t, n.left), checkIntervalNode(t, n.right)
+	assert.LessOrEqual(t, left-right, 1)
+	assert.GreaterOrEqual(t, left-right, -1)
+	assert.Equal(t, 1+max(left, right), n.height)
+
+	maxEnd := n.end
+	for _, child := range []*intervalNode{n.left, n.right} {
+		if child != nil && child.maxEnd.After(maxEnd) {
+			maxEnd = child.maxEnd
+		}
+	}
+	assert.True(t, maxEnd.Equal(n.maxEnd))
+
+	if n.left != nil {
+		assert.LessOrEqual(t, CompareTasks(n.left.task, n.task), 0)
+	}
+	if n.right != nil {
+		assert.GreaterOrEqual(t, CompareTasks(n.right.task, n.task), 0)
+	}
+
+	return n.height
+}
+
+// scanOverlapping returns the tasks within [from, to) by checking every one of them
+func scanOverlapping(tasks []*Task, from, to time.Time) []*Task {
+	q := &TaskQuery{from: from, to: to}
+
+	var got []*Task
+	for _, task := range tasks {
+		if q.includes(task) {
+			got = append(got, task)
+		}
+	}
+	slices.SortFunc(got, CompareTasks)
+
+	return got
+}
+
+// randomTasks returns n tasks within the year 2024, lasting from nothing to three days
+func randomTasks(tb testing.TB, rnd *rand.Rand, n int) []*Task {
+	tb.Helper()
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tasks := make([]*Task, n)
+	for i := range tasks {
+		task, err := NewTask(NewTaskID(), "title", "description", false, 0, start.Add(time.Duration(rnd.Intn(365*24*4))*15*time.Minute))
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		if duration := time.Duration(rnd.Intn(3*24*4)) * 15 * time.Minute; duration > 0 {
+			if err := task.SetDuration(duration); err != nil {
+				tb.Fatal(err)
+			}
+		}
+
+		tasks[i] = task
+	}
+
+	return tasks
+}
+
+func TestIntervalIndex(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	tasks := randomTasks(t, rnd, 500)
+
+	// Some tasks share their time
+	for i := 0; i < 20; i++ {
+		shared, err := NewTask(NewTaskID(), "title", "description", false, 0, tasks[i].GetTime())
+		assert.NoError(t, err)
+		tasks = append(tasks, shared)
+	}
+
+	x := newIntervalIndex()
+	for _, task := range tasks {
+		x.insert(task)
+	}
+
+	// Removing a third of the tasks, and inserting again what is indexed, is consistent
+	indexed := tasks[:0:0]
+	for i, task := range tasks {
+		if i%3 == 0 {
+			x.remove(task)
+			continue
+		}
+		x.insert(task)
+		indexed = append(indexed, task)
+	}
+
+	assert.Equal(t, len(indexed), x.len())
+	checkIntervalNode(t, x.root)
+
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		from := start.Add(time.Duration(rnd.Intn(365*24)) * time.Hour)
+		to := from.Add(time.Duration(1+rnd.Intn(7*24*4)) * 15 * time.Minute)
+
+		var got []*Task
+		x.overlapping(from, to, nil, func(task *Task) bool {
+			got = append(got, task)
+			return true
+		})
+
+		assert.Equal(t, scanOverlapping(indexed, from, to), got, "[%s, %s)", from, to)
+	}
+
+	// find with an open end returns every indexed task matching, in order
+	all := x.find(time.Time{}, time.Time{}, func(*Task) bool { return true })
+	assert.Equal(t, scanOverlapping(indexed, time.Time{}, start.AddDate(2, 0, 0)), all)
+
+	var instant []*Task
+	for _, task := range all {
+		if task.GetDuration() == 0 {
+			instant = append(instant, task)
+		}
+	}
+	assert.Equal(t, instant, x.find(time.Time{}, time.Time{}, func(task *Task) bool { return task.GetDuration() == 0 }))
+
+	for _, task := range all {
+		x.remove(task)
+	}
+	assert.Equal(t, 0, x.len())
+	assert.Nil(t, x.root)
+}
+
+func TestIntervalIndex_overlapping(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, time.January, 10, hour, min, 0, 0, time.UTC)
+	}
+	task := func(start time.Time, duration time.Duration) *Task {
+		task := newTestTask(t, start, false, 0)
+		if duration > 0 {
+			assert.NoError(t, task.SetDuration(duration))
+		}
+		return task
+	}
+
+	multiDay := task(at(9, 0).AddDate(0, 0, -2), 72*time.Hour)
+	meeting := task(at(13, 30), time.Hour)
+	reminder := task(at(14, 0), 0)
+	late := task(at(15, 30), time.Hour)
+	ended := task(at(13, 0), time.Hour)
+
+	x := newIntervalIndex()
+	for _, task := range []*Task{late, reminder, ended, meeting, multiDay} {
+		x.insert(task)
+	}
+
+	// What overlaps 14:00-15:30?
+	var got []*Task
+	x.overlapping(at(14, 0), at(15, 30), nil, func(task *Task) bool {
+		got = append(got, task)
+		return true
+	})
+	assert.Equal(t, []*Task{multiDay, meeting, reminder}, got)
+
+	// Resuming after the meeting
+	got = nil
+	x.overlapping(at(14, 0), at(15, 30), newTaskCursor(meeting), func(task *Task) bool {
+		got = append(got, task)
+		return true
+	})
+	assert.Equal(t, []*Task{reminder}, got)
+
+	// Stopping at the first task
+	got = nil
+	x.overlapping(at(14, 0), at(15, 30), nil, func(task *Task) bool {
+		got = append(got, task)
+		return false
+	})
+	assert.Equal(t, []*Task{multiDay}, got)
+}
+
+func TestCalendar_index(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	series := newTestTask(t, start, true, 24*time.Hour)
+	assert.NoError(t, series.SetDuration(26*time.Hour))
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(ctx, series))
+
+	// placedTasks returns every task placed on a day of the calendar, once
+	placedTasks := func() []*Task {
+		var tasks []*Task
+		seen := make(map[*Task]bool)
+		for _, m := range c.getMonths() {
+			for _, d := range m.getDays() {
+				for _, task := range d.getTasks() {
+					if !seen[task] {
+						seen[task] = true
+						tasks = append(tasks, task)
+					}
+				}
+			}
+		}
+		slices.SortFunc(tasks, CompareTasks)
+
+		return tasks
+	}
+
+	indexedTasks := func() []*Task {
+		var tasks []*Task
+		c.index.overlapping(time.Time{}, start.AddDate(10, 0, 0), nil, func(task *Task) bool {
+			tasks = append(tasks, task)
+			return true
+		})
+
+		return tasks
+	}
+
+	assert.Equal(t, placedTasks(), indexedTasks())
+
+	steps := []func(t *testing.T){
+		func(t *testing.T) {
+			assert.NoError(t, c.DeleteTask(ctx, findTask(t, c, series.id.primaryId, 3), ThisOccurrence))
+		},
+		func(t *testing.T) {
+			edited := newTestTask(t, start.AddDate(0, 0, 5).Add(time.Hour), false, 0)
+			assert.NoError(t, c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, 5), edited, ThisOccurrence))
+		},
+		func(t *testing.T) {
+			edited := newTestTask(t, start.AddDate(0, 0, 10), false, 0)
+			assert.NoError(t, c.UpdateTask(ctx, findTask(t, c, series.id.primaryId, 11), edited, ThisAndFollowing))
+		},
+		func(t *testing.T) {
+			completed, err := seriesTask(t, c, series).occurrence(newTestCopyID(t, series.id), start.AddDate(0, 0, 6))
+			assert.NoError(t, err)
+			completed.Complete()
+			assert.NoError(t, c.RestoreOccurrences(completed))
+		},
+		func(t *testing.T) {
+			assert.NoError(t, c.DeleteTask(ctx, seriesTask(t, c, series), AllOccurrences))
+		},
+	}
+
+	for i, step := range steps {
+		t.Run(fmt.Sprint("Step ", i), func(t *testing.T) {
+			step(t)
+			assert.Equal(t, placedTasks(), indexedTasks())
+			checkIntervalNode(t, c.index.root)
+		})
+	}
+}
+
+// linearOverlapping returns the tasks within [from, to) by scanning the tasks
+// of every day of the calendar, as a multi-day task may start on any earlier day
+func linearOverlapping(c *Calendar, from, to time.Time) []*Task {
+	q := &TaskQuery{from: from, to: to}
+
+	var tasks []*Task
+	seen := make(map[*Task]bool)
+	for _, m := range c.getMonths() {
+		for _, d := range m.getDays() {
+			d.mu.RLock()
+			for _, task := range d.tasks {
+				if !seen[task] && q.includes(task) {
+					seen[task] = true
+					tasks = append(tasks, task)
+				}
+			}
+			d.mu.RUnlock()
+		}
+	}
+	slices.SortFunc(tasks, CompareTasks)
+
+	return tasks
+}
+
+func BenchmarkCalendar_overlapping(b *testing.B) {
+	from := time.Date(2024, time.June, 12, 14, 0, 0, 0, time.UTC)
+	to := from.Add(90 * time.Minute)
+
+	for _, n := range []int{1_000, 10_000, 100_000} {
+		c := NewCalendar()
+		for _, task := range randomTasks(b, rand.New(rand.NewSource(1)), n) {
+			if err := c.AddTask(context.Background(), task); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.Run(fmt.Sprintf("index/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := c.GetTasksBetween(from, to); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("linear/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				linearOverlapping(c, from, to)
+			}
+		})
+	}
+}
@@ -0,0 +1,528 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// jsonVersion is the version of the JSON schema written by MarshalJSON
+const jsonVersion = 1
+
+// taskJSON is the JSON schema of a Task, version 1
+//
+//	{
+//	  "version": 1,
+//	  "id": "<primaryId>-<secondaryId>-original",
+//	  "title": "Stand-up",
+//	  "description": "Daily stand-up",
+//	  "completed": false,
+//	  "dayOfWeek": 1,
+//	  "time": "2024-01-01T09:00:00+01:00",
+//	  "timeZone": "Europe/Madrid",
+//	  "duration": "1h30m0s",
+//	  "allDay": false,
+//	  "priority": 1,
+//	  "repeating": true,
+//	  "repeatingInterval": "24h0m0s",
+//	  "recurrenceRule": "FREQ=DAILY;COUNT=10",
+//	  "occurrenceTime": "2024-01-01T09:00:00+01:00",
+//	  "exceptionDates": ["2024-01-02T09:00:00+01:00"],
+//	  "recurrenceDates": ["2024-01-20T09:00:00+01:00"],
+//	  "overrides": [{ ... }]
+//	}
+//
+// id is the TaskID in its String form. dayOfWeek, the time.Weekday of time in
+// its time zone, is written for readers and ignored when read. Times
+// are RFC 3339; timeZone is the IANA name of their location, omitted for UTC,
+// and a name the time zone database doesn't know keeps the offset of the
+// times. time.Local is written as the IANA name it was loaded from. duration and repeatingInterval are time.Duration
+// strings; duration is omitted for a task without an end, and is a whole
+// number of days for an all-day task. priority is omitted when undefined.
+// recurrenceRule is an RFC 5545 RRULE value. occurrenceTime is only written
+// for a copy moved away from its occurrence. The exceptions of a series,
+// including its overrides as tasks without version, are only written for an
+// original task.
+type taskJSON struct {
+	Version           int         `json:"version,omitempty"`
+	ID                *TaskID     `json:"id"`
+	Title             string      `json:"title"`
+	Description       string      `json:"description"`
+	Completed         bool        `json:"completed"`
+	DayOfWeek         int         `json:"dayOfWeek"`
+	Time              time.Time   `json:"time"`
+	TimeZone          string      `json:"timeZone,omitempty"`
+	Duration          string      `json:"duration,omitempty"`
+	AllDay            bool        `json:"allDay,omitempty"`
+	Priority          int         `json:"priority,omitempty"`
+	Repeating         bool        `json:"repeating,omitempty"`
+	RepeatingInterval string      `json:"repeatingInterval,omitempty"`
+	RecurrenceRule    string      `json:"recurrenceRule,omitempty"`
+	OccurrenceTime    *time.Time  `json:"occurrenceTime,omitempty"`
+	ExceptionDates    []time.Time `json:"exceptionDates,omitempty"`
+	RecurrenceDates   []time.Time `json:"recurrenceDates,omitempty"`
+	Overrides         []*taskJSON `json:"overrides,omitempty"`
+}
+
+// dayJSON is the JSON schema of a Day, version 1
+//
+//	{ "version": 1, "day": 5, "tasks": [{ ... }], "continued": ["<id>"] }
+//
+// The tasks are written sorted by time, without version. Within a month, a
+// task spanning several days is written on its first day of the month, and
+// its later days list its ID in continued instead.
+type dayJSON struct {
+	Version   int         `json:"version,omitempty"`
+	Day       int         `json:"day"`
+	Tasks     []*taskJSON `json:"tasks"`
+	Continued []*TaskID   `json:"continued,omitempty"`
+}
+
+// monthJSON is the JSON schema of a Month, version 1
+//
+//	{ "version": 1, "year": 2024, "month": 1, "days": [{ ... }] }
+//
+// The days are written sorted by day, without version.
+type monthJSON struct {
+	Version int        `json:"version"`
+	Year    int        `json:"year"`
+	Month   int        `json:"month"`
+	Days    []*dayJSON `json:"days"`
+}
+
+// MarshalJSON encodes the TaskID as its String form
+func (ti TaskID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ti.String())
+}
+
+// UnmarshalJSON decodes a TaskID from its String form
+//
+// If the string is not a TaskID, UnmarshalJSON returns domain_errors.ErrInvalidTaskID.
+func (ti *TaskID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.Join(domain_errors.ErrInvalidTaskID, err)
+	}
+
+	return ti.UnmarshalText([]byte(s))
+}
+
+// MarshalJSON encodes the task following the version 1 schema
+//
+// If the task is in time.Local and the IANA name of the local time zone
+// cannot be resolved, MarshalJSON returns domain_errors.ErrUnknownTimeZone.
+func (t *Task) MarshalJSON() ([]byte, error) {
+	v, err := t.toJSON()
+	if err != nil {
+		return nil, err
+	}
+	v.Version = jsonVersion
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a task written following the version 1 schema
+//
+// The task is validated like NewTask does. If the version is not supported,
+// UnmarshalJSON returns domain_errors.ErrUnsupportedVersion.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	var v taskJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	if err := checkVersion(v.Version); err != nil {
+		return err
+	}
+
+	task, err := v.task()
+	if err != nil {
+		return err
+	}
+
+	*t = *task
+
+	return nil
+}
+
+// toJSON returns the JSON form of the task
+func (t *Task) toJSON() (*taskJSON, error) {
+	zone, err := zoneName(t.time.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	v := &taskJSON{
+		ID:          t.id,
+		Title:       t.title,
+		Description: t.description,
+		Completed:   t.completed,
+		DayOfWeek:   int(t.GetDayOfWeek()),
+		Time:        t.time,
+		TimeZone:    zone,
+		AllDay:      t.allDay,
+		Priority:    t.priority,
+		Repeating:   t.repeating,
+	}
+
+	if t.duration != 0 {
+		v.Duration = t.duration.String()
+	}
+
+	if t.repeatingInterval != 0 {
+		v.RepeatingInterval = t.repeatingInterval.String()
+	}
+
+	if t.recurrence != nil {
+		v.RecurrenceRule = t.recurrence.String()
+	}
+
+	if occurrence := t.GetOccurrenceTime(); !occurrence.Equal(t.time) {
+		v.OccurrenceTime = &occurrence
+	}
+
+	if t.id != nil && t.id.original {
+		v.ExceptionDates = t.GetExceptionDates()
+		v.RecurrenceDates = t.GetRecurrenceDates()
+
+		for _, override := range t.sortedOverrides() {
+			o, err := override.toJSON()
+			if err != nil {
+				return nil, err
+			}
+			v.Overrides = append(v.Overrides, o)
+		}
+	}
+
+	return v, nil
+}
+
+// task rehydrates the task of its JSON form
+func (v *taskJSON) task() (*Task, error) {
+	if v.ID == nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, domain_errors.ErrInvalidTaskID)
+	}
+
+	loc := loadZone(v.TimeZone)
+
+	var err error
+	var duration time.Duration
+	if v.Duration != "" {
+		if duration, err = time.ParseDuration(v.Duration); err != nil {
+			return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+		}
+	}
+
+	var interval time.Duration
+	if v.RepeatingInterval != "" {
+		if interval, err = time.ParseDuration(v.RepeatingInterval); err != nil {
+			return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+		}
+	}
+
+	var rule *RecurrenceRule
+	if v.RecurrenceRule != "" {
+		if rule, err = ParseRecurrenceRule(v.RecurrenceRule); err != nil {
+			return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+		}
+	}
+
+	task, err := RehydrateTask(
+		v.ID,
+		v.Title, v.Description,
+		v.Repeating, interval,
+		rule,
+		v.Completed,
+		inZone(v.Time, loc))
+	if err != nil {
+		return nil, err
+	}
+
+	task.duration, task.allDay, task.priority = duration, v.AllDay, v.Priority
+	if err := task.Validate(); err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	if v.OccurrenceTime != nil {
+		task.occurrenceTime = inZone(*v.OccurrenceTime, loc)
+	}
+
+	var errc error
+	for _, exDate := range v.ExceptionDates {
+		errc = errors.Join(errc, task.ExcludeOccurrence(inZone(exDate, loc)))
+	}
+
+	for _, rDate := range v.RecurrenceDates {
+		errc = errors.Join(errc, task.AddOccurrence(inZone(rDate, loc)))
+	}
+
+	for _, o := range v.Overrides {
+		override, err := o.task()
+		if err != nil {
+			errc = errors.Join(errc, err)
+			continue
+		}
+
+		errc = errors.Join(errc, task.OverrideOccurrence(override.GetOccurrenceTime(), override))
+	}
+
+	if errc != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, errc)
+	}
+
+	return task, nil
+}
+
+// MarshalJSON encodes the day following the version 1 schema
+func (d *Day) MarshalJSON() ([]byte, error) {
+	v, err := d.toJSON(nil)
+	if err != nil {
+		return nil, err
+	}
+	v.Version = jsonVersion
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a day written following the version 1 schema
+//
+// The day and its tasks are validated like NewDay and NewTask do.
+// If the version is not supported, UnmarshalJSON returns domain_errors.ErrUnsupportedVersion.
+func (d *Day) UnmarshalJSON(data []byte) error {
+	var v dayJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Join(domain_errors.ErrInvalidDay, err)
+	}
+
+	if err := checkVersion(v.Version); err != nil {
+		return err
+	}
+
+	day, err := v.day(nil)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.day, d.date, d.tasks = day.day, day.date, day.tasks
+
+	return nil
+}
+
+// toJSON returns the JSON form of the day
+//
+// Tasks already in written are listed as continued, the others are written
+// and recorded in it. A nil written writes every task.
+func (d *Day) toJSON(written map[*Task]bool) (*dayJSON, error) {
+	tasks := d.getTasks()
+
+	v := &dayJSON{Day: d.day, Tasks: make([]*taskJSON, 0, len(tasks))}
+	for _, task := range tasks {
+		if written != nil {
+			if written[task] {
+				v.Continued = append(v.Continued, task.id)
+				continue
+			}
+
+			written[task] = true
+		}
+
+		t, err := task.toJSON()
+		if err != nil {
+			return nil, err
+		}
+		v.Tasks = append(v.Tasks, t)
+	}
+
+	return v, nil
+}
+
+// day rehydrates the day of its JSON form
+//
+// Its tasks are added to read, by ID, and its continued tasks are looked up
+// there. A nil read only allows days without continued tasks.
+// If a continued task was not read, day returns domain_errors.ErrTaskNotFound.
+func (v *dayJSON) day(read map[TaskID]*Task) (*Day, error) {
+	d, err := NewDay(v.Day)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range v.Tasks {
+		task, err := t.task()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := d.addTask(task); err != nil {
+			return nil, err
+		}
+
+		if read != nil {
+			read[*task.id] = task
+		}
+	}
+
+	for _, id := range v.Continued {
+		task, exists := read[*id]
+		if !exists {
+			return nil, errors.Join(domain_errors.ErrTaskNotFound, fmt.Errorf("continued task %s", id))
+		}
+
+		if err := d.addTask(task); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// MarshalJSON encodes the month following the version 1 schema
+func (m *Month) MarshalJSON() ([]byte, error) {
+	days := m.getDays()
+	sort.Slice(days, func(i, j int) bool { return days[i].day < days[j].day })
+
+	written := make(map[*Task]bool)
+
+	v := monthJSON{Version: jsonVersion, Year: m.year, Month: int(m.month), Days: make([]*dayJSON, 0, len(days))}
+	for _, d := range days {
+		dv, err := d.toJSON(written)
+		if err != nil {
+			return nil, err
+		}
+		v.Days = append(v.Days, dv)
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a month written following the version 1 schema
+//
+// The month, its days and their tasks are validated like NewMonth, NewDay
+// and NewTask do. If the version is not supported, UnmarshalJSON returns
+// domain_errors.ErrUnsupportedVersion.
+func (m *Month) UnmarshalJSON(data []byte) error {
+	var v monthJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return errors.Join(domain_errors.ErrInvalidMonth, err)
+	}
+
+	if err := checkVersion(v.Version); err != nil {
+		return err
+	}
+
+	month, err := NewMonth(time.Month(v.Month), v.Year)
+	if err != nil {
+		return err
+	}
+
+	read := make(map[TaskID]*Task)
+	for _, dv := range v.Days {
+		d, err := dv.day(read)
+		if err != nil {
+			return err
+		}
+
+		if _, exists := month.days[d.day]; exists {
+			return errors.Join(domain_errors.ErrInvalidDay, fmt.Errorf("duplicate day %d", d.day))
+		}
+		d.date = month.date(d.day)
+		month.days[d.day] = d
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.month, m.year, m.days = month.month, month.year, month.days
+
+	return nil
+}
+
+// checkVersion returns domain_errors.ErrUnsupportedVersion unless the version is supported
+func checkVersion(version int) error {
+	if version != jsonVersion {
+		return errors.Join(domain_errors.ErrUnsupportedVersion, fmt.Errorf("version %d", version))
+	}
+
+	return nil
+}
+
+// zoneName returns the name of the location, empty for UTC
+//
+// time.Local is named after the IANA name it was loaded from. If that name
+// cannot be resolved, zoneName returns domain_errors.ErrUnknownTimeZone.
+func zoneName(loc *time.Location) (string, error) {
+	switch name := loc.String(); name {
+	case "UTC":
+		return "", nil
+	case "Local":
+		return localZoneName()
+	default:
+		return name, nil
+	}
+}
+
+// localZoneName resolves the IANA name of time.Local
+//
+// Like the time package, it reads the TZ environment variable and falls back
+// to the target of the /etc/localtime link.
+func localZoneName() (string, error) {
+	source, ok := os.LookupEnv("TZ")
+	if !ok {
+		link, err := os.Readlink("/etc/localtime")
+		if err != nil {
+			return "", errors.Join(domain_errors.ErrUnknownTimeZone, fmt.Errorf("local time zone: %w", err))
+		}
+		source = link
+	}
+
+	name := strings.TrimPrefix(source, ":")
+	if i := strings.LastIndex(name, "zoneinfo/"); i >= 0 {
+		name = name[i+len("zoneinfo/"):]
+	}
+
+	switch name {
+	case "", "UTC":
+		return "", nil
+	}
+
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", errors.Join(domain_errors.ErrUnknownTimeZone, fmt.Errorf("local time zone %q: %w", source, err))
+	}
+
+	return name, nil
+}
+
+// loadZone loads the location of an IANA name
+//
+// It returns nil for an empty name or one the time zone database doesn't
+// know, such as the name of a fixed zone; the time then keeps its offset.
+func loadZone(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+
+	return loc
+}
+
+// inZone returns the time in the location, unchanged for a nil location
+func inZone(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+
+	return t.In(loc)
+}
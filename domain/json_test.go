@@ -0,0 +1,294 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskID_JSON(t *testing.T) {
+	original := NewTaskID()
+	copied := newTestCopyID(t, original)
+
+	for _, id := range []*TaskID{original, copied} {
+		data, err := json.Marshal(id)
+		assert.NoError(t, err)
+		assert.Equal(t, `"`+id.String()+`"`, string(data))
+
+		var got TaskID
+		assert.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, *id, got)
+	}
+
+	invalid := []string{
+		`"not-a-task-id"`,
+		`"` + original.primaryId.String() + `-` + original.primaryId.String() + `-copy"`,
+		`"` + original.primaryId.String() + `-` + original.primaryId.String() + `-other"`,
+		`42`,
+	}
+	for _, data := range invalid {
+		var got TaskID
+		assert.ErrorIs(t, json.Unmarshal([]byte(data), &got), domain_errors.ErrInvalidTaskID, data)
+	}
+}
+
+func TestTask_JSON(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 25, 9, 0, 0, 0, madrid)
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=10")
+	assert.NoError(t, err)
+
+	series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+	assert.NoError(t, err)
+	assert.NoError(t, series.ExcludeOccurrence(start.AddDate(0, 0, 1)))
+	assert.NoError(t, series.AddOccurrence(start.AddDate(0, 0, 20)))
+
+	override, err := series.occurrence(newTestCopyID(t, series.id), start.AddDate(0, 0, 3).Add(time.Hour))
+	assert.NoError(t, err)
+	override.Complete()
+	assert.NoError(t, series.OverrideOccurrence(start.AddDate(0, 0, 2), override))
+
+	interval := newTestTask(t, start, true, 48*time.Hour)
+	assert.NoError(t, interval.SetPriority(2))
+
+	for _, task := range []*Task{series, interval, override} {
+		data, err := json.Marshal(task)
+		assert.NoError(t, err)
+
+		var got Task
+		assert.NoError(t, json.Unmarshal(data, &got))
+
+		assert.Equal(t, task.GetID(), got.GetID())
+		assert.Equal(t, task.GetTitle(), got.GetTitle())
+		assert.Equal(t, task.IsCompleted(), got.IsCompleted())
+		assert.Equal(t, task.GetPriority(), got.GetPriority())
+		assert.True(t, task.GetTime().Equal(got.GetTime()))
+		assert.Equal(t, task.GetTime().Location().String(), got.GetTime().Location().String())
+		assert.True(t, task.GetOccurrenceTime().Equal(got.GetOccurrenceTime()))
+		assert.Equal(t, task.GetRecurrenceRule().String(), got.GetRecurrenceRule().String())
+
+		gotRepeating, gotInterval := got.IsRepeating()
+		repeating, interval := task.IsRepeating()
+		assert.Equal(t, repeating, gotRepeating)
+		assert.Equal(t, interval, gotInterval)
+
+		assert.Len(t, got.GetExceptionDates(), len(task.GetExceptionDates()))
+		assert.Len(t, got.GetRecurrenceDates(), len(task.GetRecurrenceDates()))
+		assert.Len(t, got.GetOverrides(), len(task.GetOverrides()))
+	}
+
+	data, err := json.Marshal(series)
+	assert.NoError(t, err)
+
+	var got Task
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	gotOverride, exists := got.GetOverride(start.AddDate(0, 0, 2))
+	assert.True(t, exists)
+	assert.True(t, gotOverride.IsCompleted())
+	assert.Equal(t, override.GetID(), gotOverride.GetID())
+}
+
+func TestTask_UnmarshalJSON_invalid(t *testing.T) {
+	id := NewTaskID().String()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{
+			name:    "Missing title",
+			data:    `{"version":1,"id":"` + id + `","description":"d","dayOfWeek":1,"time":"2024-01-01T09:00:00Z"}`,
+			wantErr: domain_errors.ErrTitleRequired,
+		},
+		{
+			name:    "Missing time",
+			data:    `{"version":1,"id":"` + id + `","title":"t","description":"d","dayOfWeek":1}`,
+			wantErr: domain_errors.ErrTimeRequired,
+		},
+		{
+			name:    "Missing ID",
+			data:    `{"version":1,"title":"t","description":"d","dayOfWeek":1,"time":"2024-01-01T09:00:00Z"}`,
+			wantErr: domain_errors.ErrInvalidTaskID,
+		},
+		{
+			name:    "Invalid recurrence rule",
+			data:    `{"version":1,"id":"` + id + `","title":"t","description":"d","dayOfWeek":1,"time":"2024-01-01T09:00:00Z","recurrenceRule":"FREQ=SOMETIMES"}`,
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Unsupported version",
+			data:    `{"version":2,"id":"` + id + `","title":"t","description":"d","dayOfWeek":1,"time":"2024-01-01T09:00:00Z"}`,
+			wantErr: domain_errors.ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task Task
+			assert.ErrorIs(t, json.Unmarshal([]byte(tt.data), &task), tt.wantErr)
+		})
+	}
+}
+
+func TestTask_JSON_dayOfWeek(t *testing.T) {
+	// 2024-01-07 is a Sunday, whatever dayOfWeek claims
+	data := `{"version":1,"id":"` + NewTaskID().String() + `","title":"t","description":"d","dayOfWeek":3,"time":"2024-01-07T09:00:00Z"}`
+
+	var task Task
+	assert.NoError(t, json.Unmarshal([]byte(data), &task))
+	assert.Equal(t, time.Sunday, task.GetDayOfWeek())
+
+	written, err := json.Marshal(&task)
+	assert.NoError(t, err)
+	assert.Contains(t, string(written), `"dayOfWeek":0`)
+}
+
+func TestLocalZoneName(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		want    string
+		wantErr error
+	}{
+		{name: "IANA name", tz: "Europe/Madrid", want: "Europe/Madrid"},
+		{name: "Prefixed name", tz: ":America/New_York", want: "America/New_York"},
+		{name: "Zone file", tz: "/usr/share/zoneinfo/Asia/Tokyo", want: "Asia/Tokyo"},
+		{name: "UTC", tz: "UTC", want: ""},
+		{name: "Empty", tz: "", want: ""},
+		{name: "Unknown", tz: "Nowhere/Unknown", wantErr: domain_errors.ErrUnknownTimeZone},
+		{name: "Unknown file", tz: "/etc/custom-zone", wantErr: domain_errors.ErrUnknownTimeZone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TZ", tt.tz)
+
+			got, err := localZoneName()
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMonth_JSON(t *testing.T) {
+	m, err := NewMonth(time.January, 2024)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	for _, taskTime := range []time.Time{start, start.Add(time.Hour), start.AddDate(0, 0, 14)} {
+		d, err := m.addDay(taskTime.Day())
+		assert.NoError(t, err)
+		assert.NoError(t, d.addTask(newTestTask(t, taskTime, false, 0)))
+	}
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+
+	var got Month
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, m.GetMonth(), got.GetMonth())
+	assert.Equal(t, m.GetYear(), got.GetYear())
+
+	tasks, gotTasks := m.GetTasks(), got.GetTasks()
+	assert.Len(t, gotTasks, len(tasks))
+	for i := range tasks {
+		assert.Equal(t, tasks[i].GetID(), gotTasks[i].GetID())
+	}
+
+	d, err := got.getDay(15)
+	assert.NoError(t, err)
+	assert.Len(t, d.getTasks(), 1)
+
+	var invalid Month
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"version":1,"year":2024,"month":13,"days":[]}`), &invalid), domain_errors.ErrInvalidMonth)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"version":1,"year":2024,"month":1,"days":[{"day":32,"tasks":[]}]}`), &invalid), domain_errors.ErrInvalidDay)
+}
+
+func TestDay_JSON(t *testing.T) {
+	d, err := NewDay(3)
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.January, 3, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, d.addTask(newTestTask(t, start.Add(time.Hour), false, 0)))
+	assert.NoError(t, d.addTask(newTestTask(t, start, false, 0)))
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+
+	var got Day
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	day, _ := got.getDay()
+	assert.Equal(t, 3, day)
+	assert.Len(t, got.getTasks(), 2)
+	assert.True(t, got.getTasks()[0].GetTime().Equal(start))
+
+	// A day of another month decoded again forgets the date of that month
+	m, err := NewMonth(time.February, 2024)
+	assert.NoError(t, err)
+	reused, err := m.addDay(3)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, reused))
+
+	_, weekday := reused.getDay()
+	assert.Equal(t, time.Wednesday, weekday)
+}
+
+func TestMonth_JSON_multiDay(t *testing.T) {
+	start := time.Date(2024, time.January, 9, 0, 0, 0, 0, time.UTC)
+
+	allDay := newTestTask(t, start, false, 0)
+	assert.NoError(t, allDay.SetAllDay(3))
+
+	meeting := newTestTask(t, start.Add(9*time.Hour), false, 0)
+	assert.NoError(t, meeting.SetDuration(90*time.Minute))
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(context.Background(), allDay))
+	assert.NoError(t, c.AddTask(context.Background(), meeting))
+
+	m, err := c.GetMonth(time.January, 2024)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+
+	// The all-day task is written once and continued on its other days
+	assert.Equal(t, 2, strings.Count(string(data), `"title":`))
+	assert.Equal(t, 2, strings.Count(string(data), `"continued"`))
+
+	var got Month
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	gotTasks := got.GetTasks()
+	assert.Len(t, gotTasks, 2)
+	assert.True(t, gotTasks[0].IsAllDay())
+	assert.Equal(t, allDay.GetEndTime(), gotTasks[0].GetEndTime())
+	assert.Equal(t, 90*time.Minute, gotTasks[1].GetDuration())
+
+	for day := 9; day <= 11; day++ {
+		d, err := got.getDay(day)
+		assert.NoError(t, err)
+		assert.Same(t, gotTasks[0], d.getTasks()[0], "day %d", day)
+	}
+
+	var invalid Month
+	unknown := `{"version":1,"year":2024,"month":1,"days":[{"day":2,"tasks":[],"continued":["` + NewTaskID().String() + `"]}]}`
+	assert.ErrorIs(t, json.Unmarshal([]byte(unknown), &invalid), domain_errors.ErrTaskNotFound)
+
+	invalidEnd := `{"version":1,"id":"` + NewTaskID().String() + `","title":"t","description":"d","dayOfWeek":1,"time":"2024-01-01T09:00:00Z","duration":"-1h"}`
+	var task Task
+	assert.ErrorIs(t, json.Unmarshal([]byte(invalidEnd), &task), domain_errors.ErrInvalidEndTime)
+}
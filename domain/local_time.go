@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// LocalTimePolicy decides how a wall clock time a time zone skips or repeats is resolved
+type LocalTimePolicy int
+
+const (
+	// ShiftLocalTime moves a nonexistent time forward by the length of the
+	// gap and resolves an ambiguous time to its earlier instant, as RFC 5545 does
+	ShiftLocalTime LocalTimePolicy = iota
+	// RejectLocalTime rejects nonexistent and ambiguous times
+	RejectLocalTime
+)
+
+// LocalTime returns the instant the wall clock time occurs at in the zone
+//
+// Unlike time.Date, which leaves the result unspecified, a wall clock time
+// skipped by a daylight saving transition (e.g. 02:30 when clocks jump from
+// 02:00 to 03:00) or repeated by one (e.g. 02:30 when clocks fall back from
+// 03:00 to 02:00) is resolved by the policy.
+// With RejectLocalTime, LocalTime returns domain_errors.ErrNonexistentLocalTime
+// or domain_errors.ErrAmbiguousLocalTime for such times.
+func LocalTime(year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location, policy LocalTimePolicy) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	instant, kind := resolveLocalTime(time.Date(year, month, day, hour, min, sec, nsec, time.UTC), loc)
+
+	if policy == RejectLocalTime {
+		wall := fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", year, month, day, hour, min, sec)
+
+		switch kind {
+		case nonexistentLocalTime:
+			return time.Time{}, errors.Join(domain_errors.ErrNonexistentLocalTime, fmt.Errorf("%s in %s", wall, loc))
+		case ambiguousLocalTime:
+			return time.Time{}, errors.Join(domain_errors.ErrAmbiguousLocalTime, fmt.Errorf("%s in %s", wall, loc))
+		}
+	}
+
+	return instant, nil
+}
+
+// localTimeKind tells whether a wall clock time occurs once, never or twice in a zone
+type localTimeKind int
+
+const (
+	uniqueLocalTime localTimeKind = iota
+	nonexistentLocalTime
+	ambiguousLocalTime
+)
+
+// resolveLocalTime returns the instant the wall clock time, given as a UTC
+// time with the same fields, occurs at in the zone, resolved as ShiftLocalTime does
+//
+// The offsets in effect a day before and after the wall clock time are the
+// candidates: a candidate is valid when the zone is at that offset at the
+// instant it gives. Two valid candidates make the time ambiguous, none make it
+// fall within a gap, which is skipped using the offset in effect before it.
+func resolveLocalTime(wall time.Time, loc *time.Location) (time.Time, localTimeKind) {
+	offsetAt := func(instant time.Time) int {
+		_, offset := instant.In(loc).Zone()
+		return offset
+	}
+
+	before := offsetAt(wall.Add(-24 * time.Hour))
+	after := offsetAt(wall.Add(24 * time.Hour))
+
+	at := func(offset int) time.Time {
+		return wall.Add(-time.Duration(offset) * time.Second).In(loc)
+	}
+
+	beforeValid := offsetAt(at(before)) == before
+	afterValid := offsetAt(at(after)) == after
+
+	switch {
+	case before != after && beforeValid && afterValid:
+		// The earlier instant has the larger offset
+		earlier, later := at(before), at(after)
+		if later.Before(earlier) {
+			earlier = later
+		}
+		return earlier, ambiguousLocalTime
+	case beforeValid:
+		return at(before), uniqueLocalTime
+	case afterValid:
+		return at(after), uniqueLocalTime
+	default:
+		return at(before), nonexistentLocalTime
+	}
+}
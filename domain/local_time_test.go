@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		month   time.Month
+		day     int
+		loc     *time.Location
+		policy  LocalTimePolicy
+		want    time.Time
+		wantErr error
+	}{
+		{
+			name:  "Unique",
+			month: time.January,
+			day:   10,
+			loc:   paris,
+			want:  time.Date(2024, time.January, 10, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "Nonexistent moves forward by the gap",
+			month: time.March,
+			day:   31,
+			loc:   paris,
+			want:  time.Date(2024, time.March, 31, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "Ambiguous resolves to the earlier instant",
+			month: time.October,
+			day:   27,
+			loc:   paris,
+			want:  time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "Unique with RejectLocalTime",
+			month:  time.January,
+			day:    10,
+			loc:    paris,
+			policy: RejectLocalTime,
+			want:   time.Date(2024, time.January, 10, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "Nonexistent with RejectLocalTime",
+			month:   time.March,
+			day:     31,
+			loc:     paris,
+			policy:  RejectLocalTime,
+			wantErr: domain_errors.ErrNonexistentLocalTime,
+		},
+		{
+			name:    "Ambiguous with RejectLocalTime",
+			month:   time.October,
+			day:     27,
+			loc:     paris,
+			policy:  RejectLocalTime,
+			wantErr: domain_errors.ErrAmbiguousLocalTime,
+		},
+		{
+			name:  "Nil location is UTC",
+			month: time.March,
+			day:   31,
+			want:  time.Date(2024, time.March, 31, 2, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LocalTime(2024, tt.month, tt.day, 2, 30, 0, 0, tt.loc, tt.policy)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+
+			if tt.loc != nil {
+				assert.Equal(t, tt.loc, got.Location())
+			}
+		})
+	}
+}
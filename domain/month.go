@@ -2,13 +2,19 @@ package domain
 
 import (
 	"errors"
+	"sort"
+	"sync"
 	"time"
 
 	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
 )
 
 // Month represents a calendar month
+//
+// A Month is safe for concurrent use.
 type Month struct {
+	// mu guards days
+	mu    sync.RWMutex
 	month time.Month
 	year  int
 	days  map[int]*Day
@@ -27,6 +33,37 @@ func (m *Month) Validate() error {
 	return nil
 }
 
+// GetMonth returns the month of the year
+func (m *Month) GetMonth() time.Month {
+	return m.month
+}
+
+// GetYear returns the year of the month
+func (m *Month) GetYear() int {
+	return m.year
+}
+
+// GetTasks returns a snapshot of the tasks of every day of the month, sorted by time
+//
+// A task spanning several days of the month is returned once.
+func (m *Month) GetTasks() []*Task {
+	days := m.getDays()
+	sort.Slice(days, func(i, j int) bool { return days[i].day < days[j].day })
+
+	var tasks []*Task
+	seen := make(map[*Task]bool)
+	for _, d := range days {
+		for _, task := range d.getTasks() {
+			if !seen[task] {
+				seen[task] = true
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	return tasks
+}
+
 // NewMonth creates a new month
 func NewMonth(month time.Month, year int) (*Month, error) {
 	m := &Month{
@@ -49,6 +86,9 @@ func NewMonth(month time.Month, year int) (*Month, error) {
 // If the day cannot be added, addDay returns domain_errors.ErrAddDay.
 // If the day is added successfully, addDay returns the day.
 func (m *Month) addDay(day int) (*Day, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	var d *Day
 
 	// Validate the day exists
@@ -63,6 +103,8 @@ func (m *Month) addDay(day int) (*Day, error) {
 		return nil, errors.Join(domain_errors.ErrAddDay, err)
 	}
 
+	d.date = m.date(day)
+
 	// int day
 	var id int
 
@@ -76,16 +118,24 @@ func (m *Month) addDay(day int) (*Day, error) {
 // addTaskToDay adds a task to a specific day of the month
 // If the day does not exist, addTaskToDay returns domain_errors.ErrDayNotFound.
 func (m *Month) addTaskToDay(day int, task *Task) error {
-	d, exists := m.days[day]
-	if !exists {
-		return domain_errors.ErrDayNotFound
+	d, err := m.getDay(day)
+	if err != nil {
+		return err
 	}
 
 	return d.addTask(task)
 }
 
+// date returns the date of the day of the month
+func (m *Month) date(day int) time.Time {
+	return time.Date(m.year, m.month, day, 0, 0, 0, 0, time.UTC)
+}
+
 // getDay returns the day for the month
 func (m *Month) getDay(day int) (*Day, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	d, exists := m.days[day]
 	if !exists {
 		return nil, domain_errors.ErrDayNotFound
@@ -93,3 +143,16 @@ func (m *Month) getDay(day int) (*Day, error) {
 
 	return d, nil
 }
+
+// getDays returns a snapshot of the days of the month
+func (m *Month) getDays() []*Day {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	days := make([]*Day, 0, len(m.days))
+	for _, d := range m.days {
+		days = append(days, d)
+	}
+
+	return days
+}
@@ -129,6 +129,7 @@ func TestMonthAddDay(t *testing.T) {
 					15: &Day{
 						day:   15,
 						tasks: []*Task{},
+						date:  time.Date(2022, time.May, 15, 0, 0, 0, 0, time.UTC),
 					},
 				},
 			}, // Still May
@@ -307,3 +308,26 @@ func TestMonthGetDay(t *testing.T) {
 		})
 	}
 }
+
+func TestMonthGetTasks(t *testing.T) {
+	m, err := NewMonth(time.January, 2024)
+	assert.NoError(t, err)
+
+	baseTime := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []*Task{
+		{id: NewTaskID(), time: baseTime},
+		{id: NewTaskID(), time: baseTime.Add(time.Hour)},
+		{id: NewTaskID(), time: baseTime.AddDate(0, 0, 9)},
+		{id: NewTaskID(), time: baseTime.AddDate(0, 0, 20)},
+	}
+
+	for i := len(tasks) - 1; i >= 0; i-- {
+		d, err := m.addDay(tasks[i].GetTime().Day())
+		assert.NoError(t, err)
+		assert.NoError(t, d.addTask(tasks[i]))
+	}
+
+	assert.Equal(t, time.January, m.GetMonth())
+	assert.Equal(t, 2024, m.GetYear())
+	assert.Equal(t, tasks, m.GetTasks())
+}
@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// OccurrenceKey identifies a single occurrence of a recurring series
+//
+// It is composed of the primaryId of the original task and the time the
+// occurrence was generated for, before any override moved it.
+type OccurrenceKey struct {
+	primaryId  uuid.UUID
+	occurrence time.Time
+}
+
+// NewOccurrenceKey creates the key of the occurrence of the series at the given time
+func NewOccurrenceKey(primaryId uuid.UUID, occurrence time.Time) OccurrenceKey {
+	// Normalize so the same instant in any location yields the same key
+	return OccurrenceKey{primaryId: primaryId, occurrence: occurrence.UTC().Round(0)}
+}
+
+// GetPrimaryID returns the primaryId of the series
+func (k OccurrenceKey) GetPrimaryID() uuid.UUID {
+	return k.primaryId
+}
+
+// GetOccurrence returns the original time of the occurrence, in UTC
+func (k OccurrenceKey) GetOccurrence() time.Time {
+	return k.occurrence
+}
+
+// occurrenceKey returns the key of the occurrence of the task's series at the given time
+func (t *Task) occurrenceKey(occurrence time.Time) OccurrenceKey {
+	return NewOccurrenceKey(t.id.primaryId, occurrence)
+}
+
+// GetOccurrenceTime returns the time the task was generated for within its series
+//
+// It differs from GetTime only for an override which moved the occurrence.
+func (t *Task) GetOccurrenceTime() time.Time {
+	if t.occurrenceTime.IsZero() {
+		return t.time
+	}
+
+	return t.occurrenceTime
+}
+
+// ExcludeOccurrence cancels the occurrence of the series at the given time (EXDATE)
+//
+// If the task is not an original task, ExcludeOccurrence returns domain_errors.ErrInvalidTaskID.
+func (t *Task) ExcludeOccurrence(occurrence time.Time) error {
+	if !t.id.original {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	if t.exDates == nil {
+		t.exDates = make(map[OccurrenceKey]time.Time)
+	}
+
+	t.exDates[t.occurrenceKey(occurrence)] = occurrence
+
+	return nil
+}
+
+// AddOccurrence adds an extra occurrence to the series at the given time (RDATE)
+//
+// If the task is not an original task, AddOccurrence returns domain_errors.ErrInvalidTaskID.
+func (t *Task) AddOccurrence(occurrence time.Time) error {
+	if !t.id.original {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	if occurrence.IsZero() {
+		return domain_errors.ErrTimeRequired
+	}
+
+	for _, rDate := range t.rDates {
+		if rDate.Equal(occurrence) {
+			return nil
+		}
+	}
+
+	t.rDates = append(t.rDates, occurrence)
+	sort.Slice(t.rDates, func(i, j int) bool { return t.rDates[i].Before(t.rDates[j]) })
+
+	return nil
+}
+
+// OverrideOccurrence replaces the occurrence of the series at the given time
+// with the override, for this occurrence only
+//
+// The override must be a copy of the series, i.e. share its primaryId, and
+// its time is where the occurrence moves to. The series stores a copy of the
+// override, which GetOverride returns.
+// If the task is not an original task, OverrideOccurrence returns domain_errors.ErrInvalidTaskID.
+// If the override does not belong to the series, it returns domain_errors.ErrInvalidOverride.
+// If the series has no occurrence at that time, it returns domain_errors.ErrOccurrenceNotFound.
+func (t *Task) OverrideOccurrence(occurrence time.Time, override *Task) error {
+	if !t.id.original {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	if override == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	if override.id == nil || override.id.original || override.id.primaryId != t.id.primaryId {
+		return domain_errors.ErrInvalidOverride
+	}
+
+	if err := override.Validate(); err != nil {
+		return errors.Join(domain_errors.ErrInvalidOverride, err)
+	}
+
+	if !t.hasOccurrence(occurrence) {
+		return domain_errors.ErrOccurrenceNotFound
+	}
+
+	if t.overrides == nil {
+		t.overrides = make(map[OccurrenceKey]*Task)
+	}
+
+	// The series keeps its own copy, the caller's task is left untouched
+	stored := override.clone()
+	stored.occurrenceTime = occurrence
+	t.overrides[t.occurrenceKey(occurrence)] = stored
+
+	return nil
+}
+
+// GetExceptionDates returns the excluded occurrences of the series, sorted by time
+func (t *Task) GetExceptionDates() []time.Time {
+	exDates := make([]time.Time, 0, len(t.exDates))
+	for _, exDate := range t.exDates {
+		exDates = append(exDates, exDate)
+	}
+
+	sort.Slice(exDates, func(i, j int) bool { return exDates[i].Before(exDates[j]) })
+
+	return exDates
+}
+
+// GetRecurrenceDates returns the extra occurrences of the series, sorted by time
+func (t *Task) GetRecurrenceDates() []time.Time {
+	return slices.Clone(t.rDates)
+}
+
+// GetOverrides returns the overrides of the series, sorted by occurrence time
+func (t *Task) GetOverrides() []*Task {
+	return t.sortedOverrides()
+}
+
+// GetOverride returns the override of the occurrence of the series at the given time
+func (t *Task) GetOverride(occurrence time.Time) (*Task, bool) {
+	if len(t.overrides) == 0 {
+		return nil, false
+	}
+
+	override, exists := t.overrides[t.occurrenceKey(occurrence)]
+	return override, exists
+}
+
+// removeOverride drops the override of the occurrence at the given time, if any
+func (t *Task) removeOverride(occurrence time.Time) {
+	delete(t.overrides, t.occurrenceKey(occurrence))
+}
+
+// dropExceptionsFrom drops the EXDATEs, RDATEs and overrides at or after the given time
+func (t *Task) dropExceptionsFrom(occurrence time.Time) {
+	for key, exDate := range t.exDates {
+		if !exDate.Before(occurrence) {
+			delete(t.exDates, key)
+		}
+	}
+
+	rDates := t.rDates[:0]
+	for _, rDate := range t.rDates {
+		if rDate.Before(occurrence) {
+			rDates = append(rDates, rDate)
+		}
+	}
+	t.rDates = rDates
+
+	for key, override := range t.overrides {
+		if !override.GetOccurrenceTime().Before(occurrence) {
+			delete(t.overrides, key)
+		}
+	}
+}
+
+// advanceStart moves the task to the first occurrence of its rule after its
+// own time which is not excluded, so the series no longer occurs at that time
+//
+// A COUNT is reduced by the occurrences skipped. If the rule has no further
+// occurrence, advanceStart returns false and leaves the task unchanged.
+func (t *Task) advanceStart() bool {
+	rule := t.GetRecurrenceRule()
+	if rule == nil {
+		return false
+	}
+
+	skipped := 0
+
+	it := rule.iterator(t.time)
+	for {
+		next, ok := it.next()
+		if !ok {
+			return false
+		}
+
+		if !next.After(t.time) || t.isExcluded(next) {
+			skipped++
+			continue
+		}
+
+		if rule.count > 0 {
+			rule = rule.clone()
+			rule.count -= skipped
+			t.recurrence = rule
+		}
+		t.time = next
+
+		return true
+	}
+}
+
+// isExcluded returns true if the occurrence at the given time was cancelled
+func (t *Task) isExcluded(occurrence time.Time) bool {
+	if len(t.exDates) == 0 {
+		return false
+	}
+
+	_, excluded := t.exDates[t.occurrenceKey(occurrence)]
+	return excluded
+}
+
+// isOverride returns true if the task replaces one of the series' occurrences
+func (t *Task) isOverride(task *Task) bool {
+	override, exists := t.GetOverride(task.GetOccurrenceTime())
+	return exists && override == task
+}
+
+// hasOccurrence returns true if the series, exceptions applied, occurs at the given time
+func (t *Task) hasOccurrence(occurrence time.Time) bool {
+	it := t.Occurrences()
+	it.Seek(occurrence)
+
+	current, ok := it.Peek()
+	return ok && current.Equal(occurrence)
+}
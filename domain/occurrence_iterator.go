@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// OccurrenceIterator is a pull based iterator over the original times of the
+// occurrences of a task's series, in order
+//
+// The occurrences are the rule's occurrences, or the task time when it does
+// not repeat, merged with the RDATEs and without the EXDATEs. They are
+// computed lazily, so an unbounded series can be streamed without goroutines.
+type OccurrenceIterator struct {
+	task   *Task
+	rule   *recurrenceIterator
+	single bool
+	rDates []time.Time
+	last   time.Time
+	peeked *time.Time
+}
+
+// Occurrences returns an iterator over the occurrences of the task's series
+func (t *Task) Occurrences() *OccurrenceIterator {
+	it := &OccurrenceIterator{task: t, rDates: t.rDates}
+
+	if rule := t.GetRecurrenceRule(); rule != nil {
+		it.rule = rule.iterator(t.time)
+	} else {
+		it.single = true
+	}
+
+	return it
+}
+
+// Next returns the next occurrence, or false once the series is exhausted
+func (it *OccurrenceIterator) Next() (time.Time, bool) {
+	if it.peeked != nil {
+		occurrence := *it.peeked
+		it.peeked = nil
+		return occurrence, true
+	}
+
+	for {
+		current, ok := it.merge()
+		if !ok {
+			return time.Time{}, false
+		}
+
+		// An RDATE may coincide with a rule occurrence
+		if !it.last.IsZero() && current.Equal(it.last) {
+			continue
+		}
+		it.last = current
+
+		if !it.task.isExcluded(current) {
+			return current, true
+		}
+	}
+}
+
+// Peek returns the next occurrence without consuming it
+func (it *OccurrenceIterator) Peek() (time.Time, bool) {
+	if it.peeked == nil {
+		occurrence, ok := it.Next()
+		if !ok {
+			return time.Time{}, false
+		}
+		it.peeked = &occurrence
+	}
+
+	return *it.peeked, true
+}
+
+// Seek advances the iterator so the next occurrence is the first one at or after the given time
+//
+// Unless the rule is bounded by COUNT, Seek skips whole periods of the rule
+// instead of expanding them, so looking up a distant range is cheap.
+func (it *OccurrenceIterator) Seek(at time.Time) {
+	if it.peeked != nil && !it.peeked.Before(at) {
+		return
+	}
+	it.peeked = nil
+
+	if it.rule != nil {
+		it.rule.seek(at)
+	}
+
+	if it.single && it.task.time.Before(at) {
+		it.single = false
+	}
+
+	i := sort.Search(len(it.rDates), func(i int) bool { return !it.rDates[i].Before(at) })
+	it.rDates = it.rDates[i:]
+
+	for {
+		occurrence, ok := it.Peek()
+		if !ok || !occurrence.Before(at) {
+			return
+		}
+		it.peeked = nil
+	}
+}
+
+// merge returns the earliest of the next rule occurrence and the next RDATE
+func (it *OccurrenceIterator) merge() (time.Time, bool) {
+	var (
+		ruleTime time.Time
+		ruleOk   bool
+	)
+
+	switch {
+	case it.rule != nil:
+		ruleTime, ruleOk = it.rule.peek()
+	case it.single:
+		ruleTime, ruleOk = it.task.time, true
+	}
+
+	if ruleOk && (len(it.rDates) == 0 || !it.rDates[0].Before(ruleTime)) {
+		if it.rule != nil {
+			it.rule.next()
+		} else {
+			it.single = false
+		}
+		return ruleTime, true
+	}
+
+	if len(it.rDates) > 0 {
+		rDate := it.rDates[0]
+		it.rDates = it.rDates[1:]
+		return rDate, true
+	}
+
+	return time.Time{}, false
+}
+
+// occurrencesBetween calls yield with the original time of every occurrence
+// whose effective time, once overrides are applied, falls within [from, to)
+//
+// A zero from or to leaves that side of the window open. Iteration stops
+// when yield returns false.
+func (t *Task) occurrencesBetween(from, to time.Time, yield func(time.Time) bool) {
+	inWindow := func(date time.Time) bool {
+		return !date.Before(from) && (to.IsZero() || date.Before(to))
+	}
+
+	it := t.Occurrences()
+	if !from.IsZero() {
+		it.Seek(from)
+	}
+
+	for {
+		occurrence, ok := it.Next()
+		if !ok || (!to.IsZero() && !occurrence.Before(to)) {
+			break
+		}
+
+		effectiveTime := occurrence
+		if override, exists := t.GetOverride(occurrence); exists {
+			effectiveTime = override.GetTime()
+		}
+
+		if inWindow(effectiveTime) && !yield(occurrence) {
+			return
+		}
+	}
+
+	// Overrides moved into the window from an occurrence outside of it
+	for _, override := range t.sortedOverrides() {
+		occurrence := override.GetOccurrenceTime()
+		if inWindow(occurrence) || !inWindow(override.GetTime()) || !t.hasOccurrence(occurrence) {
+			continue
+		}
+
+		if !yield(occurrence) {
+			return
+		}
+	}
+}
+
+// occurrenceTask returns the task of the occurrence at the given time, its
+// override or else a new copy with an ID created by the factory
+//
+// An OccurrenceTaskIDFactory is given the occurrence time to create the copy.
+// If the copy cannot be created, occurrenceTask returns a
+// *domain_errors.OccurrenceError for the given time.
+func (t *Task) occurrenceTask(date time.Time, taskIDFactory TaskIDFactory) (*Task, error) {
+	if override, exists := t.GetOverride(date); exists {
+		return override, nil
+	}
+
+	var taskID *TaskID
+	var err error
+	if f, ok := taskIDFactory.(OccurrenceTaskIDFactory); ok {
+		taskID, err = f.CreateOccurrenceTaskID(t.id.primaryId, date)
+	} else {
+		taskID, err = taskIDFactory.CreateTaskID(t.id.primaryId.String())
+	}
+	if err != nil {
+		return nil, &domain_errors.OccurrenceError{Occurrence: date, Err: err}
+	}
+
+	task, err := t.occurrence(taskID, date)
+	if err != nil {
+		return nil, &domain_errors.OccurrenceError{Occurrence: date, Err: err}
+	}
+
+	return task, nil
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOccurrenceIterator_NextPeek(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	series := newTestTask(t, start, true, 48*time.Hour)
+	assert.NoError(t, series.ExcludeOccurrence(day(3)))
+	assert.NoError(t, series.AddOccurrence(day(4)))
+	assert.NoError(t, series.AddOccurrence(day(5)))
+
+	it := series.Occurrences()
+
+	peeked, ok := it.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, day(1), peeked)
+
+	var got []time.Time
+	for len(got) < 5 {
+		occurrence, ok := it.Next()
+		assert.True(t, ok)
+		got = append(got, occurrence)
+	}
+
+	// The RDATE on the 5th coincides with a rule occurrence
+	assert.Equal(t, []time.Time{day(1), day(4), day(5), day(7), day(9)}, got)
+}
+
+func TestOccurrenceIterator_Seek(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule     string
+		seek     time.Time
+		expected []time.Time
+	}{
+		{
+			name: "Daily far in the future",
+			rule: "FREQ=DAILY",
+			seek: time.Date(3024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(3024, time.March, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(3024, time.March, 2, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Seek to an occurrence",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			seek: time.Date(2024, time.February, 12, 9, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, time.February, 12, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, time.February, 14, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Monthly skipping short months",
+			rule: "FREQ=MONTHLY",
+			seek: time.Date(2030, time.February, 1, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2030, time.March, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2030, time.May, 31, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Yearly",
+			rule: "FREQ=YEARLY;BYMONTH=1;BYDAY=-1WE",
+			seek: time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2100, time.January, 27, 9, 0, 0, 0, time.UTC),
+				time.Date(2101, time.January, 26, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Hourly",
+			rule: "FREQ=HOURLY;INTERVAL=5",
+			seek: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.February, 1, 5, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Count is honoured",
+			rule: "FREQ=DAILY;COUNT=3",
+			seek: time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "Past the end",
+			rule:     "FREQ=DAILY;UNTIL=20240205T000000Z",
+			seek:     time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			assert.NoError(t, err)
+
+			task, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+			assert.NoError(t, err)
+
+			it := task.Occurrences()
+			it.Seek(tt.seek)
+
+			var got []time.Time
+			for len(got) < 2 {
+				occurrence, ok := it.Next()
+				if !ok {
+					break
+				}
+				got = append(got, occurrence)
+			}
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestOccurrenceIterator_nonRepeating(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	task := newTestTask(t, start, false, 0)
+
+	it := task.Occurrences()
+	occurrence, ok := it.Next()
+	assert.True(t, ok)
+	assert.Equal(t, start, occurrence)
+
+	_, ok = it.Next()
+	assert.False(t, ok)
+
+	it = task.Occurrences()
+	it.Seek(start.Add(time.Second))
+	_, ok = it.Peek()
+	assert.False(t, ok)
+}
+
+func BenchmarkOccurrenceIterator_Seek(b *testing.B) {
+	rule, _ := ParseRecurrenceRule("FREQ=DAILY")
+	task, _ := NewRecurringTask(NewTaskID(), "title", "description", rule,
+		time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC))
+	target := time.Date(2124, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < b.N; i++ {
+		it := task.Occurrences()
+		it.Seek(target)
+		it.Next()
+	}
+}
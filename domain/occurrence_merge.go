@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// MergeOccurrences returns the tasks of the series' occurrences within [from, to), sorted by time
+//
+// Only completed occurrences need to be stored, every other one is expanded
+// from the series. A stored occurrence, matched by primaryId and occurrence
+// time, replaces the expanded one unless only the latter is completed, so no
+// occurrence is ever returned twice. Overrides of the series always win.
+// A zero from and to expand the series like AddTask does.
+// Stored occurrences the series no longer has are left out, and each is
+// reported as a *domain_errors.OccurrenceError joined with
+// domain_errors.ErrOccurrenceNotFound, returned along with the merged tasks.
+// If a stored task is not a copy of the series, MergeOccurrences returns domain_errors.ErrInvalidTaskID.
+// If to is not after from, MergeOccurrences returns domain_errors.ErrInvalidRange.
+func MergeOccurrences(series *Task, stored []*Task, from, to time.Time) ([]*Task, error) {
+	return MergeOccurrencesFrom(defaultIDSource, series, stored, from, to)
+}
+
+// MergeOccurrencesFrom is MergeOccurrences with the IDs of the expanded
+// occurrences taken from the source
+func MergeOccurrencesFrom(source IDSource, series *Task, stored []*Task, from, to time.Time) ([]*Task, error) {
+	if series == nil {
+		return nil, domain_errors.ErrTaskCannotBeNil
+	}
+
+	if from.IsZero() && to.IsZero() {
+		from, to = series.defaultWindow()
+	} else if !to.After(from) {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	index, err := indexStoredOccurrences(series, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped error
+	for _, task := range stored {
+		key := series.occurrenceKey(task.GetOccurrenceTime())
+		if _, exists := index[key]; !exists || series.hasOccurrence(task.GetOccurrenceTime()) {
+			continue
+		}
+
+		delete(index, key)
+		dropped = errors.Join(dropped, &domain_errors.OccurrenceError{
+			Occurrence: task.GetOccurrenceTime(),
+			Err:        domain_errors.ErrOccurrenceNotFound,
+		})
+	}
+
+	original, tasks, err := series.mergeOccurrences(context.Background(), from, to, index, NewCopyTaskIDFactory(source))
+	if err != nil {
+		return nil, err
+	}
+
+	if original != nil {
+		tasks = append(tasks, original)
+	}
+
+	slices.SortFunc(tasks, CompareTasks)
+
+	return tasks, dropped
+}
+
+// mergeOccurrences returns the tasks of the series' occurrences within [from, to)
+//
+// The occurrences are searched with searchRepetitionBetween and their tasks
+// created with createTasksFromDates. The original task is returned apart, or
+// nil when it does not occupy its own time within the window. A stored
+// occurrence replaces the expanded one unless only the latter is completed,
+// and overrides always win.
+// Occurrence failures don't stop the expansion, every one of them is
+// reported; a done context stops it.
+func (t *Task) mergeOccurrences(ctx context.Context, from, to time.Time, stored map[OccurrenceKey]*Task, taskIDFactory TaskIDFactory) (original *Task, copies []*Task, errc error) {
+	if t.placesOriginalWithin(from, to) {
+		original = t
+	}
+
+	dates := t.searchRepetitionBetween(ctx, from, to)
+	tasks, errs := t.createTasksFromDates(ctx, dates, taskIDFactory)
+
+	for tasks != nil || errs != nil {
+		select {
+		case task, ok := <-tasks:
+			if !ok {
+				tasks = nil
+				continue
+			}
+
+			// The original task already occupies its own time
+			if task.GetTime().Equal(t.GetTime()) && !t.isOverride(task) {
+				continue
+			}
+
+			if storedTask, exists := stored[t.occurrenceKey(task.GetOccurrenceTime())]; exists && !t.isOverride(task) {
+				task = preferredOccurrence(task, storedTask)
+			}
+
+			copies = append(copies, task)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+
+			errc = errors.Join(errc, err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errc = errors.Join(errc, err)
+	}
+
+	return original, copies, errc
+}
+
+// placesOriginalWithin returns true if the original task occupies its own
+// time within [from, to), neither overridden nor excluded
+//
+// A zero from or to leaves that side of the window open.
+func (t *Task) placesOriginalWithin(from, to time.Time) bool {
+	if t.GetTime().Before(from) || (!to.IsZero() && !t.GetTime().Before(to)) {
+		return false
+	}
+
+	_, overridden := t.GetOverride(t.GetTime())
+
+	return !overridden && !t.isExcluded(t.GetTime())
+}
+
+// indexStoredOccurrences indexes the stored occurrences of the series by OccurrenceKey
+//
+// When an occurrence is stored twice, the preferred one is kept.
+func indexStoredOccurrences(series *Task, stored []*Task) (map[OccurrenceKey]*Task, error) {
+	index := make(map[OccurrenceKey]*Task, len(stored))
+	for _, task := range stored {
+		if err := series.validateStoredOccurrence(task); err != nil {
+			return nil, err
+		}
+
+		key := series.occurrenceKey(task.GetOccurrenceTime())
+		if existing, exists := index[key]; exists {
+			task = preferredOccurrence(existing, task)
+		}
+		index[key] = task
+	}
+
+	return index, nil
+}
+
+// validateStoredOccurrence checks the task is a copy of the series
+func (t *Task) validateStoredOccurrence(task *Task) error {
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	if task.id == nil || task.id.original || task.id.primaryId != t.id.primaryId {
+		return domain_errors.ErrInvalidTaskID
+	}
+
+	return nil
+}
+
+// preferredOccurrence returns which of two tasks of the same occurrence to keep
+//
+// The stored task wins unless only the current one is completed.
+func preferredOccurrence(current, stored *Task) *Task {
+	if current.IsCompleted() && !stored.IsCompleted() {
+		return current
+	}
+
+	return stored
+}
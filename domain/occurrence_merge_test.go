@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStoredOccurrence creates a stored copy of the series' occurrence at the given time
+func newStoredOccurrence(t *testing.T, series *Task, occurrence time.Time, completed bool) *Task {
+	t.Helper()
+
+	task, err := series.occurrence(newTestCopyID(t, series.id), occurrence)
+	assert.NoError(t, err)
+	task.completed = completed
+
+	return task
+}
+
+func TestMergeOccurrences(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=5")
+	assert.NoError(t, err)
+
+	newSeries := func(t *testing.T) *Task {
+		series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+		assert.NoError(t, err)
+		return series
+	}
+
+	tests := []struct {
+		name string
+		// stored returns the stored occurrences and the ones expected in the result
+		stored   func(t *testing.T, series *Task) (stored []*Task, want map[time.Time]*Task)
+		from, to time.Time
+		wantLen  int
+		wantErr  error
+	}{
+		{
+			name: "Nothing stored",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				return nil, map[time.Time]*Task{day(1): series}
+			},
+			wantLen: 5,
+		},
+		{
+			name: "Completed occurrence replaces the expanded one",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				completed := newStoredOccurrence(t, series, day(3), true)
+				return []*Task{completed}, map[time.Time]*Task{day(3): completed}
+			},
+			wantLen: 5,
+		},
+		{
+			name: "Completed duplicate wins",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				completed := newStoredOccurrence(t, series, day(3), true)
+				pending := newStoredOccurrence(t, series, day(3), false)
+				return []*Task{completed, pending}, map[time.Time]*Task{day(3): completed}
+			},
+			wantLen: 5,
+		},
+		{
+			name: "Occurrence the series no longer has is reported",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				completed := newStoredOccurrence(t, series, day(3), true)
+				return []*Task{newStoredOccurrence(t, series, day(9), true), completed}, map[time.Time]*Task{day(3): completed}
+			},
+			wantLen: 5,
+			wantErr: domain_errors.ErrOccurrenceNotFound,
+		},
+		{
+			name: "Within a window",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				completed := newStoredOccurrence(t, series, day(2), true)
+				return []*Task{completed, newStoredOccurrence(t, series, day(4), true)}, map[time.Time]*Task{day(2): completed}
+			},
+			from:    day(2),
+			to:      day(4),
+			wantLen: 2,
+		},
+		{
+			name: "Task of another series",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				return []*Task{newStoredOccurrence(t, newSeries(t), day(2), true)}, nil
+			},
+			wantErr: domain_errors.ErrInvalidTaskID,
+		},
+		{
+			name: "Invalid range",
+			stored: func(t *testing.T, series *Task) ([]*Task, map[time.Time]*Task) {
+				return nil, nil
+			},
+			from:    day(2),
+			to:      day(2),
+			wantErr: domain_errors.ErrInvalidRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := newSeries(t)
+			stored, want := tt.stored(t, series)
+
+			tasks, err := MergeOccurrences(series, stored, tt.from, tt.to)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if errors.Is(err, domain_errors.ErrOccurrenceNotFound) {
+				var occurrenceErr *domain_errors.OccurrenceError
+				assert.ErrorAs(t, err, &occurrenceErr)
+				assert.Equal(t, day(9), occurrenceErr.Occurrence)
+			} else if tt.wantErr != nil {
+				assert.Nil(t, tasks)
+				return
+			}
+
+			assert.Len(t, tasks, tt.wantLen)
+
+			seen := make(map[time.Time]bool)
+			for i, task := range tasks {
+				occurrence := task.GetOccurrenceTime()
+				assert.False(t, seen[occurrence], "duplicated occurrence %s", occurrence)
+				seen[occurrence] = true
+
+				if i > 0 {
+					assert.False(t, task.GetTime().Before(tasks[i-1].GetTime()))
+				}
+
+				if wanted, exists := want[occurrence]; exists {
+					assert.Same(t, wanted, task)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendar_RestoreOccurrences(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	c := NewCalendar()
+	series := newTestTask(t, start, true, 24*time.Hour)
+	assert.NoError(t, c.AddTaskBetween(ctx, series, start, start.AddDate(0, 0, 7)))
+
+	completed := newStoredOccurrence(t, series, start.AddDate(0, 0, 2), true)
+	outside := newStoredOccurrence(t, series, start.AddDate(0, 0, 10), true)
+	other := newTestTask(t, start, false, 0)
+
+	err := c.RestoreOccurrences(completed, outside, other)
+	assert.ErrorIs(t, err, domain_errors.ErrTaskNotFound)
+	assert.NotErrorIs(t, err, domain_errors.ErrOccurrenceNotFound)
+
+	tasks, err := c.GetTasksOn(start.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Task{completed}, tasks)
+	assert.Equal(t, []*Task{completed}, c.GetCompletedOccurrences())
+
+	// A pending stored occurrence doesn't replace a completed one
+	pending := newStoredOccurrence(t, series, start.AddDate(0, 0, 2), false)
+	assert.NoError(t, c.RestoreOccurrences(pending))
+
+	tasks, err = c.GetTasksOn(start.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Task{completed}, tasks)
+
+	assert.ErrorIs(t, c.RestoreOccurrences(newStoredOccurrence(t, series, start.Add(time.Minute), true)), domain_errors.ErrOccurrenceNotFound)
+}
+
+func TestCalendar_RestoreOccurrences_edits(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	c := NewCalendar()
+	series := newTestTask(t, start, true, 24*time.Hour)
+	assert.NoError(t, c.AddTask(ctx, series))
+
+	completed := newStoredOccurrence(t, series, start.AddDate(0, 0, 5), true)
+	assert.NoError(t, c.RestoreOccurrences(completed))
+
+	// Moving the whole series discards the occurrences stored for its old times
+	edited := newEditedTask(t, "edited", start.Add(time.Hour))
+	assert.NoError(t, c.UpdateTask(ctx, series, edited, AllOccurrences))
+
+	assert.Empty(t, c.GetCompletedOccurrences())
+	tasks, err := c.GetTasksOn(start.AddDate(0, 0, 5))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.False(t, tasks[0].IsCompleted())
+}
+
+func TestMergeOccurrencesFrom(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=5")
+	assert.NoError(t, err)
+
+	series, err := NewRecurringTask(NewTaskID(), "title", "description", rule, start)
+	assert.NoError(t, err)
+
+	first, err := MergeOccurrencesFrom(NewDeterministicIDSource(nil), series, nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	second, err := MergeOccurrencesFrom(NewDeterministicIDSource(nil), series, nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 5)
+	for i := range first {
+		assert.Equal(t, first[i].GetID(), second[i].GetID())
+	}
+}
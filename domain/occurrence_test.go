@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestOverride creates a copy of the series' task moved to the given time
+func newTestOverride(t *testing.T, series *Task, title string, at time.Time) *Task {
+	t.Helper()
+
+	override, err := NewTask(
+		newTestCopyID(t, series.id),
+		title, "description",
+		false, 0,
+		at)
+	assert.NoError(t, err)
+
+	return override
+}
+
+func TestNewOccurrenceKey(t *testing.T) {
+	id := uuid.New()
+	at := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	madrid := time.FixedZone("CET", 3600)
+
+	assert.Equal(t, NewOccurrenceKey(id, at), NewOccurrenceKey(id, at.In(madrid)))
+	assert.NotEqual(t, NewOccurrenceKey(id, at), NewOccurrenceKey(uuid.New(), at))
+	assert.NotEqual(t, NewOccurrenceKey(id, at), NewOccurrenceKey(id, at.Add(time.Second)))
+	assert.Equal(t, id, NewOccurrenceKey(id, at).GetPrimaryID())
+	assert.Equal(t, at, NewOccurrenceKey(id, at.In(madrid)).GetOccurrence())
+}
+
+func TestTask_OverrideOccurrence(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	series := newTestTask(t, start, true, 24*time.Hour)
+	other := newTestTask(t, start, true, 24*time.Hour)
+
+	tests := []struct {
+		name       string
+		series     *Task
+		occurrence time.Time
+		override   *Task
+		wantErr    error
+	}{
+		{
+			name:       "Move an occurrence",
+			series:     series,
+			occurrence: start.AddDate(0, 0, 2),
+			override:   newTestOverride(t, series, "moved", start.AddDate(0, 0, 2).Add(3*time.Hour)),
+		},
+		{
+			name:       "Nil override",
+			series:     series,
+			occurrence: start,
+			wantErr:    domain_errors.ErrTaskCannotBeNil,
+		},
+		{
+			name:       "Override from another series",
+			series:     series,
+			occurrence: start,
+			override:   newTestOverride(t, other, "moved", start),
+			wantErr:    domain_errors.ErrInvalidOverride,
+		},
+		{
+			name:       "Original task as override",
+			series:     series,
+			occurrence: start,
+			override:   other,
+			wantErr:    domain_errors.ErrInvalidOverride,
+		},
+		{
+			name:       "Not an occurrence",
+			series:     series,
+			occurrence: start.Add(time.Hour),
+			override:   newTestOverride(t, series, "moved", start),
+			wantErr:    domain_errors.ErrOccurrenceNotFound,
+		},
+		{
+			name:       "Series is a copy",
+			series:     newTestOverride(t, series, "copy", start),
+			occurrence: start,
+			override:   newTestOverride(t, series, "moved", start),
+			wantErr:    domain_errors.ErrInvalidTaskID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var occurrenceTime time.Time
+			if tt.override != nil {
+				occurrenceTime = tt.override.GetOccurrenceTime()
+			}
+
+			err := tt.series.OverrideOccurrence(tt.occurrence, tt.override)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			override, exists := tt.series.GetOverride(tt.occurrence)
+			assert.Equal(t, tt.wantErr == nil, exists)
+			if exists {
+				// The series stores a copy, the override passed in is left untouched
+				assert.NotSame(t, tt.override, override)
+				assert.Equal(t, tt.override.GetID(), override.GetID())
+				assert.Equal(t, tt.occurrence, override.GetOccurrenceTime())
+				assert.Equal(t, occurrenceTime, tt.override.GetOccurrenceTime())
+			}
+		})
+	}
+}
+
+func TestTask_exceptions(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	series := newTestTask(t, start, true, 24*time.Hour)
+	series.recurrence = &RecurrenceRule{freq: Daily, interval: 1, count: 5, wkst: time.Monday}
+
+	assert.NoError(t, series.ExcludeOccurrence(day(2)))
+	assert.NoError(t, series.AddOccurrence(day(10)))
+	assert.NoError(t, series.AddOccurrence(day(3)))
+	// An RDATE on an excluded occurrence stays excluded
+	assert.NoError(t, series.AddOccurrence(day(2)))
+	assert.NoError(t, series.OverrideOccurrence(day(4), newTestOverride(t, series, "moved", day(20))))
+	assert.NoError(t, series.OverrideOccurrence(day(5), newTestOverride(t, series, "renamed", day(5))))
+
+	assert.Equal(t, []time.Time{day(2)}, series.GetExceptionDates())
+	assert.Equal(t, []time.Time{day(2), day(3), day(10)}, series.GetRecurrenceDates())
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected []time.Time
+	}{
+		{
+			name:     "Whole series",
+			expected: []time.Time{day(1), day(3), day(4), day(5), day(10)},
+		},
+		{
+			name:     "Occurrence moved out of the window",
+			from:     day(1),
+			to:       day(10),
+			expected: []time.Time{day(1), day(3), day(5)},
+		},
+		{
+			name:     "Occurrence moved into the window",
+			from:     day(15),
+			to:       day(25),
+			expected: []time.Time{day(4)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, occurrencesOf(series, tt.from, tt.to))
+		})
+	}
+}
+
+func TestCalendar_AddTask_exceptions(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.AddDate(0, 0, n-1) }
+
+	series := newTestTask(t, start, true, 24*time.Hour)
+	moved := newTestOverride(t, series, "moved", day(3).Add(2*time.Hour))
+
+	assert.NoError(t, series.ExcludeOccurrence(day(1)))
+	assert.NoError(t, series.ExcludeOccurrence(day(2)))
+	assert.NoError(t, series.OverrideOccurrence(day(3), moved))
+
+	c := NewCalendar()
+	assert.NoError(t, c.AddTask(context.Background(), series))
+
+	m, err := c.getMonth(time.January, 2024)
+	assert.NoError(t, err)
+	assert.Len(t, m.days, 29)
+
+	_, err = m.getDay(1)
+	assert.ErrorIs(t, err, domain_errors.ErrDayNotFound)
+
+	third, err := m.getDay(3)
+	assert.NoError(t, err)
+	stored, _ := series.GetOverride(day(3))
+	assert.Equal(t, []*Task{stored}, third.getTasks())
+}
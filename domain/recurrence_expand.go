@@ -0,0 +1,532 @@
+package domain
+
+import (
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// maxEmptyYears bounds how long a run of periods without an occurrence the
+// expansion inspects before deciding that the rule yields nothing more, e.g.
+// "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30". The Gregorian calendar repeats
+// itself every 400 years.
+const maxEmptyYears = 400
+
+// civilDate is a calendar date without time of day or location
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// newCivilDate normalizes the date, so day 32 of January is the 1st of February
+func newCivilDate(year int, month time.Month, day int) civilDate {
+	y, m, d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date()
+	return civilDate{year: y, month: m, day: d}
+}
+
+// valid reports whether the date exists as given, e.g. February 30th does not
+func (d civilDate) valid() bool {
+	return newCivilDate(d.year, d.month, d.day) == d
+}
+
+func (d civilDate) weekday() time.Weekday {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+// daysIn returns the number of days of the month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// recurrenceIterator expands a recurrence rule anchored at dtstart period by period
+type recurrenceIterator struct {
+	rule    *RecurrenceRule
+	dtstart time.Time
+	period  int
+	buffer  []time.Time
+	emitted int
+	done    bool
+}
+
+// iterator returns an iterator over the occurrences of the rule starting at dtstart
+func (r *RecurrenceRule) iterator(dtstart time.Time) *recurrenceIterator {
+	return &recurrenceIterator{rule: r, dtstart: dtstart}
+}
+
+// next returns the next occurrence, or false once the rule is exhausted
+func (it *recurrenceIterator) next() (time.Time, bool) {
+	var emptySince time.Time
+
+	for len(it.buffer) == 0 {
+		if it.done {
+			return time.Time{}, false
+		}
+
+		start, candidates := it.rule.expandPeriod(it.dtstart, it.period)
+		it.period++
+
+		if until := it.rule.until; !until.IsZero() && start.After(until) {
+			it.done = true
+			return time.Time{}, false
+		}
+
+		for _, c := range candidates {
+			if !c.Before(it.dtstart) {
+				it.buffer = append(it.buffer, c)
+			}
+		}
+
+		if len(it.buffer) > 0 {
+			break
+		}
+
+		if emptySince.IsZero() {
+			emptySince = start
+		}
+
+		limit := emptySince.AddDate(maxEmptyYears, 0, 0)
+		if start.After(limit) {
+			it.done = true
+			return time.Time{}, false
+		}
+
+		// Periods shorter than a day are skipped to the next date the rule
+		// matches, a minutely rule could otherwise spend months of periods
+		if len(candidates) == 0 && it.rule.isSubDaily() {
+			period, ok := it.rule.nextDatePeriod(it.dtstart, start, limit)
+			if !ok {
+				it.done = true
+				return time.Time{}, false
+			}
+			it.period = max(it.period, period)
+		}
+	}
+
+	occurrence := it.buffer[0]
+	it.buffer = it.buffer[1:]
+
+	if until := it.rule.until; !until.IsZero() && occurrence.After(until) {
+		it.done = true
+		return time.Time{}, false
+	}
+
+	it.emitted++
+	if it.rule.count > 0 && it.emitted > it.rule.count {
+		it.done = true
+		return time.Time{}, false
+	}
+
+	return occurrence, true
+}
+
+// peek returns the next occurrence without consuming it
+func (it *recurrenceIterator) peek() (time.Time, bool) {
+	occurrence, ok := it.next()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	it.unread(occurrence)
+
+	return occurrence, true
+}
+
+// unread pushes the occurrence back so the next call to next returns it again
+func (it *recurrenceIterator) unread(occurrence time.Time) {
+	it.buffer = append([]time.Time{occurrence}, it.buffer...)
+	it.emitted--
+}
+
+// seek advances the iterator so next returns the first occurrence at or after the given time
+//
+// Rules bounded by COUNT are expanded up to that time, as every occurrence
+// must be counted; any other rule jumps straight to the period before it.
+func (it *recurrenceIterator) seek(at time.Time) {
+	if it.rule.count == 0 {
+		if k := it.rule.periodsBetween(it.dtstart, at) - 1; k > it.period {
+			it.period = k
+			it.buffer = nil
+		}
+	}
+
+	for {
+		occurrence, ok := it.next()
+		if !ok {
+			return
+		}
+
+		if !occurrence.Before(at) {
+			it.unread(occurrence)
+			return
+		}
+	}
+}
+
+// periodsBetween returns how many whole periods of the rule fit between dtstart and the given time
+func (r *RecurrenceRule) periodsBetween(dtstart, at time.Time) int {
+	if !at.After(dtstart) {
+		return 0
+	}
+
+	year, month, day := dtstart.Date()
+	atYear, atMonth, atDay := at.In(dtstart.Location()).Date()
+
+	days := func(fromDay int) int {
+		start := time.Date(year, month, fromDay, 0, 0, 0, 0, time.UTC)
+		end := time.Date(atYear, atMonth, atDay, 0, 0, 0, 0, time.UTC)
+		return int(end.Sub(start) / (24 * time.Hour))
+	}
+
+	var periods int
+
+	switch r.freq {
+	case Yearly:
+		periods = atYear - year
+	case Monthly:
+		periods = (atYear-year)*12 + int(atMonth-month)
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.wkst) + 7) % 7
+		periods = days(day-offset) / 7
+	case Daily:
+		periods = days(day)
+	default:
+		periods = int((at.Unix() - dtstart.Unix()) / r.unitSeconds())
+	}
+
+	return periods / r.interval
+}
+
+// isSubDaily reports whether the periods of the rule are shorter than a day
+func (r *RecurrenceRule) isSubDaily() bool {
+	return r.freq == Hourly || r.freq == Minutely || r.freq == Secondly
+}
+
+// nextDatePeriod returns the first period of a sub-daily rule on the first
+// date after the given period start which BYMONTH, BYMONTHDAY and BYDAY match
+//
+// If no such date starts before limit, nextDatePeriod returns false.
+func (r *RecurrenceRule) nextDatePeriod(dtstart, start, limit time.Time) (int, bool) {
+	loc := dtstart.Location()
+	year, month, day := start.In(loc).Date()
+	d := newCivilDate(year, month, day+1)
+
+	for {
+		midnight, _ := LocalTime(d.year, d.month, d.day, 0, 0, 0, 0, loc, ShiftLocalTime)
+		if !midnight.Before(limit) {
+			return 0, false
+		}
+
+		switch {
+		case !r.matchesMonth(d.month):
+			d = newCivilDate(d.year, d.month+1, 1)
+		case !r.matchesDate(d):
+			d = newCivilDate(d.year, d.month, d.day+1)
+		default:
+			// The first period starting at or after midnight
+			step := r.unitSeconds() * int64(r.interval)
+			elapsed := midnight.Unix() - dtstart.Unix()
+			return int((elapsed + step - 1) / step), true
+		}
+	}
+}
+
+// IsBounded reports whether the rule ends, either through COUNT or UNTIL
+func (r *RecurrenceRule) IsBounded() bool {
+	return r.count > 0 || !r.until.IsZero()
+}
+
+// Between returns the occurrences of the rule anchored at dtstart within [from, to)
+//
+// The window may span any number of months and years.
+// If to is not after from, Between returns domain_errors.ErrInvalidRange.
+func (r *RecurrenceRule) Between(dtstart, from, to time.Time) ([]time.Time, error) {
+	if !to.After(from) {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	var occurrences []time.Time
+
+	it := r.iterator(dtstart)
+	for {
+		occurrence, ok := it.next()
+		if !ok || !occurrence.Before(to) {
+			return occurrences, nil
+		}
+
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+}
+
+// All returns every occurrence of the rule anchored at dtstart
+//
+// If the rule has neither COUNT nor UNTIL, All returns domain_errors.ErrUnboundedRecurrence.
+func (r *RecurrenceRule) All(dtstart time.Time) ([]time.Time, error) {
+	if !r.IsBounded() {
+		return nil, domain_errors.ErrUnboundedRecurrence
+	}
+
+	var occurrences []time.Time
+
+	it := r.iterator(dtstart)
+	for {
+		occurrence, ok := it.next()
+		if !ok {
+			return occurrences, nil
+		}
+
+		occurrences = append(occurrences, occurrence)
+	}
+}
+
+// countBefore returns how many occurrences of the rule anchored at dtstart precede the given time
+func (r *RecurrenceRule) countBefore(dtstart, occurrence time.Time) int {
+	n := 0
+
+	it := r.iterator(dtstart)
+	for {
+		current, ok := it.next()
+		if !ok || !current.Before(occurrence) {
+			return n
+		}
+
+		n++
+	}
+}
+
+// expandPeriod returns the start of the kth period after dtstart and its
+// occurrences, sorted and with BYSETPOS applied
+func (r *RecurrenceRule) expandPeriod(dtstart time.Time, k int) (time.Time, []time.Time) {
+	year, month, day := dtstart.Date()
+	hour, min, sec := dtstart.Clock()
+	loc := dtstart.Location()
+	step := k * r.interval
+
+	// Occurrences keep the wall clock time of dtstart across daylight saving
+	// transitions, a time skipped by one is moved forward by the gap
+	at := func(d civilDate) time.Time {
+		t, _ := resolveLocalTime(time.Date(d.year, d.month, d.day, hour, min, sec, dtstart.Nanosecond(), time.UTC), loc)
+		return t
+	}
+	midnight := func(d civilDate) time.Time {
+		t, _ := resolveLocalTime(time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC), loc)
+		return t
+	}
+
+	var (
+		start time.Time
+		dates []civilDate
+	)
+
+	switch r.freq {
+	case Yearly:
+		first := civilDate{year: year + step, month: time.January, day: 1}
+		start = midnight(first)
+		dates = r.yearDates(first.year, month, day)
+	case Monthly:
+		first := newCivilDate(year, month+time.Month(step), 1)
+		start = midnight(first)
+		if r.matchesMonth(first.month) {
+			dates = r.monthDates(first.year, first.month, day)
+		}
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.wkst) + 7) % 7
+		first := newCivilDate(year, month, day-offset+7*step)
+		start = midnight(first)
+		for i := 0; i < 7; i++ {
+			d := newCivilDate(first.year, first.month, first.day+i)
+			if r.matchesWeekday(d.weekday(), dtstart.Weekday()) && r.matchesMonth(d.month) {
+				dates = append(dates, d)
+			}
+		}
+	case Daily:
+		d := newCivilDate(year, month, day+step)
+		start = midnight(d)
+		if r.matchesDate(d) {
+			dates = append(dates, d)
+		}
+	default:
+		// Seconds don't overflow where a time.Duration of centuries would
+		start = time.Unix(dtstart.Unix()+int64(step)*r.unitSeconds(), int64(dtstart.Nanosecond())).In(loc)
+		y, m, d := start.Date()
+		if r.matchesDate(civilDate{year: y, month: m, day: d}) {
+			return start, []time.Time{start}
+		}
+		return start, nil
+	}
+
+	dates = r.applySetPos(dates)
+
+	times := make([]time.Time, len(dates))
+	for i, d := range dates {
+		times[i] = at(d)
+	}
+
+	return start, sortedUnique(times)
+}
+
+// unitSeconds returns the seconds of a single step for the sub-daily frequencies
+func (r *RecurrenceRule) unitSeconds() int64 {
+	switch r.freq {
+	case Hourly:
+		return 3600
+	case Minutely:
+		return 60
+	default:
+		return 1
+	}
+}
+
+// yearDates returns the candidate dates of a yearly period
+func (r *RecurrenceRule) yearDates(year int, month time.Month, day int) []civilDate {
+	if len(r.byMonth) == 0 && len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		d := civilDate{year: year, month: month, day: day}
+		if d.valid() {
+			return []civilDate{d}
+		}
+		return nil
+	}
+
+	var dates []civilDate
+
+	// BYDAY without BYMONTH is relative to the whole year
+	if len(r.byDay) > 0 && len(r.byMonth) == 0 {
+		total := time.Date(year+1, time.January, 0, 0, 0, 0, 0, time.UTC).YearDay()
+		for i := 1; i <= total; i++ {
+			d := newCivilDate(year, time.January, i)
+			if r.matchesMonthDay(d) && r.matchesOrdinalWeekday(d.weekday(), i, total) {
+				dates = append(dates, d)
+			}
+		}
+		return dates
+	}
+
+	for m := time.January; m <= time.December; m++ {
+		if !r.matchesMonth(m) {
+			continue
+		}
+		dates = append(dates, r.monthDates(year, m, day)...)
+	}
+
+	return dates
+}
+
+// monthDates returns the candidate dates of the month
+func (r *RecurrenceRule) monthDates(year int, month time.Month, day int) []civilDate {
+	if len(r.byMonthDay) == 0 && len(r.byDay) == 0 {
+		d := civilDate{year: year, month: month, day: day}
+		if d.valid() {
+			return []civilDate{d}
+		}
+		return nil
+	}
+
+	var dates []civilDate
+
+	total := daysIn(year, month)
+	for i := 1; i <= total; i++ {
+		d := civilDate{year: year, month: month, day: i}
+		if r.matchesMonthDay(d) && r.matchesOrdinalWeekday(d.weekday(), i, total) {
+			dates = append(dates, d)
+		}
+	}
+
+	return dates
+}
+
+// matchesDate applies BYMONTH, BYMONTHDAY and BYDAY as filters
+func (r *RecurrenceRule) matchesDate(d civilDate) bool {
+	return r.matchesMonth(d.month) &&
+		r.matchesMonthDay(d) &&
+		(len(r.byDay) == 0 || r.matchesWeekday(d.weekday(), d.weekday()))
+}
+
+func (r *RecurrenceRule) matchesMonth(month time.Month) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+
+	for _, m := range r.byMonth {
+		if m == month {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *RecurrenceRule) matchesMonthDay(d civilDate) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+
+	total := daysIn(d.year, d.month)
+	for _, md := range r.byMonthDay {
+		if md == d.day || total+md+1 == d.day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesWeekday reports whether weekday is in BYDAY, or equals fallback when BYDAY is empty
+func (r *RecurrenceRule) matchesWeekday(weekday, fallback time.Weekday) bool {
+	if len(r.byDay) == 0 {
+		return weekday == fallback
+	}
+
+	for _, wd := range r.byDay {
+		if wd.Weekday == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesOrdinalWeekday reports whether the index-th day of a span of total
+// days matches BYDAY, honouring the ordinals
+func (r *RecurrenceRule) matchesOrdinalWeekday(weekday time.Weekday, index, total int) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+
+	fromStart := (index-1)/7 + 1
+	fromEnd := -((total-index)/7 + 1)
+
+	for _, wd := range r.byDay {
+		if wd.Weekday != weekday {
+			continue
+		}
+
+		if wd.Ordinal == 0 || wd.Ordinal == fromStart || wd.Ordinal == fromEnd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applySetPos keeps only the BYSETPOS positions of the period's sorted dates
+func (r *RecurrenceRule) applySetPos(dates []civilDate) []civilDate {
+	if len(r.bySetPos) == 0 {
+		return dates
+	}
+
+	var selected []civilDate
+	for _, pos := range r.bySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(dates) + pos
+		}
+
+		if i >= 0 && i < len(dates) {
+			selected = append(selected, dates[i])
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,369 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// takeOccurrences returns at most n occurrences of the rule starting at dtstart
+func takeOccurrences(t *testing.T, rule string, dtstart time.Time, n int) []time.Time {
+	t.Helper()
+
+	r, err := ParseRecurrenceRule(rule)
+	assert.NoError(t, err)
+
+	var occurrences []time.Time
+	it := r.iterator(dtstart)
+	for len(occurrences) < n {
+		occurrence, ok := it.next()
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+}
+
+func TestRecurrenceRule_iterator(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		take     int
+		expected []time.Time
+	}{
+		{
+			name:     "Daily with count",
+			rule:     "FREQ=DAILY;COUNT=3",
+			dtstart:  date(2024, time.January, 30),
+			take:     10,
+			expected: []time.Time{date(2024, time.January, 30), date(2024, time.January, 31), date(2024, time.February, 1)},
+		},
+		{
+			name:     "Every other day until",
+			rule:     "FREQ=DAILY;INTERVAL=2;UNTIL=20240105T090000Z",
+			dtstart:  date(2024, time.January, 1),
+			take:     10,
+			expected: []time.Time{date(2024, time.January, 1), date(2024, time.January, 3), date(2024, time.January, 5)},
+		},
+		{
+			name:    "Weekly on Monday, Wednesday and Friday",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			dtstart: date(2024, time.January, 3),
+			take:    4,
+			expected: []time.Time{
+				date(2024, time.January, 3), date(2024, time.January, 5),
+				date(2024, time.January, 8), date(2024, time.January, 10),
+			},
+		},
+		{
+			name:    "Every other week honours the week start",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;WKST=SU",
+			dtstart: date(2024, time.January, 2),
+			take:    4,
+			expected: []time.Time{
+				date(2024, time.January, 2), date(2024, time.January, 14),
+				date(2024, time.January, 16), date(2024, time.January, 28),
+			},
+		},
+		{
+			name:    "Every second Tuesday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2",
+			dtstart: date(2024, time.January, 1),
+			take:    3,
+			expected: []time.Time{
+				date(2024, time.January, 9), date(2024, time.February, 13), date(2024, time.March, 12),
+			},
+		},
+		{
+			name:    "Second Tuesday via ordinal",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.January, 9), date(2024, time.February, 13),
+			},
+		},
+		{
+			name:    "Last weekday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: date(2024, time.January, 1),
+			take:    3,
+			expected: []time.Time{
+				date(2024, time.January, 31), date(2024, time.February, 29), date(2024, time.March, 29),
+			},
+		},
+		{
+			name:    "Last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: date(2023, time.December, 31),
+			take:    3,
+			expected: []time.Time{
+				date(2023, time.December, 31), date(2024, time.January, 31), date(2024, time.February, 29),
+			},
+		},
+		{
+			name:    "Monthly on the 31st skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: date(2024, time.January, 31),
+			take:    3,
+			expected: []time.Time{
+				date(2024, time.January, 31), date(2024, time.March, 31), date(2024, time.May, 31),
+			},
+		},
+		{
+			name:    "Yearly on March 3",
+			rule:    "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.March, 3), date(2025, time.March, 3),
+			},
+		},
+		{
+			name:    "Yearly on February 29",
+			rule:    "FREQ=YEARLY",
+			dtstart: date(2024, time.February, 29),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.February, 29), date(2028, time.February, 29),
+			},
+		},
+		{
+			name:    "Yearly on the 20th Monday of the year",
+			rule:    "FREQ=YEARLY;BYDAY=20MO",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.May, 13), date(2025, time.May, 19),
+			},
+		},
+		{
+			name:    "Yearly on the last Sunday of March",
+			rule:    "FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.March, 31), date(2025, time.March, 30),
+			},
+		},
+		{
+			name:    "Friday the 13th",
+			rule:    "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				date(2024, time.September, 13), date(2024, time.December, 13),
+			},
+		},
+		{
+			name:    "Daily filtered by month",
+			rule:    "FREQ=DAILY;BYMONTH=2;COUNT=2",
+			dtstart: date(2024, time.January, 30),
+			take:    10,
+			expected: []time.Time{
+				date(2024, time.February, 1), date(2024, time.February, 2),
+			},
+		},
+		{
+			name:    "Hourly",
+			rule:    "FREQ=HOURLY;INTERVAL=12;COUNT=3",
+			dtstart: date(2024, time.January, 1),
+			take:    10,
+			expected: []time.Time{
+				date(2024, time.January, 1), date(2024, time.January, 1).Add(12 * time.Hour), date(2024, time.January, 2),
+			},
+		},
+		{
+			name:    "Minutely filtered by a month months away",
+			rule:    "FREQ=MINUTELY;INTERVAL=7;BYMONTH=12",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				// The first of the 7 minute steps from dtstart on December 1st
+				time.Date(2024, time.December, 1, 0, 6, 0, 0, time.UTC),
+				time.Date(2024, time.December, 1, 0, 13, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "Secondly filtered by a leap day",
+			rule:    "FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=29;COUNT=1",
+			dtstart: date(2025, time.March, 1),
+			take:    10,
+			expected: []time.Time{
+				time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "Hourly filtered by weekday",
+			rule:    "FREQ=HOURLY;INTERVAL=5;BYDAY=SA",
+			dtstart: date(2024, time.January, 1),
+			take:    2,
+			expected: []time.Time{
+				time.Date(2024, time.January, 6, 4, 0, 0, 0, time.UTC),
+				time.Date(2024, time.January, 6, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "Impossible date ends the expansion",
+			rule:     "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart:  date(2024, time.January, 1),
+			take:     1,
+			expected: nil,
+		},
+		{
+			name:     "Impossible date ends a minutely expansion",
+			rule:     "FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=30",
+			dtstart:  date(2024, time.January, 1),
+			take:     1,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := takeOccurrences(t, tt.rule, tt.dtstart, tt.take)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRecurrenceRule_Between(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		dtstart   time.Time
+		from      time.Time
+		to        time.Time
+		wantCount int
+		wantFirst time.Time
+		wantErr   error
+	}{
+		{
+			name:      "Daily across the end of the year",
+			rule:      "FREQ=DAILY",
+			dtstart:   date(2023, time.December, 30),
+			from:      date(2023, time.December, 30),
+			to:        date(2024, time.March, 1),
+			wantCount: 62,
+			wantFirst: date(2023, time.December, 30),
+		},
+		{
+			name:      "Window after dtstart",
+			rule:      "FREQ=WEEKLY;BYDAY=MO",
+			dtstart:   date(2024, time.January, 1),
+			from:      date(2025, time.January, 1),
+			to:        date(2025, time.February, 1),
+			wantCount: 4,
+			wantFirst: date(2025, time.January, 6),
+		},
+		{
+			name:      "Count ends before the window does",
+			rule:      "FREQ=MONTHLY;COUNT=3",
+			dtstart:   date(2024, time.November, 15),
+			from:      date(2024, time.January, 1),
+			to:        date(2026, time.January, 1),
+			wantCount: 3,
+			wantFirst: date(2024, time.November, 15),
+		},
+		{
+			name:    "Empty window",
+			rule:    "FREQ=DAILY",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.January, 2),
+			to:      date(2024, time.January, 2),
+			wantErr: domain_errors.ErrInvalidRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			assert.NoError(t, err)
+
+			occurrences, err := rule.Between(tt.dtstart, tt.from, tt.to)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, occurrences, tt.wantCount)
+
+			if tt.wantCount > 0 {
+				assert.Equal(t, tt.wantFirst, occurrences[0])
+				assert.True(t, occurrences[len(occurrences)-1].Before(tt.to))
+			}
+		})
+	}
+}
+
+func TestRecurrenceRule_All(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		wantCount int
+		wantErr   error
+	}{
+		{
+			name:      "Count",
+			rule:      "FREQ=DAILY;COUNT=40",
+			wantCount: 40,
+		},
+		{
+			name:      "Until",
+			rule:      "FREQ=MONTHLY;UNTIL=20250101T000000Z",
+			wantCount: 12,
+		},
+		{
+			name:    "Unbounded",
+			rule:    "FREQ=DAILY",
+			wantErr: domain_errors.ErrUnboundedRecurrence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			assert.NoError(t, err)
+
+			occurrences, err := rule.All(date(2024, time.January, 1))
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Len(t, occurrences, tt.wantCount)
+		})
+	}
+}
+
+func TestRecurrenceRule_iterator_daylightSaving(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2024, time.March, day, hour, min, 0, 0, paris)
+	}
+
+	// Every occurrence keeps 09:00 although the spring transition shortens a day
+	morning := takeOccurrences(t, "FREQ=DAILY", at(30, 9, 0), 3)
+	assert.Equal(t, []time.Time{at(30, 9, 0), at(31, 9, 0), at(32, 9, 0)}, morning)
+	assert.Equal(t, 23*time.Hour, morning[1].Sub(morning[0]))
+
+	// 02:30 doesn't exist on the day of the transition and moves to 03:30
+	night := takeOccurrences(t, "FREQ=DAILY", at(30, 2, 30), 3)
+	assert.Len(t, night, 3)
+	assert.Equal(t, time.Date(2024, time.March, 31, 1, 30, 0, 0, time.UTC), night[1].UTC())
+	assert.Equal(t, time.Date(2024, time.April, 1, 0, 30, 0, 0, time.UTC), night[2].UTC())
+
+	// 02:30 occurs twice on the day of the autumn transition, the earlier one is used
+	autumn := takeOccurrences(t, "FREQ=DAILY", time.Date(2024, time.October, 26, 2, 30, 0, 0, paris), 3)
+	assert.Len(t, autumn, 3)
+	assert.Equal(t, time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC), autumn[1].UTC())
+	assert.Equal(t, time.Date(2024, time.October, 28, 1, 30, 0, 0, time.UTC), autumn[2].UTC())
+
+	// Sub-daily frequencies step in elapsed time
+	hourly := takeOccurrences(t, "FREQ=HOURLY", at(31, 1, 0), 2)
+	assert.Equal(t, time.Hour, hourly[1].Sub(hourly[0]))
+	assert.Equal(t, 3, hourly[1].Hour())
+}
@@ -0,0 +1,532 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// Frequency is the FREQ rule part of a recurrence rule
+type Frequency string
+
+const (
+	Secondly Frequency = "SECONDLY"
+	Minutely Frequency = "MINUTELY"
+	Hourly   Frequency = "HOURLY"
+	Daily    Frequency = "DAILY"
+	Weekly   Frequency = "WEEKLY"
+	Monthly  Frequency = "MONTHLY"
+	Yearly   Frequency = "YEARLY"
+)
+
+// rruleUntilLayout is the UTC date-time layout used for the UNTIL rule part
+const rruleUntilLayout = "20060102T150405Z"
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// weekdayCode returns the two letter RFC 5545 code of the weekday
+//
+// A weekday out of range is formatted like time.Weekday does, e.g.
+// "%!Weekday(9)", which no parser accepts.
+func weekdayCode(weekday time.Weekday) string {
+	if weekday < time.Sunday || weekday > time.Saturday {
+		return weekday.String()
+	}
+
+	return weekdayCodes[weekday]
+}
+
+// parseWeekday parses a two letter RFC 5545 weekday code
+func parseWeekday(code string) (time.Weekday, error) {
+	for i, c := range weekdayCodes {
+		if c == code {
+			return time.Weekday(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %q", code)
+}
+
+// WeekdayNum is a BYDAY value, a weekday optionally prefixed by its ordinal
+//
+// An ordinal of 0 matches every such weekday in the period, a positive
+// ordinal the nth one and a negative ordinal the nth one from the end.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// String returns the RFC 5545 representation of the weekday, e.g. "-1FR"
+func (w WeekdayNum) String() string {
+	if w.Ordinal == 0 {
+		return weekdayCode(w.Weekday)
+	}
+
+	return strconv.Itoa(w.Ordinal) + weekdayCode(w.Weekday)
+}
+
+// RecurrenceRule is an RFC 5545 RRULE value
+//
+// It supports the FREQ, INTERVAL, BYDAY, BYMONTHDAY, BYMONTH, BYSETPOS,
+// COUNT, UNTIL and WKST rule parts.
+type RecurrenceRule struct {
+	freq       Frequency
+	interval   int
+	byDay      []WeekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+	bySetPos   []int
+	count      int
+	until      time.Time
+	wkst       time.Weekday
+}
+
+// NewRecurrenceRule creates a new recurrence rule with the given frequency and interval
+//
+// The remaining rule parts can be set through the With* methods.
+func NewRecurrenceRule(freq Frequency, interval int) (*RecurrenceRule, error) {
+	r := &RecurrenceRule{
+		freq:     freq,
+		interval: interval,
+		wkst:     time.Monday,
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewWeeklyRecurrenceRule creates a rule repeating on the weekdays every interval weeks
+//
+// e.g. every Monday, Wednesday and Friday is
+// NewWeeklyRecurrenceRule(1, time.Monday, time.Wednesday, time.Friday).
+// Weeks start on Monday; the weekdays are sorted from it and duplicates removed.
+// Without weekdays, NewWeeklyRecurrenceRule returns domain_errors.ErrInvalidRecurrenceRule.
+func NewWeeklyRecurrenceRule(interval int, weekdays ...time.Weekday) (*RecurrenceRule, error) {
+	r, err := NewRecurrenceRule(Weekly, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(weekdays) == 0 {
+		return nil, errors.Join(domain_errors.ErrInvalidRecurrenceRule, errors.New("at least one weekday is required"))
+	}
+
+	sorted := append([]time.Weekday(nil), weekdays...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return (sorted[i]-r.wkst+7)%7 < (sorted[j]-r.wkst+7)%7
+	})
+
+	days := make([]WeekdayNum, 0, len(sorted))
+	for i, wd := range sorted {
+		if i > 0 && wd == sorted[i-1] {
+			continue
+		}
+		days = append(days, WeekdayNum{Weekday: wd})
+	}
+
+	r.WithByDay(days...)
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// clone returns a deep copy of the rule
+func (r *RecurrenceRule) clone() *RecurrenceRule {
+	c := *r
+	c.byDay = append([]WeekdayNum(nil), r.byDay...)
+	c.byMonthDay = append([]int(nil), r.byMonthDay...)
+	c.byMonth = append([]time.Month(nil), r.byMonth...)
+	c.bySetPos = append([]int(nil), r.bySetPos...)
+
+	return &c
+}
+
+// WithByDay sets the BYDAY rule part
+func (r *RecurrenceRule) WithByDay(days ...WeekdayNum) *RecurrenceRule {
+	r.byDay = append([]WeekdayNum(nil), days...)
+	return r
+}
+
+// WithByMonthDay sets the BYMONTHDAY rule part
+func (r *RecurrenceRule) WithByMonthDay(days ...int) *RecurrenceRule {
+	r.byMonthDay = append([]int(nil), days...)
+	return r
+}
+
+// WithByMonth sets the BYMONTH rule part
+func (r *RecurrenceRule) WithByMonth(months ...time.Month) *RecurrenceRule {
+	r.byMonth = append([]time.Month(nil), months...)
+	return r
+}
+
+// WithBySetPos sets the BYSETPOS rule part
+func (r *RecurrenceRule) WithBySetPos(positions ...int) *RecurrenceRule {
+	r.bySetPos = append([]int(nil), positions...)
+	return r
+}
+
+// WithCount sets the COUNT rule part
+func (r *RecurrenceRule) WithCount(count int) *RecurrenceRule {
+	r.count = count
+	return r
+}
+
+// WithUntil sets the UNTIL rule part
+func (r *RecurrenceRule) WithUntil(until time.Time) *RecurrenceRule {
+	r.until = until
+	return r
+}
+
+// WithWeekStart sets the WKST rule part
+func (r *RecurrenceRule) WithWeekStart(wkst time.Weekday) *RecurrenceRule {
+	r.wkst = wkst
+	return r
+}
+
+// GetFrequency returns the FREQ rule part
+func (r *RecurrenceRule) GetFrequency() Frequency {
+	return r.freq
+}
+
+// GetInterval returns the INTERVAL rule part
+func (r *RecurrenceRule) GetInterval() int {
+	return r.interval
+}
+
+// GetByDay returns a copy of the BYDAY rule part
+func (r *RecurrenceRule) GetByDay() []WeekdayNum {
+	return slices.Clone(r.byDay)
+}
+
+// GetByMonthDay returns a copy of the BYMONTHDAY rule part
+func (r *RecurrenceRule) GetByMonthDay() []int {
+	return slices.Clone(r.byMonthDay)
+}
+
+// GetByMonth returns a copy of the BYMONTH rule part
+func (r *RecurrenceRule) GetByMonth() []time.Month {
+	return slices.Clone(r.byMonth)
+}
+
+// GetBySetPos returns a copy of the BYSETPOS rule part
+func (r *RecurrenceRule) GetBySetPos() []int {
+	return slices.Clone(r.bySetPos)
+}
+
+// GetCount returns the COUNT rule part, 0 when unset
+func (r *RecurrenceRule) GetCount() int {
+	return r.count
+}
+
+// GetUntil returns the UNTIL rule part, the zero time when unset
+func (r *RecurrenceRule) GetUntil() time.Time {
+	return r.until
+}
+
+// GetWeekStart returns the WKST rule part
+func (r *RecurrenceRule) GetWeekStart() time.Weekday {
+	return r.wkst
+}
+
+// Validate validates the recurrence rule
+func (r *RecurrenceRule) Validate() (errc error) {
+	switch r.freq {
+	case Secondly, Minutely, Hourly, Daily, Weekly, Monthly, Yearly:
+	default:
+		errc = errors.Join(fmt.Errorf("unknown frequency %q", r.freq), errc)
+	}
+
+	if r.interval < 1 {
+		errc = errors.Join(fmt.Errorf("interval must be positive, got %d", r.interval), errc)
+	}
+
+	if r.count < 0 {
+		errc = errors.Join(fmt.Errorf("count must not be negative, got %d", r.count), errc)
+	}
+
+	if r.count > 0 && !r.until.IsZero() {
+		errc = errors.Join(errors.New("count and until are mutually exclusive"), errc)
+	}
+
+	for _, d := range r.byDay {
+		if d.Weekday < time.Sunday || d.Weekday > time.Saturday {
+			errc = errors.Join(fmt.Errorf("invalid weekday %d", d.Weekday), errc)
+		}
+
+		if d.Ordinal < -53 || d.Ordinal > 53 {
+			errc = errors.Join(fmt.Errorf("invalid weekday ordinal %d", d.Ordinal), errc)
+		}
+
+		if d.Ordinal != 0 && r.freq != Monthly && r.freq != Yearly {
+			errc = errors.Join(fmt.Errorf("weekday ordinals require a monthly or yearly frequency"), errc)
+		}
+	}
+
+	for _, d := range r.byMonthDay {
+		if d == 0 || d < -31 || d > 31 {
+			errc = errors.Join(fmt.Errorf("invalid month day %d", d), errc)
+		}
+	}
+
+	if len(r.byMonthDay) > 0 && r.freq == Weekly {
+		errc = errors.Join(errors.New("month days cannot be used with a weekly frequency"), errc)
+	}
+
+	for _, m := range r.byMonth {
+		if m < time.January || m > time.December {
+			errc = errors.Join(fmt.Errorf("invalid month %d", m), errc)
+		}
+	}
+
+	for _, p := range r.bySetPos {
+		if p == 0 || p < -366 || p > 366 {
+			errc = errors.Join(fmt.Errorf("invalid set position %d", p), errc)
+		}
+	}
+
+	if len(r.bySetPos) > 0 && len(r.byDay) == 0 && len(r.byMonthDay) == 0 && len(r.byMonth) == 0 {
+		errc = errors.Join(errors.New("set positions require another BYxxx rule part"), errc)
+	}
+
+	if r.wkst < time.Sunday || r.wkst > time.Saturday {
+		errc = errors.Join(fmt.Errorf("invalid week start %d", r.wkst), errc)
+	}
+
+	if errc != nil {
+		errc = errors.Join(domain_errors.ErrInvalidRecurrenceRule, errc)
+	}
+
+	return errc
+}
+
+// ParseRecurrenceRule parses an RFC 5545 RRULE value such as
+// "FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2"
+//
+// An optional "RRULE:" prefix is accepted. A floating UNTIL value is read as UTC.
+// If the value cannot be parsed, ParseRecurrenceRule returns
+// domain_errors.ErrInvalidRecurrenceRule joined with the details.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+
+	r := &RecurrenceRule{interval: 1, wkst: time.Monday}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, errors.Join(domain_errors.ErrInvalidRecurrenceRule, fmt.Errorf("malformed rule part %q", part))
+		}
+
+		name = strings.ToUpper(name)
+		val = strings.ToUpper(val)
+		if seen[name] {
+			return nil, errors.Join(domain_errors.ErrInvalidRecurrenceRule, fmt.Errorf("duplicate rule part %s", name))
+		}
+		seen[name] = true
+
+		if err := r.parsePart(name, val); err != nil {
+			return nil, errors.Join(domain_errors.ErrInvalidRecurrenceRule, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if !seen["FREQ"] {
+		return nil, errors.Join(domain_errors.ErrInvalidRecurrenceRule, errors.New("FREQ is required"))
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// parsePart parses a single NAME=VALUE rule part into the rule
+func (r *RecurrenceRule) parsePart(name, val string) error {
+	var err error
+
+	switch name {
+	case "FREQ":
+		r.freq = Frequency(val)
+	case "INTERVAL":
+		r.interval, err = strconv.Atoi(val)
+	case "COUNT":
+		r.count, err = strconv.Atoi(val)
+	case "UNTIL":
+		r.until, err = parseUntil(val)
+	case "WKST":
+		r.wkst, err = parseWeekday(val)
+	case "BYDAY":
+		r.byDay, err = parseList(val, parseWeekdayNum)
+	case "BYMONTHDAY":
+		r.byMonthDay, err = parseList(val, strconv.Atoi)
+	case "BYSETPOS":
+		r.bySetPos, err = parseList(val, strconv.Atoi)
+	case "BYMONTH":
+		r.byMonth, err = parseList(val, func(s string) (time.Month, error) {
+			m, err := strconv.Atoi(s)
+			return time.Month(m), err
+		})
+	default:
+		err = errors.New("unsupported rule part")
+	}
+
+	return err
+}
+
+// parseUntil parses an UNTIL value in either DATE or DATE-TIME form
+func parseUntil(val string) (time.Time, error) {
+	switch len(val) {
+	case len("20060102"):
+		return time.ParseInLocation("20060102", val, time.UTC)
+	case len("20060102T150405"):
+		return time.ParseInLocation("20060102T150405", val, time.UTC)
+	default:
+		return time.Parse(rruleUntilLayout, val)
+	}
+}
+
+// parseWeekdayNum parses a BYDAY value such as "MO", "2TU" or "-1FR"
+func parseWeekdayNum(val string) (WeekdayNum, error) {
+	if len(val) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", val)
+	}
+
+	weekday, err := parseWeekday(val[len(val)-2:])
+	if err != nil {
+		return WeekdayNum{}, err
+	}
+
+	var ordinal int
+	if prefix := val[:len(val)-2]; prefix != "" {
+		ordinal, err = strconv.Atoi(prefix)
+		if err != nil || ordinal == 0 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday ordinal %q", prefix)
+		}
+	}
+
+	return WeekdayNum{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+// parseList parses a comma separated list of values
+func parseList[T any](val string, parse func(string) (T, error)) ([]T, error) {
+	parts := strings.Split(val, ",")
+	values := make([]T, 0, len(parts))
+
+	for _, p := range parts {
+		v, err := parse(p)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// String formats the rule as an RFC 5545 RRULE value, without the "RRULE:" prefix
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.freq)}
+
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	}
+
+	if !r.until.IsZero() {
+		parts = append(parts, "UNTIL="+r.until.UTC().Format(rruleUntilLayout))
+	}
+
+	if len(r.byMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(r.byMonth, func(m time.Month) string {
+			return strconv.Itoa(int(m))
+		}))
+	}
+
+	if len(r.byMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(r.byMonthDay, strconv.Itoa))
+	}
+
+	if len(r.byDay) > 0 {
+		parts = append(parts, "BYDAY="+joinList(r.byDay, WeekdayNum.String))
+	}
+
+	if len(r.bySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinList(r.bySetPos, strconv.Itoa))
+	}
+
+	if r.wkst != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.wkst))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// joinList formats a list of values as a comma separated string
+func joinList[T any](values []T, format func(T) string) string {
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = format(v)
+	}
+
+	return strings.Join(formatted, ",")
+}
+
+// recurrenceRuleFromInterval derives a recurrence rule from a fixed repeating interval
+//
+// It returns nil when the interval is not a positive whole number of seconds.
+func recurrenceRuleFromInterval(interval time.Duration) *RecurrenceRule {
+	if interval <= 0 || interval%time.Second != 0 {
+		return nil
+	}
+
+	units := []struct {
+		freq Frequency
+		unit time.Duration
+	}{
+		{Daily, 24 * time.Hour},
+		{Hourly, time.Hour},
+		{Minutely, time.Minute},
+		{Secondly, time.Second},
+	}
+
+	for _, u := range units {
+		if interval%u.unit == 0 {
+			return &RecurrenceRule{freq: u.freq, interval: int(interval / u.unit), wkst: time.Monday}
+		}
+	}
+
+	return nil
+}
+
+// sortedUnique sorts the times and removes duplicates
+func sortedUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	unique := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			unique = append(unique, t)
+		}
+	}
+
+	return unique
+}
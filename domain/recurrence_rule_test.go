@@ -0,0 +1,319 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected *RecurrenceRule
+		wantErr  error
+	}{
+		{
+			name:     "Daily",
+			value:    "FREQ=DAILY",
+			expected: &RecurrenceRule{freq: Daily, interval: 1, wkst: time.Monday},
+		},
+		{
+			name:  "Every second Tuesday of the month",
+			value: "RRULE:FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2",
+			expected: &RecurrenceRule{
+				freq:     Monthly,
+				interval: 1,
+				byDay:    []WeekdayNum{{Weekday: time.Tuesday}},
+				bySetPos: []int{2},
+				wkst:     time.Monday,
+			},
+		},
+		{
+			name:  "Yearly on March 3 until 2030",
+			value: "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3;UNTIL=20300101T000000Z",
+			expected: &RecurrenceRule{
+				freq:       Yearly,
+				interval:   1,
+				byMonth:    []time.Month{time.March},
+				byMonthDay: []int{3},
+				until:      time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+				wkst:       time.Monday,
+			},
+		},
+		{
+			name:  "Lower case weekly with count and week start",
+			value: "freq=weekly;interval=2;count=10;byday=mo,we,fr;wkst=su",
+			expected: &RecurrenceRule{
+				freq:     Weekly,
+				interval: 2,
+				count:    10,
+				byDay: []WeekdayNum{
+					{Weekday: time.Monday},
+					{Weekday: time.Wednesday},
+					{Weekday: time.Friday},
+				},
+				wkst: time.Sunday,
+			},
+		},
+		{
+			name:  "Last Friday",
+			value: "FREQ=MONTHLY;BYDAY=-1FR",
+			expected: &RecurrenceRule{
+				freq:     Monthly,
+				interval: 1,
+				byDay:    []WeekdayNum{{Ordinal: -1, Weekday: time.Friday}},
+				wkst:     time.Monday,
+			},
+		},
+		{
+			name:    "Missing frequency",
+			value:   "INTERVAL=2",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Unknown frequency",
+			value:   "FREQ=FORTNIGHTLY",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Unsupported rule part",
+			value:   "FREQ=DAILY;BYHOUR=9",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Duplicate rule part",
+			value:   "FREQ=DAILY;FREQ=WEEKLY",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Count and until",
+			value:   "FREQ=DAILY;COUNT=2;UNTIL=20300101",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Ordinal with weekly frequency",
+			value:   "FREQ=WEEKLY;BYDAY=1MO",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Invalid month day",
+			value:   "FREQ=MONTHLY;BYMONTHDAY=32",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Set position without another rule part",
+			value:   "FREQ=MONTHLY;BYSETPOS=1",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:    "Malformed rule part",
+			value:   "FREQ",
+			wantErr: domain_errors.ErrInvalidRecurrenceRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.value)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, rule)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func TestRecurrenceRule_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "Daily",
+			value:    "FREQ=DAILY;INTERVAL=1",
+			expected: "FREQ=DAILY",
+		},
+		{
+			name:     "Canonical order",
+			value:    "BYSETPOS=-1;BYDAY=MO,TU,WE,TH,FR;FREQ=MONTHLY;INTERVAL=3",
+			expected: "FREQ=MONTHLY;INTERVAL=3;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		},
+		{
+			name:     "Floating until is formatted as UTC",
+			value:    "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=3;UNTIL=20300101",
+			expected: "FREQ=YEARLY;UNTIL=20300101T000000Z;BYMONTH=3;BYMONTHDAY=3",
+		},
+		{
+			name:     "Week start and count",
+			value:    "FREQ=MONTHLY;COUNT=4;BYDAY=2MO;WKST=SU",
+			expected: "FREQ=MONTHLY;COUNT=4;BYDAY=2MO;WKST=SU",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rule.String())
+
+			roundTrip, err := ParseRecurrenceRule(rule.String())
+			assert.NoError(t, err)
+			assert.Equal(t, rule, roundTrip)
+		})
+	}
+}
+
+func TestRecurrenceRule_String_invalidWeekday(t *testing.T) {
+	rule, err := NewRecurrenceRule(Weekly, 1)
+	assert.NoError(t, err)
+
+	rule.WithByDay(WeekdayNum{Weekday: 9}, WeekdayNum{Ordinal: 2, Weekday: 8}).WithWeekStart(7)
+
+	assert.Equal(t, "%!Weekday(9)", WeekdayNum{Weekday: 9}.String())
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=%!Weekday(9),2%!Weekday(8);WKST=%!Weekday(7)", rule.String())
+	assert.ErrorIs(t, rule.Validate(), domain_errors.ErrInvalidRecurrenceRule)
+
+	_, err = ParseRecurrenceRule(rule.String())
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidRecurrenceRule)
+}
+
+func TestRecurrenceRule_getters(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=MONTHLY;BYMONTH=1,2;BYMONTHDAY=1,2;BYDAY=MO,TU;BYSETPOS=1,-1")
+	assert.NoError(t, err)
+
+	// The getters return copies, mutating them leaves the rule untouched
+	rule.GetByDay()[0] = WeekdayNum{Weekday: time.Sunday}
+	rule.GetByMonthDay()[0] = 31
+	rule.GetByMonth()[0] = time.December
+	rule.GetBySetPos()[0] = 3
+
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTH=1,2;BYMONTHDAY=1,2;BYDAY=MO,TU;BYSETPOS=1,-1", rule.String())
+}
+
+func TestNewRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		freq     Frequency
+		interval int
+		wantErr  error
+	}{
+		{
+			name:     "Valid rule",
+			freq:     Weekly,
+			interval: 2,
+		},
+		{
+			name:     "Zero interval",
+			freq:     Weekly,
+			interval: 0,
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "Unknown frequency",
+			freq:     "",
+			interval: 1,
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRecurrenceRule(tt.freq, tt.interval)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if err == nil {
+				assert.Equal(t, tt.freq, rule.GetFrequency())
+				assert.Equal(t, tt.interval, rule.GetInterval())
+				assert.Equal(t, time.Monday, rule.GetWeekStart())
+			}
+		})
+	}
+}
+
+func TestNewWeeklyRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		weekdays []time.Weekday
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Monday, Wednesday and Friday",
+			interval: 1,
+			weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday},
+			want:     "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+		},
+		{
+			name:     "Sorted from the week start without duplicates",
+			interval: 2,
+			weekdays: []time.Weekday{time.Sunday, time.Friday, time.Monday, time.Friday},
+			want:     "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR,SU",
+		},
+		{
+			name:     "No weekdays",
+			interval: 1,
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "Zero interval",
+			interval: 0,
+			weekdays: []time.Weekday{time.Monday},
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "Invalid weekday",
+			interval: 1,
+			weekdays: []time.Weekday{time.Weekday(7)},
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewWeeklyRecurrenceRule(tt.interval, tt.weekdays...)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if err == nil {
+				assert.Equal(t, tt.want, rule.String())
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleFromInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		expected string
+	}{
+		{name: "Two days", interval: 48 * time.Hour, expected: "FREQ=DAILY;INTERVAL=2"},
+		{name: "Hours", interval: 36 * time.Hour, expected: "FREQ=HOURLY;INTERVAL=36"},
+		{name: "Minutes", interval: 90 * time.Minute, expected: "FREQ=MINUTELY;INTERVAL=90"},
+		{name: "Seconds", interval: 5 * time.Second, expected: "FREQ=SECONDLY;INTERVAL=5"},
+		{name: "Zero", interval: 0},
+		{name: "Sub-second", interval: time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := recurrenceRuleFromInterval(tt.interval)
+
+			if tt.expected == "" {
+				assert.Nil(t, rule)
+				return
+			}
+
+			assert.Equal(t, tt.expected, rule.String())
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskRepository is the port through which tasks are persisted
+//
+// Tasks are stored by their TaskID, originals and copies alike.
+type TaskRepository interface {
+	// Save inserts the task or replaces the stored task with the same TaskID
+	Save(ctx context.Context, task *Task) error
+	// FindByID returns the task with the given TaskID
+	//
+	// If there is no such task, FindByID returns domain_errors.ErrTaskNotFound.
+	FindByID(ctx context.Context, id TaskID) (*Task, error)
+	// FindInRange returns the stored tasks whose time falls within [from, to), sorted by time
+	//
+	// Occurrences of a series which were not stored are not generated.
+	FindInRange(ctx context.Context, from, to time.Time) ([]*Task, error)
+	// Delete removes the task with the given TaskID
+	//
+	// If there is no such task, Delete returns domain_errors.ErrTaskNotFound.
+	Delete(ctx context.Context, id TaskID) error
+}
+
+// CalendarRepository is the port through which calendars are persisted
+//
+// A calendar is stored as its series; the occurrences are regenerated when
+// it is loaded.
+type CalendarRepository interface {
+	// Save inserts the calendar or replaces the stored calendar with the same ID
+	Save(ctx context.Context, calendar *Calendar) error
+	// Load returns the calendar with the given ID
+	//
+	// If there is no such calendar, Load returns domain_errors.ErrCalendarNotFound.
+	// If only some of its tasks cannot be restored, Load may return the
+	// calendar without them together with their errors.
+	Load(ctx context.Context, id uuid.UUID) (*Calendar, error)
+	// Delete removes the calendar with the given ID
+	//
+	// If there is no such calendar, Delete returns domain_errors.ErrCalendarNotFound.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
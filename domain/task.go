@@ -3,6 +3,9 @@ package domain
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
+	"sort"
 	"time"
 
 	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
@@ -14,10 +17,22 @@ type Task struct {
 	title             string
 	repeating         bool
 	repeatingInterval time.Duration
+	recurrence        *RecurrenceRule
 	description       string
 	completed         bool
-	dayOfWeek         time.Weekday
 	time              time.Time
+	// duration is how long the task lasts, zero for a task without an end
+	duration time.Duration
+	// allDay tasks last whole days, duration is a multiple of a day
+	allDay bool
+	// priority is 1 for the highest to 9 for the lowest, 0 when undefined
+	priority int
+	// occurrenceTime is the time a copy was generated for within its series
+	occurrenceTime time.Time
+	// exDates, rDates and overrides are the exceptions of a recurring series
+	exDates   map[OccurrenceKey]time.Time
+	rDates    []time.Time
+	overrides map[OccurrenceKey]*Task
 }
 
 // GetID returns the task ID
@@ -41,8 +56,10 @@ func (t *Task) IsCompleted() bool {
 }
 
 // GetDayOfWeek returns the day of the week of the task
+//
+// It is the day of the week of the task's time in its time zone.
 func (t *Task) GetDayOfWeek() time.Weekday {
-	return t.dayOfWeek
+	return t.time.Weekday()
 }
 
 // GetTime returns the time of the task
@@ -50,6 +67,54 @@ func (t *Task) GetTime() time.Time {
 	return t.time
 }
 
+// GetDuration returns how long the task lasts, zero for a task without an end
+//
+// The duration of an all-day task is its number of days times 24 hours.
+func (t *Task) GetDuration() time.Duration {
+	return t.duration
+}
+
+// GetEndTime returns the time the task ends, its own time if it has no end
+//
+// An all-day task ends at the start of the day after its last day, in its
+// time zone, so a day shortened by daylight saving is still a whole day.
+func (t *Task) GetEndTime() time.Time {
+	if !t.allDay {
+		return t.time.Add(t.duration)
+	}
+
+	year, month, day := t.time.Date()
+	end, _ := LocalTime(year, month, day+t.days(), 0, 0, 0, 0, t.time.Location(), ShiftLocalTime)
+
+	return end
+}
+
+// GetPriority returns the priority of the task
+//
+// As in RFC 5545, 1 is the highest priority and 9 the lowest; 0 is undefined.
+// Tasks at the same time are ordered by priority, the undefined ones last.
+func (t *Task) GetPriority() int {
+	return t.priority
+}
+
+// IsAllDay returns true if the task lasts whole days
+func (t *Task) IsAllDay() bool {
+	return t.allDay
+}
+
+// days returns the number of days of an all-day task
+func (t *Task) days() int {
+	return int(t.duration / (24 * time.Hour))
+}
+
+// GetTimeZone returns the time zone of the task
+//
+// It is the location of the task's time. A recurring task repeats at the
+// same wall clock time in its time zone, whatever its UTC offset.
+func (t *Task) GetTimeZone() *time.Location {
+	return t.time.Location()
+}
+
 // IsRepeating returns true if the task is repeating
 func (t *Task) IsRepeating() (bool, time.Duration) {
 	if t.repeating {
@@ -59,13 +124,28 @@ func (t *Task) IsRepeating() (bool, time.Duration) {
 	return false, 0
 }
 
+// GetRecurrenceRule returns the recurrence rule of the task
+//
+// A task created with a repeating interval gets the equivalent rule, e.g.
+// 48h becomes FREQ=DAILY;INTERVAL=2. If the task does not repeat, nil is returned.
+func (t *Task) GetRecurrenceRule() *RecurrenceRule {
+	if t.recurrence != nil {
+		return t.recurrence
+	}
+
+	if !t.repeating {
+		return nil
+	}
+
+	return recurrenceRuleFromInterval(t.repeatingInterval)
+}
+
 // NewTask creates a new task
 func NewTask(
 	taskId *TaskID,
 	title, description string,
 	repeating bool,
 	repeatingInterval time.Duration,
-	dayOfWeek time.Weekday,
 	time time.Time) (*Task, error) {
 	task := &Task{
 		id:                taskId,
@@ -73,7 +153,6 @@ func NewTask(
 		repeating:         repeating,
 		repeatingInterval: repeatingInterval,
 		description:       description,
-		dayOfWeek:         dayOfWeek,
 		time:              time,
 	}
 
@@ -84,13 +163,113 @@ func NewTask(
 	return task, nil
 }
 
+// NewRecurringTask creates a new task which repeats following the recurrence rule
+func NewRecurringTask(
+	taskId *TaskID,
+	title, description string,
+	rule *RecurrenceRule,
+	time time.Time) (*Task, error) {
+	task := &Task{
+		id:          taskId,
+		title:       title,
+		repeating:   rule != nil,
+		recurrence:  rule,
+		description: description,
+		time:        time,
+	}
+
+	if err := task.Validate(); err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	return task, nil
+}
+
+// NewWeeklyTask creates a new task which repeats on the weekdays every interval weeks
+//
+// The task's time gives the time of day of every occurrence. A time on none
+// of the weekdays is moved to the first of them after it, at the same wall
+// clock time, so the series starts with its first occurrence.
+func NewWeeklyTask(
+	taskId *TaskID,
+	title, description string,
+	interval int,
+	weekdays []time.Weekday,
+	time time.Time) (*Task, error) {
+	rule, err := NewWeeklyRecurrenceRule(interval, weekdays...)
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	if !time.IsZero() && !rule.matchesWeekday(time.Weekday(), time.Weekday()) {
+		time = firstWeekdayFrom(time, weekdays)
+	}
+
+	return NewRecurringTask(taskId, title, description, rule, time)
+}
+
+// firstWeekdayFrom returns the wall clock time of t on the first of the weekdays after it
+func firstWeekdayFrom(t time.Time, weekdays []time.Weekday) time.Time {
+	days := 7
+	for _, wd := range weekdays {
+		if d := (int(wd)-int(t.Weekday())+6)%7 + 1; d < days {
+			days = d
+		}
+	}
+
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	moved, _ := LocalTime(year, month, day+days, hour, min, sec, t.Nanosecond(), t.Location(), ShiftLocalTime)
+
+	return moved
+}
+
+// RehydrateTask recreates a persisted task with its ID and completion state
+//
+// rule is nil for a task which does not repeat or only repeats by
+// repeatingInterval. The exceptions of a series are restored through
+// ExcludeOccurrence, AddOccurrence and OverrideOccurrence.
+func RehydrateTask(
+	taskId *TaskID,
+	title, description string,
+	repeating bool,
+	repeatingInterval time.Duration,
+	rule *RecurrenceRule,
+	completed bool,
+	time time.Time) (*Task, error) {
+	task := &Task{
+		id:                taskId,
+		title:             title,
+		repeating:         repeating || rule != nil,
+		repeatingInterval: repeatingInterval,
+		recurrence:        rule,
+		description:       description,
+		completed:         completed,
+		time:              time,
+	}
+
+	if taskId == nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, domain_errors.ErrInvalidTaskID)
+	}
+
+	if err := task.Validate(); err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	return task, nil
+}
+
 // Validate validates the task
 func (t *Task) Validate() (errc error) {
-	isOriginal := t.id.original && t.id.secondaryId == t.id.primaryId
-	isCopy := !t.id.original && t.id.secondaryId != t.id.primaryId
-
-	if !isCopy && !isOriginal {
+	if t.id == nil {
 		errc = errors.Join(domain_errors.ErrInvalidTaskID, errc)
+	} else {
+		isOriginal := t.id.original && t.id.secondaryId == t.id.primaryId
+		isCopy := !t.id.original && t.id.secondaryId != t.id.primaryId
+
+		if !isCopy && !isOriginal {
+			errc = errors.Join(domain_errors.ErrInvalidTaskID, errc)
+		}
 	}
 
 	if t.title == "" {
@@ -102,113 +281,278 @@ func (t *Task) Validate() (errc error) {
 
 	}
 
-	if t.dayOfWeek == 0 {
-		errc = errors.Join(domain_errors.ErrDayOfWeekRequired, errc)
-	}
-
 	if t.time.IsZero() {
 		errc = errors.Join(domain_errors.ErrTimeRequired, errc)
 	}
 
+	if t.duration < 0 || (t.allDay && (t.duration == 0 || t.duration%(24*time.Hour) != 0)) {
+		errc = errors.Join(domain_errors.ErrInvalidEndTime, errc)
+	}
+
+	if t.priority < 0 || t.priority > 9 {
+		errc = errors.Join(domain_errors.ErrInvalidPriority, errc)
+	}
+
+	if t.recurrence != nil {
+		if err := t.recurrence.Validate(); err != nil {
+			errc = errors.Join(err, errc)
+		}
+	}
+
 	return errc
 }
 
 // Complete marks the task as completed
+//
+// A task placed in a calendar is read-only, see Calendar.CompleteOccurrence.
 func (t *Task) Complete() {
 	t.completed = true
 }
 
+// SetDuration sets how long the task lasts
+//
+// It is meant to be set before the task is added to a calendar; UpdateTask
+// changes the duration of a placed task.
+// If the duration is not positive, SetDuration returns domain_errors.ErrInvalidEndTime.
+func (t *Task) SetDuration(duration time.Duration) error {
+	if duration <= 0 {
+		return domain_errors.ErrInvalidEndTime
+	}
+
+	t.duration = duration
+	t.allDay = false
+
+	return nil
+}
+
+// SetPriority sets the priority of the task, 0 to leave it undefined
+//
+// The priority orders the task among those at the same time, so it is meant
+// to be set before the task is added to a calendar; UpdateTask changes the
+// priority of a placed task, which is read-only.
+// If the priority is not within 0 and 9, SetPriority returns domain_errors.ErrInvalidPriority.
+func (t *Task) SetPriority(priority int) error {
+	if priority < 0 || priority > 9 {
+		return domain_errors.ErrInvalidPriority
+	}
+
+	t.priority = priority
+
+	return nil
+}
+
+// SetEndTime sets the time the task ends
+//
+// If end is not after the task time, SetEndTime returns domain_errors.ErrInvalidEndTime.
+func (t *Task) SetEndTime(end time.Time) error {
+	return t.SetDuration(end.Sub(t.time))
+}
+
+// SetAllDay makes the task last the given number of whole days
+//
+// The task time moves to the start of its day in its time zone, so it is
+// meant to be called on a new task, before its exceptions are added.
+// If days is less than 1, SetAllDay returns domain_errors.ErrInvalidEndTime.
+func (t *Task) SetAllDay(days int) error {
+	if days < 1 {
+		return domain_errors.ErrInvalidEndTime
+	}
+
+	year, month, day := t.time.Date()
+	t.time, _ = LocalTime(year, month, day, 0, 0, 0, 0, t.time.Location(), ShiftLocalTime)
+	t.duration = time.Duration(days) * 24 * time.Hour
+	t.allDay = true
+
+	return nil
+}
+
 // CalculateNextRepeatingTime calculates the next repeating time
+//
+// The next time follows the task's recurrence rule, so a daily task keeps
+// its wall clock time in its time zone across daylight saving transitions.
+// If the rule has no further occurrence, the task time is returned.
 func (t *Task) CalculateNextRepeatingTime() time.Time {
-	return t.time.Add(t.repeatingInterval)
+	rule := t.GetRecurrenceRule()
+	if rule == nil {
+		return t.time.Add(t.repeatingInterval)
+	}
+
+	it := rule.iterator(t.time)
+	for {
+		next, ok := it.next()
+		if !ok {
+			return t.time
+		}
+
+		if next.After(t.time) {
+			return next
+		}
+	}
+}
+
+// defaultWindow returns the window an unbounded series is expanded within by
+// default, DefaultHorizon from its start or its first MaxPlacedOccurrences
+// occurrences, whichever ends first
+func (t *Task) defaultWindow() (from, to time.Time) {
+	if rule := t.GetRecurrenceRule(); rule == nil || rule.IsBounded() {
+		return time.Time{}, time.Time{}
+	}
+
+	return t.time, t.placementEnd(t.time, t.time.Add(DefaultHorizon))
+}
+
+// placementEnd returns to, or the time of the first occurrence within
+// [from, to) past the first MaxPlacedOccurrences, so a series with a short
+// period is not expanded all at once
+func (t *Task) placementEnd(from, to time.Time) time.Time {
+	end, n := to, 0
+	t.occurrencesBetween(from, to, func(date time.Time) bool {
+		n++
+		if n > MaxPlacedOccurrences {
+			end = date
+			return false
+		}
+
+		return true
+	})
+
+	return end
 }
 
 // searchRepetition returns a channel with the next repeating times
 //
 // If the task is not repeating, the channel will contain only the task time
-// If the task is repeating, the channel will contain the next repeating times
+// If the task is repeating, the channel will contain the times expanded from its recurrence rule
+// A rule bounded by COUNT or UNTIL is expanded completely, any other rule
+// within its default window. Use searchRepetitionBetween for other windows.
 // The channel will be closed when there are no more repeating times
 func (t *Task) searchRepetition(ctx context.Context) <-chan time.Time {
-	// var times []time.Time
-	// quit := make(chan struct{})
-	isRepeating, interval := t.IsRepeating()
-
-	timeChan := make(chan time.Time)
+	from, to := t.defaultWindow()
 
-	if (!isRepeating) || (interval == 0) {
-		go func(ch chan<- time.Time) {
-			defer close(ch)
-			ch <- t.time
-		}(timeChan)
-		return timeChan
-	}
+	return t.searchRepetitionBetween(ctx, from, to)
+}
 
-	// next month in time
-	lastDay := time.Date(t.time.Year(), t.time.Month()+1, 0, 23, 59, 59, 0, t.time.Location())
+// searchRepetitionBetween returns a channel with the repeating times within [from, to)
+//
+// The window may cross month and year boundaries. A zero from or to leaves that
+// side of the window open; without an end the expansion stops at the rule's
+// COUNT or UNTIL, or when the context is cancelled.
+// Excluded occurrences are skipped and extra ones included. The channel carries
+// the original time of each occurrence; an overridden occurrence is sent when
+// the time it was moved to falls within the window.
+// The channel will be closed when there are no more repeating times
+func (t *Task) searchRepetitionBetween(ctx context.Context, from, to time.Time) <-chan time.Time {
+	timeChan := make(chan time.Time)
 
 	go func() {
 		defer close(timeChan)
-		currentTime := t.time
-		for {
-			select {
-			case <-ctx.Done():
+
+		t.occurrencesBetween(from, to, func(date time.Time) bool {
+			if ctx.Err() != nil {
 				// Context was cancelled, exit the goroutine
-				return
-			default:
-				if currentTime.After(lastDay) {
-					return
-				}
+				return false
+			}
 
-				timeChan <- currentTime
-				currentTime = currentTime.Add(interval)
+			select {
+			case <-ctx.Done():
+				return false
+			case timeChan <- date:
+				return true
 			}
-		}
+		})
 	}()
 
-	// for {
-	// 	select {
-	// 	case time := <-timeChan:
-	// 		times = append(times, time)
-	// 	case <-quit:
-	// 		close(timeChan)
-	// 		close(quit)
-	// 		return times
-	// 	}
-	// }
-
 	return timeChan
 }
 
+// createTasksFromDates returns a channel with the task of every date received,
+// and a channel with the error of every date whose task cannot be created
+//
+// An occurrence which cannot be created is reported as a
+// *domain_errors.OccurrenceError carrying its time instead of being dropped,
+// so the caller can reject or log a partial expansion.
+// Both channels will be closed when datesChan is or the context is
+// cancelled; the caller must receive from both of them until then.
 func (t *Task) createTasksFromDates(ctx context.Context,
 	datesChan <-chan time.Time,
-	taskIDFactory TaskIDFactory) <-chan *Task {
+	taskIDFactory TaskIDFactory) (<-chan *Task, <-chan error) {
 	taskChan := make(chan *Task)
+	errChan := make(chan error)
 
 	go func() {
 		defer close(taskChan)
+		defer close(errChan)
 
 		for date := range datesChan {
-			select {
-			case <-ctx.Done():
+			if ctx.Err() != nil {
 				// Context was cancelled, exit the goroutine
 				return
-			default:
-				taskID := taskIDFactory.CreateTaskID(t.id.primaryId.String())
-
-				task, err := NewTask(
-					taskID,
-					t.title, t.description,
-					t.repeating, t.repeatingInterval,
-					t.dayOfWeek, date)
-				if err != nil {
-					// TODO: handle error
-					continue
+			}
+
+			task, err := t.occurrenceTask(date, taskIDFactory)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case errChan <- err:
 				}
+				continue
+			}
 
-				taskChan <- task
+			select {
+			case <-ctx.Done():
+				return
+			case taskChan <- task:
 			}
 		}
 	}()
 
-	return taskChan
+	return taskChan, errChan
+}
+
+// sortedOverrides returns the overrides of the series sorted by occurrence time
+func (t *Task) sortedOverrides() []*Task {
+	overrides := make([]*Task, 0, len(t.overrides))
+	for _, override := range t.overrides {
+		overrides = append(overrides, override)
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].GetOccurrenceTime().Before(overrides[j].GetOccurrenceTime())
+	})
+
+	return overrides
+}
+
+// clone returns a copy of the task which can be mutated without affecting the task
+func (t *Task) clone() *Task {
+	c := *t
+	c.exDates = maps.Clone(t.exDates)
+	c.rDates = slices.Clone(t.rDates)
+	c.overrides = maps.Clone(t.overrides)
+
+	return &c
+}
+
+// occurrence creates a task for one occurrence of the series at the given date
+func (t *Task) occurrence(taskID *TaskID, date time.Time) (*Task, error) {
+	task := &Task{
+		id:                taskID,
+		title:             t.title,
+		repeating:         t.repeating,
+		repeatingInterval: t.repeatingInterval,
+		recurrence:        t.recurrence,
+		description:       t.description,
+		time:              date,
+		duration:          t.duration,
+		allDay:            t.allDay,
+		priority:          t.priority,
+		occurrenceTime:    date,
+	}
+
+	if err := task.Validate(); err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTask, err)
+	}
+
+	return task, nil
 }
@@ -1,9 +1,15 @@
 package domain
 
 import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
 )
 
 // TaskID is the unique identifier for a task.
@@ -26,7 +32,7 @@ type TaskID struct {
 	original bool
 }
 
-func (ti *TaskID) String() string {
+func (ti TaskID) String() string {
 	var taskType string
 
 	if ti.original {
@@ -38,8 +44,61 @@ func (ti *TaskID) String() string {
 	return fmt.Sprintf("%s-%s-%s", ti.primaryId, ti.secondaryId.String(), taskType)
 }
 
+// compare returns -1, 0 or +1 as the ID is ordered before, with or after other
+//
+// IDs are ordered as their String forms are, a nil ID first.
+func (ti *TaskID) compare(other *TaskID) int {
+	switch {
+	case ti == nil && other == nil:
+		return 0
+	case ti == nil:
+		return -1
+	case other == nil:
+		return 1
+	}
+
+	if c := bytes.Compare(ti.primaryId[:], other.primaryId[:]); c != 0 {
+		return c
+	}
+
+	if c := bytes.Compare(ti.secondaryId[:], other.secondaryId[:]); c != 0 {
+		return c
+	}
+
+	// A copy is ordered before the original
+	switch {
+	case ti.original == other.original:
+		return 0
+	case ti.original:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// GetPrimaryID returns the identifier of the original task
+func (ti TaskID) GetPrimaryID() uuid.UUID {
+	return ti.primaryId
+}
+
+// GetSecondaryID returns the identifier of the task itself
+func (ti TaskID) GetSecondaryID() uuid.UUID {
+	return ti.secondaryId
+}
+
+// IsOriginal returns true if the task is the original and not a copy
+func (ti TaskID) IsOriginal() bool {
+	return ti.original
+}
+
+// NewTaskID creates a random original TaskID
 func NewTaskID() *TaskID {
-	id := uuid.New()
+	return NewTaskIDFrom(defaultIDSource)
+}
+
+// NewTaskIDFrom creates an original TaskID with an identifier from the source
+func NewTaskIDFrom(source IDSource) *TaskID {
+	id := source.NewID()
 	return &TaskID{
 		primaryId:   id,
 		secondaryId: id,
@@ -47,51 +106,227 @@ func NewTaskID() *TaskID {
 	}
 }
 
+// RehydrateTaskID recreates a persisted TaskID from its parts
+//
+// If the parts don't form an original or a copy TaskID, RehydrateTaskID
+// returns domain_errors.ErrInvalidTaskID.
+func RehydrateTaskID(primaryId, secondaryId uuid.UUID, original bool) (*TaskID, error) {
+	isOriginal := original && secondaryId == primaryId
+	isCopy := !original && secondaryId != primaryId
+
+	if primaryId == uuid.Nil || secondaryId == uuid.Nil || (!isOriginal && !isCopy) {
+		return nil, domain_errors.ErrInvalidTaskID
+	}
+
+	return &TaskID{
+		primaryId:   primaryId,
+		secondaryId: secondaryId,
+		original:    original,
+	}, nil
+}
+
+// ParseTaskID parses a TaskID from its String form, <primaryId>-<secondaryId>-original|copy
+//
+// If the string is not a TaskID, ParseTaskID returns domain_errors.ErrInvalidTaskID
+// joined with the details.
+func ParseTaskID(s string) (*TaskID, error) {
+	const uuidLength = 36
+
+	if len(s) < 2*uuidLength+2 || s[uuidLength] != '-' || s[2*uuidLength+1] != '-' {
+		return nil, errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("malformed task ID %q", s))
+	}
+
+	primaryId, err := uuid.Parse(s[:uuidLength])
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("primaryId: %w", err))
+	}
+
+	secondaryId, err := uuid.Parse(s[uuidLength+1 : 2*uuidLength+1])
+	if err != nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("secondaryId: %w", err))
+	}
+
+	var original bool
+	switch taskType := s[2*uuidLength+2:]; taskType {
+	case "original":
+		original = true
+	case "copy":
+	default:
+		return nil, errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("unknown task type %q", taskType))
+	}
+
+	id, err := RehydrateTaskID(primaryId, secondaryId, original)
+	if err != nil {
+		return nil, errors.Join(err, fmt.Errorf("inconsistent task ID %q", s))
+	}
+
+	return id, nil
+}
+
+// MarshalText encodes the TaskID as its String form
+func (ti TaskID) MarshalText() ([]byte, error) {
+	return []byte(ti.String()), nil
+}
+
+// UnmarshalText decodes a TaskID from its String form
+//
+// If the text is not a TaskID, UnmarshalText returns domain_errors.ErrInvalidTaskID.
+func (ti *TaskID) UnmarshalText(text []byte) error {
+	id, err := ParseTaskID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*ti = *id
+
+	return nil
+}
+
+// taskIDBinaryLength is the length of a TaskID in binary form: both
+// identifiers followed by 1 for an original or 0 for a copy
+const taskIDBinaryLength = 2*16 + 1
+
+// MarshalBinary encodes the TaskID in its binary form
+func (ti TaskID) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, taskIDBinaryLength)
+	data = append(data, ti.primaryId[:]...)
+	data = append(data, ti.secondaryId[:]...)
+
+	if ti.original {
+		return append(data, 1), nil
+	}
+
+	return append(data, 0), nil
+}
+
+// UnmarshalBinary decodes a TaskID from its binary form
+//
+// If the data is not a TaskID, UnmarshalBinary returns domain_errors.ErrInvalidTaskID.
+func (ti *TaskID) UnmarshalBinary(data []byte) error {
+	if len(data) != taskIDBinaryLength || data[32] > 1 {
+		return errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("malformed binary task ID of %d bytes", len(data)))
+	}
+
+	id, err := RehydrateTaskID(uuid.UUID(data[:16]), uuid.UUID(data[16:32]), data[32] == 1)
+	if err != nil {
+		return err
+	}
+
+	*ti = *id
+
+	return nil
+}
+
+// Scan reads the TaskID from a database value in its String form
+//
+// If the value is NULL or not a TaskID, Scan returns domain_errors.ErrInvalidTaskID.
+func (ti *TaskID) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return ti.UnmarshalText([]byte(strings.TrimSpace(v)))
+	case []byte:
+		return ti.UnmarshalText([]byte(strings.TrimSpace(string(v))))
+	case nil:
+		return errors.Join(domain_errors.ErrInvalidTaskID, errors.New("NULL task ID"))
+	default:
+		return errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("cannot scan %T", src))
+	}
+}
+
+// Value returns the TaskID in its String form as a database value
+func (ti TaskID) Value() (driver.Value, error) {
+	return ti.String(), nil
+}
+
 // TaskIDFactory is the abstract factory interface for creating TaskID instances
+//
+// CreateTaskID returns domain_errors.ErrInvalidTaskID when the identifier is
+// empty or not a UUID.
 type TaskIDFactory interface {
-	CreateTaskID(identifier string) *TaskID
+	CreateTaskID(identifier string) (*TaskID, error)
 }
 
 // OriginalTaskIDFactory is a concrete factory implementation for creating original TaskID instances
 type OriginalTaskIDFactory struct{}
 
 // CreateTaskID creates an original TaskID instance
-func (f *OriginalTaskIDFactory) CreateTaskID(identifier string) *TaskID {
-	if identifier == "" {
-		return nil
-	}
-
-	parsedIdentifier, err := uuid.Parse(identifier)
-
+func (f *OriginalTaskIDFactory) CreateTaskID(identifier string) (*TaskID, error) {
+	parsedIdentifier, err := parseIdentifier(identifier)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	return &TaskID{
 		primaryId:   parsedIdentifier,
 		secondaryId: parsedIdentifier,
 		original:    true,
-	}
+	}, nil
+}
+
+// OccurrenceTaskIDFactory is implemented by the factories which create the
+// TaskID of a series' occurrence from the occurrence time
+type OccurrenceTaskIDFactory interface {
+	CreateOccurrenceTaskID(primaryId uuid.UUID, occurrence time.Time) (*TaskID, error)
 }
 
 // CopyTaskIDFactory is a concrete factory implementation for creating copy TaskID instances
-type CopyTaskIDFactory struct{}
+//
+// The zero value takes its secondaryIds from a RandomIDSource.
+type CopyTaskIDFactory struct {
+	source IDSource
+}
+
+// NewCopyTaskIDFactory creates a CopyTaskIDFactory taking its secondaryIds from the source
+//
+// If the source is nil, the secondaryIds are random.
+func NewCopyTaskIDFactory(source IDSource) *CopyTaskIDFactory {
+	return &CopyTaskIDFactory{source: source}
+}
 
 // CreateTaskID creates a copy TaskID instance
-func (f *CopyTaskIDFactory) CreateTaskID(identifier string) *TaskID {
-	if identifier == "" {
-		return nil
+func (f *CopyTaskIDFactory) CreateTaskID(identifier string) (*TaskID, error) {
+	parsedIdentifier, err := parseIdentifier(identifier)
+	if err != nil {
+		return nil, err
 	}
 
-	parsedIdentifier, err := uuid.Parse(identifier)
+	return f.CreateOccurrenceTaskID(parsedIdentifier, time.Time{})
+}
 
-	if err != nil {
-		return nil
+// CreateOccurrenceTaskID creates the copy TaskID of the series' occurrence at the given time
+//
+// If the primaryId is nil, CreateOccurrenceTaskID returns domain_errors.ErrInvalidTaskID.
+func (f *CopyTaskIDFactory) CreateOccurrenceTaskID(primaryId uuid.UUID, occurrence time.Time) (*TaskID, error) {
+	if primaryId == uuid.Nil {
+		return nil, errors.Join(domain_errors.ErrInvalidTaskID, errors.New("nil identifier"))
+	}
+
+	source := f.source
+	if source == nil {
+		source = defaultIDSource
 	}
 
 	return &TaskID{
-		primaryId:   parsedIdentifier,
-		secondaryId: uuid.New(),
+		primaryId:   primaryId,
+		secondaryId: source.NewOccurrenceID(primaryId, occurrence),
 		original:    false,
+	}, nil
+}
+
+// parseIdentifier parses the primaryId given to a TaskIDFactory
+func parseIdentifier(identifier string) (uuid.UUID, error) {
+	if identifier == "" {
+		return uuid.Nil, errors.Join(domain_errors.ErrInvalidTaskID, errors.New("empty identifier"))
 	}
+
+	parsedIdentifier, err := uuid.Parse(identifier)
+	if err != nil {
+		return uuid.Nil, errors.Join(domain_errors.ErrInvalidTaskID, fmt.Errorf("identifier %q: %w", identifier, err))
+	}
+
+	if parsedIdentifier == uuid.Nil {
+		return uuid.Nil, errors.Join(domain_errors.ErrInvalidTaskID, errors.New("nil identifier"))
+	}
+
+	return parsedIdentifier, nil
 }
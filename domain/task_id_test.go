@@ -0,0 +1,329 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ encoding.TextMarshaler     = (*TaskID)(nil)
+	_ encoding.TextUnmarshaler   = (*TaskID)(nil)
+	_ encoding.BinaryMarshaler   = (*TaskID)(nil)
+	_ encoding.BinaryUnmarshaler = (*TaskID)(nil)
+	_ sql.Scanner                = (*TaskID)(nil)
+	_ driver.Valuer              = (*TaskID)(nil)
+
+	// Plain values encode as well
+	_ fmt.Stringer             = TaskID{}
+	_ encoding.TextMarshaler   = TaskID{}
+	_ encoding.BinaryMarshaler = TaskID{}
+	_ driver.Valuer            = TaskID{}
+	_ json.Marshaler           = TaskID{}
+)
+
+// newTestCopyID creates a copy TaskID sharing the primaryId of id
+func newTestCopyID(t *testing.T, id *TaskID) *TaskID {
+	t.Helper()
+
+	copied, err := (&CopyTaskIDFactory{}).CreateTaskID(id.primaryId.String())
+	assert.NoError(t, err)
+
+	return copied
+}
+
+func TestTaskIDFactory_CreateTaskID(t *testing.T) {
+	identifier := uuid.New()
+
+	tests := []struct {
+		name         string
+		factory      TaskIDFactory
+		identifier   string
+		wantOriginal bool
+		wantErr      bool
+	}{
+		{
+			name:         "Original",
+			factory:      &OriginalTaskIDFactory{},
+			identifier:   identifier.String(),
+			wantOriginal: true,
+		},
+		{
+			name:       "Copy",
+			factory:    &CopyTaskIDFactory{},
+			identifier: identifier.String(),
+		},
+		{
+			name:    "Original from an empty identifier",
+			factory: &OriginalTaskIDFactory{},
+			wantErr: true,
+		},
+		{
+			name:    "Copy from an empty identifier",
+			factory: &CopyTaskIDFactory{},
+			wantErr: true,
+		},
+		{
+			name:       "Original from an unparsable identifier",
+			factory:    &OriginalTaskIDFactory{},
+			identifier: "not-a-uuid",
+			wantErr:    true,
+		},
+		{
+			name:       "Copy from an unparsable identifier",
+			factory:    &CopyTaskIDFactory{},
+			identifier: "not-a-uuid",
+			wantErr:    true,
+		},
+		{
+			name:       "Copy from the nil UUID",
+			factory:    &CopyTaskIDFactory{},
+			identifier: uuid.Nil.String(),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.factory.CreateTaskID(tt.identifier)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+				assert.Nil(t, got)
+
+				// The error reaches NewTask instead of a nil ID panicking in Validate
+				_, err = NewTask(got, "title", "description", false, 0, time.Now())
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, identifier, got.GetPrimaryID())
+			assert.Equal(t, tt.wantOriginal, got.IsOriginal())
+			assert.Equal(t, tt.wantOriginal, got.GetSecondaryID() == identifier)
+		})
+	}
+}
+
+func TestNewTaskIDFrom(t *testing.T) {
+	want := uuid.New()
+
+	id := NewTaskIDFrom(&sequenceIDSource{ids: []uuid.UUID{want}})
+	assert.Equal(t, want, id.GetPrimaryID())
+	assert.Equal(t, want, id.GetSecondaryID())
+	assert.True(t, id.IsOriginal())
+}
+
+func TestCopyTaskIDFactory_CreateOccurrenceTaskID(t *testing.T) {
+	primaryId := uuid.New()
+	occurrence := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	factory := NewCopyTaskIDFactory(NewDeterministicIDSource(nil))
+
+	id, err := factory.CreateOccurrenceTaskID(primaryId, occurrence)
+	assert.NoError(t, err)
+	assert.Equal(t, primaryId, id.GetPrimaryID())
+	assert.False(t, id.IsOriginal())
+
+	again, err := NewCopyTaskIDFactory(NewDeterministicIDSource(nil)).CreateOccurrenceTaskID(primaryId, occurrence)
+	assert.NoError(t, err)
+	assert.Equal(t, id, again)
+
+	// The zero value stays random
+	random, err := (&CopyTaskIDFactory{}).CreateOccurrenceTaskID(primaryId, occurrence)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id.GetSecondaryID(), random.GetSecondaryID())
+
+	_, err = factory.CreateOccurrenceTaskID(uuid.Nil, occurrence)
+	assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+}
+
+func TestParseTaskID(t *testing.T) {
+	original := NewTaskID()
+	copied := newTestCopyID(t, original)
+	primary := original.primaryId.String()
+
+	tests := []struct {
+		name    string
+		s       string
+		want    *TaskID
+		wantErr bool
+	}{
+		{
+			name: "Original",
+			s:    original.String(),
+			want: original,
+		},
+		{
+			name: "Copy",
+			s:    copied.String(),
+			want: copied,
+		},
+		{
+			name:    "Bare UUID",
+			s:       primary,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid secondaryId",
+			s:       primary + "-" + "zzzzzzzz-zzzz-zzzz-zzzz-zzzzzzzzzzzz" + "-copy",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown task type",
+			s:       primary + "-" + primary + "-master",
+			wantErr: true,
+		},
+		{
+			name:    "Copy sharing the primaryId",
+			s:       primary + "-" + primary + "-copy",
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaskID(tt.s)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+				// The error carries more than the sentinel
+				assert.NotEqual(t, domain_errors.ErrInvalidTaskID.Error(), err.Error())
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.s, got.String())
+		})
+	}
+}
+
+func TestTaskID_text(t *testing.T) {
+	id := NewTaskID()
+
+	text, err := id.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), string(text))
+
+	var got TaskID
+	assert.NoError(t, got.UnmarshalText(text))
+	assert.Equal(t, *id, got)
+
+	assert.ErrorIs(t, got.UnmarshalText([]byte("invalid")), domain_errors.ErrInvalidTaskID)
+}
+
+func TestTaskID_binary(t *testing.T) {
+	original := NewTaskID()
+	copied := newTestCopyID(t, original)
+
+	for _, id := range []*TaskID{original, copied} {
+		data, err := id.MarshalBinary()
+		assert.NoError(t, err)
+		assert.Len(t, data, taskIDBinaryLength)
+
+		var got TaskID
+		assert.NoError(t, got.UnmarshalBinary(data))
+		assert.Equal(t, *id, got)
+	}
+
+	data, err := original.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got TaskID
+	assert.ErrorIs(t, got.UnmarshalBinary(data[:20]), domain_errors.ErrInvalidTaskID)
+
+	data[32] = 0
+	assert.ErrorIs(t, got.UnmarshalBinary(data), domain_errors.ErrInvalidTaskID)
+
+	data[32] = 2
+	assert.ErrorIs(t, got.UnmarshalBinary(data), domain_errors.ErrInvalidTaskID)
+}
+
+func TestTaskID_sql(t *testing.T) {
+	id := NewTaskID()
+
+	value, err := id.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), value)
+
+	tests := []struct {
+		name    string
+		src     any
+		wantErr bool
+	}{
+		{
+			name: "String",
+			src:  id.String(),
+		},
+		{
+			name: "Bytes",
+			src:  []byte(id.String()),
+		},
+		{
+			name:    "NULL",
+			src:     nil,
+			wantErr: true,
+		},
+		{
+			name:    "Other type",
+			src:     uuid.New(),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TaskID
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, *id, got)
+		})
+	}
+}
+
+func TestTaskID_value(t *testing.T) {
+	id := NewTaskID()
+
+	// A TaskID held by value, as in a map or a struct field
+	type record struct {
+		ID  TaskID  `json:"id"`
+		Ptr *TaskID `json:"ptr"`
+	}
+
+	data, err := json.Marshal(record{ID: *id})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": "`+id.String()+`", "ptr": null}`, string(data))
+
+	var got record
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, *id, got.ID)
+
+	assert.Equal(t, id.String(), fmt.Sprint(*id))
+	assert.Equal(t, id.String(), fmt.Sprint(map[string]TaskID{"id": *id}["id"]))
+
+	value, err := driver.DefaultParameterConverter.ConvertValue(*id)
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), value)
+
+	// A nil *TaskID is a NULL rather than a panic
+	value, err = driver.DefaultParameterConverter.ConvertValue((*TaskID)(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+}
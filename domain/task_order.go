@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"cmp"
+	"time"
+)
+
+// CompareTasks returns -1, 0 or +1 as a is ordered before, with or after b
+//
+// Tasks are ordered by time, then by priority, the undefined one last, then
+// by ID. It is a total order: two tasks are ordered the same only when they
+// have the same ID, like a task and its updated version. Days, range queries
+// and the lists of tasks a calendar returns follow this order; it can be
+// passed to slices.SortFunc.
+func CompareTasks(a, b *Task) int {
+	return compareOrder(a.time, a.priority, a.id, b.time, b.priority, b.id)
+}
+
+// compareOrder returns -1, 0 or +1 as the position of a task, given by its
+// time, priority and ID, is before, at or after the position of another
+func compareOrder(aTime time.Time, aPriority int, aId *TaskID, bTime time.Time, bPriority int, bId *TaskID) int {
+	if c := aTime.Compare(bTime); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(priorityRank(aPriority), priorityRank(bPriority)); c != 0 {
+		return c
+	}
+
+	return aId.compare(bId)
+}
+
+// priorityRank returns the rank of the priority, the undefined priority ranking after the lowest
+func priorityRank(priority int) int {
+	if priority == 0 {
+		return 10
+	}
+
+	return priority
+}
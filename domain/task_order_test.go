@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sameTimeTasks returns n tasks sharing a few times and priorities, so that
+// most of them are ordered by their priority or ID
+func sameTimeTasks(rnd *rand.Rand, n int) []*Task {
+	start := time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC)
+	priorities := []int{0, 1, 2, 9}
+
+	newID := func() uuid.UUID {
+		var id uuid.UUID
+		rnd.Read(id[:])
+		return id
+	}
+
+	tasks := make([]*Task, n)
+	for i := range tasks {
+		id := &TaskID{primaryId: newID(), original: true}
+		id.secondaryId = id.primaryId
+		if rnd.Intn(2) == 0 {
+			id.secondaryId, id.original = newID(), false
+		}
+
+		tasks[i] = &Task{
+			id:       id,
+			title:    "title",
+			time:     start.Add(time.Duration(rnd.Intn(3)) * 30 * time.Minute),
+			priority: priorities[rnd.Intn(len(priorities))],
+		}
+	}
+
+	return tasks
+}
+
+// sign returns -1, 0 or +1 as n is negative, zero or positive
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func TestCompareTasks(t *testing.T) {
+	start := time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC)
+	first := &TaskID{primaryId: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	second := &TaskID{primaryId: uuid.MustParse("00000000-0000-0000-0000-000000000002")}
+
+	tests := []struct {
+		name string
+		a, b *Task
+		want int
+	}{
+		{
+			name: "Earlier time",
+			a:    &Task{id: second, time: start, priority: 9},
+			b:    &Task{id: first, time: start.Add(time.Minute), priority: 1},
+			want: -1,
+		},
+		{
+			name: "Higher priority",
+			a:    &Task{id: second, time: start, priority: 1},
+			b:    &Task{id: first, time: start, priority: 2},
+			want: -1,
+		},
+		{
+			name: "Undefined priority last",
+			a:    &Task{id: first, time: start, priority: 0},
+			b:    &Task{id: second, time: start, priority: 9},
+			want: 1,
+		},
+		{
+			name: "Lower ID",
+			a:    &Task{id: first, time: start, priority: 3},
+			b:    &Task{id: second, time: start, priority: 3},
+			want: -1,
+		},
+		{
+			name: "Same ID",
+			a:    &Task{id: first, time: start},
+			b:    &Task{id: first, time: start, title: "updated"},
+			want: 0,
+		},
+		{
+			name: "Without ID",
+			a:    &Task{time: start},
+			b:    &Task{id: first, time: start},
+			want: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CompareTasks(tt.a, tt.b))
+			assert.Equal(t, -tt.want, CompareTasks(tt.b, tt.a))
+		})
+	}
+}
+
+func TestCompareTasks_properties(t *testing.T) {
+	// CompareTasks is a total order over tasks with distinct IDs
+	totalOrder := func(seed int64) bool {
+		tasks := sameTimeTasks(rand.New(rand.NewSource(seed)), 12)
+
+		for _, a := range tasks {
+			if CompareTasks(a, a) != 0 {
+				return false
+			}
+
+			for _, b := range tasks {
+				ab := CompareTasks(a, b)
+				if ab != -CompareTasks(b, a) || (ab == 0) != (a == b) {
+					return false
+				}
+
+				for _, c := range tasks {
+					if ab <= 0 && CompareTasks(b, c) <= 0 && CompareTasks(a, c) > 0 {
+						return false
+					}
+				}
+			}
+		}
+
+		return true
+	}
+
+	// The position of a cursor and the IDs' String forms agree with the order
+	consistent := func(seed int64) bool {
+		tasks := sameTimeTasks(rand.New(rand.NewSource(seed)), 12)
+
+		for _, a := range tasks {
+			cursor, err := ParseTaskCursor(newTaskCursor(a).String())
+			if err != nil {
+				return false
+			}
+
+			for _, b := range tasks {
+				if cursor.compare(b) != CompareTasks(b, a) {
+					return false
+				}
+
+				if sign(a.id.compare(b.id)) != sign(strings.Compare(a.id.String(), b.id.String())) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	tests := []struct {
+		name     string
+		property func(seed int64) bool
+	}{
+		{name: "Total order", property: totalOrder},
+		{name: "Consistent with cursors and IDs", property: consistent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := quick.Check(tt.property, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
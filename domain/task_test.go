@@ -31,7 +31,6 @@ func TestTask_Validate(t *testing.T) {
 				id:          taskId,
 				title:       "",
 				description: "description",
-				dayOfWeek:   1,
 				time:        time.Now(),
 			},
 			error: domain_errors.ErrTitleRequired,
@@ -42,21 +41,19 @@ func TestTask_Validate(t *testing.T) {
 				id:          taskId,
 				title:       "title",
 				description: "",
-				dayOfWeek:   1,
 				time:        time.Now(),
 			},
 			error: domain_errors.ErrDescriptionRequired,
 		},
 		{
-			name: "Zero dayOfWeek",
+			name: "Sunday task",
 			task: &Task{
 				id:          taskId,
 				title:       "title",
 				description: "description",
-				dayOfWeek:   0,
-				time:        time.Now(),
+				time:        time.Date(2024, time.January, 7, 9, 0, 0, 0, time.UTC),
 			},
-			error: domain_errors.ErrDayOfWeekRequired,
+			error: nil,
 		},
 		{
 			name: "Zero time",
@@ -64,18 +61,25 @@ func TestTask_Validate(t *testing.T) {
 				id:          taskId,
 				title:       "title",
 				description: "description",
-				dayOfWeek:   1,
 				time:        time.Time{},
 			},
 			error: domain_errors.ErrTimeRequired,
 		},
+		{
+			name: "Nil task ID",
+			task: &Task{
+				title:       "title",
+				description: "description",
+				time:        time.Now(),
+			},
+			error: domain_errors.ErrInvalidTaskID,
+		},
 		{
 			name: "Valid task",
 			task: &Task{
 				id:          taskId,
 				title:       "title",
 				description: "description",
-				dayOfWeek:   1,
 				time:        time.Now(),
 			},
 			error: nil,
@@ -106,8 +110,8 @@ func TestNewTask(t *testing.T) {
 		description       string
 		repeating         bool
 		repeatingInterval time.Duration
-		dayOfWeek         time.Weekday
 		time              time.Time
+		dayOfWeek         time.Weekday
 		error             error
 	}{
 		{
@@ -117,7 +121,6 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
 			time:              time.Now(),
 			error:             domain_errors.ErrTitleRequired,
 		},
@@ -128,20 +131,31 @@ func TestNewTask(t *testing.T) {
 			description:       "",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
 			time:              time.Now(),
 			error:             domain_errors.ErrDescriptionRequired,
 		},
 		{
 			id:                taskId,
-			name:              "Zero dayOfWeek",
+			name:              "Sunday task",
 			title:             "title",
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         0,
-			time:              time.Now(),
-			error:             domain_errors.ErrDayOfWeekRequired,
+			time:              time.Date(2024, time.January, 7, 9, 0, 0, 0, time.UTC),
+			dayOfWeek:         time.Sunday,
+			error:             nil,
+		},
+		{
+			id:                taskId,
+			name:              "Day of the week in the task's time zone",
+			title:             "title",
+			description:       "description",
+			repeating:         false,
+			repeatingInterval: time.Duration(0),
+			// Monday 01:00 in Auckland is still Sunday in UTC
+			time:      time.Date(2024, time.January, 8, 1, 0, 0, 0, time.FixedZone("NZDT", 13*60*60)),
+			dayOfWeek: time.Monday,
+			error:     nil,
 		},
 		{
 			id:                taskId,
@@ -150,7 +164,6 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
 			time:              time.Time{},
 			error:             domain_errors.ErrTimeRequired,
 		},
@@ -161,8 +174,8 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         true,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
-			time:              time.Now(),
+			time:              time.Date(2024, time.January, 9, 9, 0, 0, 0, time.UTC),
+			dayOfWeek:         time.Tuesday,
 			error:             nil,
 		},
 		{
@@ -172,8 +185,8 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
-			time:              time.Now(),
+			time:              time.Date(2024, time.January, 9, 9, 0, 0, 0, time.UTC),
+			dayOfWeek:         time.Tuesday,
 			error:             nil,
 		},
 		{
@@ -187,7 +200,6 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
 			time:              time.Now(),
 			error:             domain_errors.ErrInvalidTaskID,
 		},
@@ -202,7 +214,16 @@ func TestNewTask(t *testing.T) {
 			description:       "description",
 			repeating:         false,
 			repeatingInterval: time.Duration(0),
-			dayOfWeek:         1,
+			time:              time.Now(),
+			error:             domain_errors.ErrInvalidTaskID,
+		},
+		{
+			id:                nil,
+			name:              "Nil task ID",
+			title:             "title",
+			description:       "description",
+			repeating:         false,
+			repeatingInterval: time.Duration(0),
 			time:              time.Now(),
 			error:             domain_errors.ErrInvalidTaskID,
 		},
@@ -215,7 +236,7 @@ func TestNewTask(t *testing.T) {
 				tc.id,
 				tc.title, tc.description,
 				tc.repeating, tc.repeatingInterval,
-				tc.dayOfWeek, tc.time)
+				tc.time)
 			assert.ErrorIs(t, err, tc.error)
 
 			if err != nil {
@@ -272,6 +293,92 @@ func TestTask_Complete(t *testing.T) {
 	}
 }
 
+// occurrencesOf returns the occurrences of the task within [from, to)
+func occurrencesOf(task *Task, from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	task.occurrencesBetween(from, to, func(date time.Time) bool {
+		occurrences = append(occurrences, date)
+		return true
+	})
+
+	return occurrences
+}
+
+type MockTaskIDFactory struct {
+	mock.Mock
+}
+
+func (m *MockTaskIDFactory) CreateTaskID(primaryID string) (*TaskID, error) {
+	args := m.Called(primaryID)
+	return args.Get(0).(*TaskID), args.Error(1)
+}
+
+func TestTask_occurrenceTask(t *testing.T) {
+	ogTaskID := uuid.New()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	date := start.AddDate(0, 0, 1)
+
+	newSeries := func() *Task {
+		return &Task{
+			id: &TaskID{
+				primaryId:   ogTaskID,
+				secondaryId: ogTaskID,
+				original:    true},
+			title:             "Task1",
+			description:       "Description1",
+			time:              start,
+			repeating:         true,
+			repeatingInterval: time.Duration(24 * time.Hour),
+		}
+	}
+
+	copyTaskID := &TaskID{
+		primaryId:   ogTaskID,
+		secondaryId: uuid.New(),
+		original:    false,
+	}
+
+	t.Run("Copy of the occurrence", func(t *testing.T) {
+		factory := &MockTaskIDFactory{}
+		factory.On("CreateTaskID", ogTaskID.String()).Return(copyTaskID, nil)
+
+		task, err := newSeries().occurrenceTask(date, factory)
+		assert.NoError(t, err)
+		assert.Equal(t, copyTaskID, task.id)
+		assert.Equal(t, date, task.GetTime())
+		assert.Equal(t, date, task.GetOccurrenceTime())
+		assert.Equal(t, "Task1", task.GetTitle())
+		factory.AssertExpectations(t)
+	})
+
+	t.Run("Factory returns an error", func(t *testing.T) {
+		factory := &MockTaskIDFactory{}
+		factory.On("CreateTaskID", mock.Anything).Return((*TaskID)(nil), domain_errors.ErrInvalidTaskID)
+
+		task, err := newSeries().occurrenceTask(date, factory)
+		assert.Nil(t, task)
+
+		var occurrenceErr *domain_errors.OccurrenceError
+		assert.ErrorAs(t, err, &occurrenceErr)
+		assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+		assert.Equal(t, date, occurrenceErr.Occurrence)
+	})
+
+	t.Run("Overridden occurrence", func(t *testing.T) {
+		series := newSeries()
+		override, err := series.occurrence(copyTaskID, date.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.NoError(t, series.OverrideOccurrence(date, override))
+		stored, _ := series.GetOverride(date)
+
+		factory := &MockTaskIDFactory{}
+		task, err := series.occurrenceTask(date, factory)
+		assert.NoError(t, err)
+		assert.Same(t, stored, task)
+		factory.AssertNotCalled(t, "CreateTaskID", mock.Anything)
+	})
+}
+
 func TestSearchRepetition(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -287,7 +394,7 @@ func TestSearchRepetition(t *testing.T) {
 			time:       time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
 			interval:   24 * time.Hour,
 			cancel:     false,
-			wantLength: 31,
+			wantLength: 365,
 		},
 		{
 			name:       "Non-repeating task",
@@ -306,7 +413,6 @@ func TestSearchRepetition(t *testing.T) {
 			wantLength: 0,
 		},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			task := &Task{
@@ -334,23 +440,12 @@ func TestSearchRepetition(t *testing.T) {
 	}
 }
 
-type MockTaskIDFactory struct {
-	mock.Mock
-}
-
-func (m *MockTaskIDFactory) CreateTaskID(primaryID string) *TaskID {
-	args := m.Called(primaryID)
-	return args.Get(0).(*TaskID)
-}
-
 func TestCreateTasksFromDates(t *testing.T) {
-	// Setup
 	ogTaskID := uuid.New()
 	times := []time.Time{
 		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
 		time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
 	}
-
 	task := &Task{
 		id: &TaskID{
 			primaryId:   ogTaskID,
@@ -358,33 +453,39 @@ func TestCreateTasksFromDates(t *testing.T) {
 			original:    true},
 		title:             "Task1",
 		description:       "Description1",
-		time:              time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time:              times[0],
 		repeating:         true,
 		repeatingInterval: time.Duration(24 * time.Hour),
-		dayOfWeek:         1,
+	}
+	copyTaskID := &TaskID{
+		primaryId:   ogTaskID,
+		secondaryId: uuid.New(),
+		original:    false,
 	}
 
 	tests := []struct {
 		name          string
-		dates         []time.Time
-		taskIDFactory *MockTaskIDFactory
+		taskID        *TaskID
+		taskIDErr     error
 		expectedDates []time.Time
+		expectedErrs  []time.Time
 		cancelContext bool
 	}{
 		{
 			name:          "Create tasks from dates",
-			dates:         times,
-			taskIDFactory: &MockTaskIDFactory{},
+			taskID:        copyTaskID,
 			expectedDates: times,
-			cancelContext: false,
 		},
 		{
 			name:          "Context cancelled",
-			dates:         times,
-			taskIDFactory: &MockTaskIDFactory{},
-			expectedDates: nil,
+			taskID:        copyTaskID,
 			cancelContext: true,
 		},
+		{
+			name:         "Factory returns an error",
+			taskIDErr:    domain_errors.ErrInvalidTaskID,
+			expectedErrs: times,
+		},
 	}
 
 	for _, tt := range tests {
@@ -396,30 +497,593 @@ func TestCreateTasksFromDates(t *testing.T) {
 				cancel()
 			}
 
-			datesChan := make(chan time.Time, len(tt.dates))
-			for _, date := range tt.dates {
+			datesChan := make(chan time.Time, len(times))
+			for _, date := range times {
 				datesChan <- date
 			}
 			close(datesChan)
 
-			tt.taskIDFactory.On("CreateTaskID", mock.Anything).Return(&TaskID{
-				primaryId:   ogTaskID,
-				secondaryId: uuid.New(),
-				original:    false,
-			})
+			taskIDFactory := &MockTaskIDFactory{}
+			taskIDFactory.On("CreateTaskID", ogTaskID.String()).Return(tt.taskID, tt.taskIDErr)
 
-			tasksChan := task.createTasksFromDates(ctx, datesChan, tt.taskIDFactory)
+			tasksChan, errChan := task.createTasksFromDates(ctx, datesChan, taskIDFactory)
 
-			for newTask := range tasksChan {
-				assert.Contains(t, tt.expectedDates, newTask.GetTime())
+			var gotDates, gotErrs []time.Time
+			for tasksChan != nil || errChan != nil {
+				select {
+				case task, ok := <-tasksChan:
+					if !ok {
+						tasksChan = nil
+						continue
+					}
+					gotDates = append(gotDates, task.GetTime())
+				case err, ok := <-errChan:
+					if !ok {
+						errChan = nil
+						continue
+					}
+
+					var occurrenceErr *domain_errors.OccurrenceError
+					assert.ErrorAs(t, err, &occurrenceErr)
+					assert.ErrorIs(t, err, domain_errors.ErrInvalidTaskID)
+					gotErrs = append(gotErrs, occurrenceErr.Occurrence)
+				}
 			}
 
-			if tt.cancelContext {
-				assert.Empty(t, tasksChan)
-			} else {
-				tt.taskIDFactory.AssertExpectations(t)
+			assert.Equal(t, tt.expectedDates, gotDates)
+			assert.Equal(t, tt.expectedErrs, gotErrs)
+		})
+	}
+}
+
+func TestNewRecurringTask(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		rule  *RecurrenceRule
+		error error
+	}{
+		{
+			name: "Valid rule",
+			rule: rule,
+		},
+		{
+			name: "Non-repeating task",
+			rule: nil,
+		},
+		{
+			name:  "Invalid rule",
+			rule:  &RecurrenceRule{freq: Daily},
+			error: domain_errors.ErrInvalidRecurrenceRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := NewRecurringTask(
+				NewTaskID(),
+				"title", "description",
+				tt.rule,
+				time.Date(2024, time.January, 9, 9, 0, 0, 0, time.UTC))
+			assert.ErrorIs(t, err, tt.error)
+
+			if err != nil {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTask)
+				return
+			}
+
+			isRepeating, _ := task.IsRepeating()
+			assert.Equal(t, tt.rule != nil, isRepeating)
+			assert.Equal(t, tt.rule, task.GetRecurrenceRule())
+		})
+	}
+}
+
+func TestTask_occurrencesBetween_recurrenceRule(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO,WE,FR")
+	assert.NoError(t, err)
+
+	task := &Task{
+		time:       time.Date(2024, time.January, 1, 7, 0, 0, 0, time.UTC),
+		repeating:  true,
+		recurrence: rule,
+	}
+
+	var got []int
+	for _, occurrence := range occurrencesOf(task, task.GetTime(), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)) {
+		got = append(got, occurrence.Day())
+	}
+
+	assert.Equal(t, []int{1, 3, 5, 8, 10, 12, 15, 17, 19, 22, 24, 26, 29, 31}, got)
+}
+
+func TestNewWeeklyTask(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	monWedFri := []time.Weekday{time.Monday, time.Wednesday, time.Friday}
+
+	tests := []struct {
+		name     string
+		interval int
+		weekdays []time.Weekday
+		time     time.Time
+		wantTime time.Time
+		wantErr  error
+	}{
+		{
+			name:     "Start on one of the weekdays",
+			interval: 1,
+			weekdays: monWedFri,
+			time:     time.Date(2024, time.January, 3, 7, 0, 0, 0, time.UTC),
+			wantTime: time.Date(2024, time.January, 3, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Start moved to the next weekday",
+			interval: 1,
+			weekdays: monWedFri,
+			time:     time.Date(2024, time.January, 6, 7, 0, 0, 0, time.UTC),
+			wantTime: time.Date(2024, time.January, 8, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Start moved across daylight saving keeps its wall clock time",
+			interval: 1,
+			weekdays: monWedFri,
+			time:     time.Date(2024, time.March, 30, 7, 0, 0, 0, madrid),
+			wantTime: time.Date(2024, time.April, 1, 7, 0, 0, 0, madrid),
+		},
+		{
+			name:     "No weekdays",
+			interval: 1,
+			time:     time.Date(2024, time.January, 3, 7, 0, 0, 0, time.UTC),
+			wantErr:  domain_errors.ErrInvalidRecurrenceRule,
+		},
+		{
+			name:     "Zero time",
+			interval: 1,
+			weekdays: monWedFri,
+			wantErr:  domain_errors.ErrTimeRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := NewWeeklyTask(NewTaskID(), "title", "description", tt.interval, tt.weekdays, tt.time)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if err != nil {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTask)
+				return
+			}
+
+			assert.True(t, tt.wantTime.Equal(task.GetTime()), "got %s", task.GetTime())
+			assert.Equal(t, Weekly, task.GetRecurrenceRule().GetFrequency())
+		})
+	}
+}
+
+func TestTask_occurrencesBetween_weekdays(t *testing.T) {
+	// Every other week on Monday, Wednesday and Friday, from Sunday 2024-01-28
+	task, err := NewWeeklyTask(
+		NewTaskID(),
+		"title", "description",
+		2, []time.Weekday{time.Friday, time.Monday, time.Wednesday},
+		time.Date(2024, time.January, 28, 7, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	got := occurrencesOf(task, task.GetTime(), time.Date(2024, time.March, 9, 0, 0, 0, 0, time.UTC))
+
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 7, 0, 0, 0, time.UTC)
+	}
+	assert.Equal(t, []time.Time{
+		at(time.January, 29), at(time.January, 31), at(time.February, 2),
+		at(time.February, 12), at(time.February, 14), at(time.February, 16),
+		at(time.February, 26), at(time.February, 28), at(time.March, 1),
+	}, got)
+}
+
+func TestTask_occurrencesBetween(t *testing.T) {
+	start := time.Date(2024, time.January, 30, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		task       *Task
+		from       time.Time
+		to         time.Time
+		wantLength int
+	}{
+		{
+			name:       "Daily task across months",
+			task:       &Task{time: start, repeating: true, repeatingInterval: 24 * time.Hour},
+			from:       start,
+			to:         time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantLength: 31,
+		},
+		{
+			name:       "Daily task across years",
+			task:       &Task{time: start, repeating: true, repeatingInterval: 24 * time.Hour},
+			from:       time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+			to:         time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC),
+			wantLength: 2,
+		},
+		{
+			name:       "Non-repeating task within window",
+			task:       &Task{time: start},
+			from:       start,
+			to:         start.Add(time.Hour),
+			wantLength: 1,
+		},
+		{
+			name:       "Non-repeating task outside window",
+			task:       &Task{time: start},
+			from:       start.Add(time.Hour),
+			to:         start.Add(2 * time.Hour),
+			wantLength: 0,
+		},
+		{
+			name: "Open ended window stops at count",
+			task: &Task{
+				time:       start,
+				repeating:  true,
+				recurrence: &RecurrenceRule{freq: Daily, interval: 1, count: 45, wkst: time.Monday},
+			},
+			from:       start,
+			wantLength: 45,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, occurrencesOf(tt.task, tt.from, tt.to), tt.wantLength)
+		})
+	}
+
+	t.Run("Stops when yield returns false", func(t *testing.T) {
+		task := &Task{time: start, repeating: true, repeatingInterval: 24 * time.Hour}
+
+		calls := 0
+		task.occurrencesBetween(start, time.Time{}, func(time.Time) bool {
+			calls++
+			return calls < 3
+		})
+
+		assert.Equal(t, 3, calls)
+	})
+}
+
+func TestTask_defaultWindow_boundedRule(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=5")
+	assert.NoError(t, err)
+
+	task := &Task{
+		time:       time.Date(2024, time.January, 30, 9, 0, 0, 0, time.UTC),
+		repeating:  true,
+		recurrence: rule,
+	}
+
+	from, to := task.defaultWindow()
+	got := occurrencesOf(task, from, to)
+
+	assert.Len(t, got, 5)
+	assert.Equal(t, time.February, got[len(got)-1].Month())
+}
+
+func TestRehydrateTaskID(t *testing.T) {
+	primaryId := uuid.New()
+	secondaryId := uuid.New()
+
+	tests := []struct {
+		name        string
+		primaryId   uuid.UUID
+		secondaryId uuid.UUID
+		original    bool
+		wantErr     error
+	}{
+		{
+			name:        "Original",
+			primaryId:   primaryId,
+			secondaryId: primaryId,
+			original:    true,
+		},
+		{
+			name:        "Copy",
+			primaryId:   primaryId,
+			secondaryId: secondaryId,
+		},
+		{
+			name:        "Original with another secondaryId",
+			primaryId:   primaryId,
+			secondaryId: secondaryId,
+			original:    true,
+			wantErr:     domain_errors.ErrInvalidTaskID,
+		},
+		{
+			name:        "Copy sharing the primaryId",
+			primaryId:   primaryId,
+			secondaryId: primaryId,
+			wantErr:     domain_errors.ErrInvalidTaskID,
+		},
+		{
+			name:        "Nil primaryId",
+			secondaryId: secondaryId,
+			wantErr:     domain_errors.ErrInvalidTaskID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := RehydrateTaskID(tt.primaryId, tt.secondaryId, tt.original)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr != nil {
+				assert.Nil(t, id)
+				return
 			}
 
+			assert.Equal(t, tt.primaryId, id.GetPrimaryID())
+			assert.Equal(t, tt.secondaryId, id.GetSecondaryID())
+			assert.Equal(t, tt.original, id.IsOriginal())
+		})
+	}
+}
+
+func TestRehydrateTask(t *testing.T) {
+	taskTime := time.Date(2024, time.January, 9, 9, 0, 0, 0, time.UTC)
+	id := NewTaskID()
+
+	rule, err := ParseRecurrenceRule("FREQ=WEEKLY;COUNT=3")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		taskId        *TaskID
+		title         string
+		rule          *RecurrenceRule
+		completed     bool
+		wantRepeating bool
+		wantErr       error
+	}{
+		{
+			name:      "Completed task",
+			taskId:    id,
+			title:     "title",
+			completed: true,
+		},
+		{
+			name:          "Recurring task",
+			taskId:        id,
+			title:         "title",
+			rule:          rule,
+			wantRepeating: true,
+		},
+		{
+			name:    "Nil ID",
+			title:   "title",
+			wantErr: domain_errors.ErrInvalidTaskID,
+		},
+		{
+			name:    "Invalid task",
+			taskId:  id,
+			wantErr: domain_errors.ErrTitleRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := RehydrateTask(
+				tt.taskId,
+				tt.title, "description",
+				false, 0,
+				tt.rule,
+				tt.completed,
+				taskTime)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, domain_errors.ErrInvalidTask)
+				assert.Nil(t, task)
+				return
+			}
+
+			assert.Equal(t, *tt.taskId, task.GetID())
+			assert.Equal(t, tt.completed, task.IsCompleted())
+			assert.Equal(t, tt.rule, task.GetRecurrenceRule())
+
+			isRepeating, _ := task.IsRepeating()
+			assert.Equal(t, tt.wantRepeating, isRepeating)
+		})
+	}
+}
+
+func TestTask_CalculateNextRepeatingTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 30, 9, 0, 0, 0, paris)
+
+	tests := []struct {
+		name              string
+		repeating         bool
+		repeatingInterval time.Duration
+		want              time.Time
+	}{
+		{
+			name:              "Daily keeps its wall clock time across daylight saving",
+			repeating:         true,
+			repeatingInterval: 24 * time.Hour,
+			want:              time.Date(2024, time.March, 31, 9, 0, 0, 0, paris),
+		},
+		{
+			name:              "Hourly steps in elapsed time",
+			repeating:         true,
+			repeatingInterval: time.Hour,
+			want:              start.Add(time.Hour),
+		},
+		{
+			name: "Not repeating",
+			want: start,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := NewTask(NewTaskID(), "title", "description", tt.repeating, tt.repeatingInterval, start)
+			assert.NoError(t, err)
+
+			assert.True(t, tt.want.Equal(task.CalculateNextRepeatingTime()), "got %s", task.CalculateNextRepeatingTime())
+			assert.Equal(t, paris, task.GetTimeZone())
+		})
+	}
+}
+
+func TestTask_SetDuration(t *testing.T) {
+	start := time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		set     func(task *Task) error
+		wantEnd time.Time
+		wantErr error
+	}{
+		{
+			name:    "Duration",
+			set:     func(task *Task) error { return task.SetDuration(90 * time.Minute) },
+			wantEnd: start.Add(90 * time.Minute),
+		},
+		{
+			name:    "End time",
+			set:     func(task *Task) error { return task.SetEndTime(start.Add(26 * time.Hour)) },
+			wantEnd: start.Add(26 * time.Hour),
+		},
+		{
+			name:    "Zero duration",
+			set:     func(task *Task) error { return task.SetDuration(0) },
+			wantEnd: start,
+			wantErr: domain_errors.ErrInvalidEndTime,
+		},
+		{
+			name:    "End before start",
+			set:     func(task *Task) error { return task.SetEndTime(start.Add(-time.Hour)) },
+			wantEnd: start,
+			wantErr: domain_errors.ErrInvalidEndTime,
+		},
+		{
+			name:    "End at start",
+			set:     func(task *Task) error { return task.SetEndTime(start) },
+			wantEnd: start,
+			wantErr: domain_errors.ErrInvalidEndTime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newTestTask(t, start, false, 0)
+
+			assert.ErrorIs(t, tt.set(task), tt.wantErr)
+			assert.Equal(t, tt.wantEnd, task.GetEndTime())
+			assert.Equal(t, tt.wantEnd.Sub(start), task.GetDuration())
+			assert.False(t, task.IsAllDay())
+			assert.NoError(t, task.Validate())
+		})
+	}
+}
+
+func TestTask_SetPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority int
+		want     int
+		wantErr  error
+	}{
+		{name: "Highest", priority: 1, want: 1},
+		{name: "Lowest", priority: 9, want: 9},
+		{name: "Undefined", priority: 0, want: 0},
+		{name: "Negative", priority: -1, want: 3, wantErr: domain_errors.ErrInvalidPriority},
+		{name: "Beyond the lowest", priority: 10, want: 3, wantErr: domain_errors.ErrInvalidPriority},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newTestTask(t, time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC), false, 0)
+			assert.NoError(t, task.SetPriority(3))
+
+			assert.ErrorIs(t, task.SetPriority(tt.priority), tt.wantErr)
+			assert.Equal(t, tt.want, task.GetPriority())
+			assert.NoError(t, task.Validate())
+		})
+	}
+
+	task := newTestTask(t, time.Date(2024, time.January, 10, 9, 0, 0, 0, time.UTC), false, 0)
+	task.priority = 12
+	assert.ErrorIs(t, task.Validate(), domain_errors.ErrInvalidPriority)
+}
+
+func TestTask_SetAllDay(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	// The days span the spring daylight saving transition
+	task := newTestTask(t, time.Date(2024, time.March, 30, 15, 30, 0, 0, paris), false, 0)
+
+	assert.ErrorIs(t, task.SetAllDay(0), domain_errors.ErrInvalidEndTime)
+	assert.False(t, task.IsAllDay())
+
+	assert.NoError(t, task.SetAllDay(2))
+	assert.True(t, task.IsAllDay())
+	assert.Equal(t, time.Date(2024, time.March, 30, 0, 0, 0, 0, paris), task.GetTime())
+	assert.Equal(t, time.Date(2024, time.April, 1, 0, 0, 0, 0, paris), task.GetEndTime())
+	assert.Equal(t, 48*time.Hour, task.GetDuration())
+
+	// Occurrences last as long as their series
+	occurrence, err := task.occurrence(newTestCopyID(t, task.id), task.GetTime().AddDate(0, 0, 7))
+	assert.NoError(t, err)
+	assert.True(t, occurrence.IsAllDay())
+	assert.Equal(t, task.GetDuration(), occurrence.GetDuration())
+
+	// A timed duration ends the all-day task
+	assert.NoError(t, task.SetDuration(time.Hour))
+	assert.False(t, task.IsAllDay())
+}
+
+func TestTask_Validate_end(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		allDay   bool
+		wantErr  error
+	}{
+		{
+			name:     "Negative duration",
+			duration: -time.Hour,
+			wantErr:  domain_errors.ErrInvalidEndTime,
+		},
+		{
+			name:    "All-day without days",
+			allDay:  true,
+			wantErr: domain_errors.ErrInvalidEndTime,
+		},
+		{
+			name:     "All-day with part of a day",
+			duration: 36 * time.Hour,
+			allDay:   true,
+			wantErr:  domain_errors.ErrInvalidEndTime,
+		},
+		{
+			name:     "All-day",
+			duration: 48 * time.Hour,
+			allDay:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := newTestTask(t, time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC), false, 0)
+			task.duration, task.allDay = tt.duration, tt.allDay
+
+			assert.ErrorIs(t, task.Validate(), tt.wantErr)
 		})
 	}
 }
@@ -0,0 +1,293 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// component is a VEVENT or VTODO read into its task
+type component struct {
+	raw  *rawComponent
+	task *domain.Task
+	// primaryId is the primaryId the UID maps onto
+	primaryId uuid.UUID
+	// recurrenceID is the occurrence the component replaces, if any
+	recurrenceID time.Time
+	cancelled    bool
+	exDates      []time.Time
+	rDates       []time.Time
+}
+
+// build turns the components of an object into tasks and returns the original ones
+//
+// Components which cannot be turned into a task are reported and skipped.
+func (d *Decoder) build(raws []*rawComponent) []*domain.Task {
+	var tasks []*domain.Task
+	series := make(map[uuid.UUID]*domain.Task)
+	var occurrences []*component
+
+	for _, raw := range raws {
+		c, err := d.readProperties(raw)
+		if err != nil {
+			d.warn(InvalidComponent, raw.line, raw.name, err)
+			continue
+		}
+
+		if !c.recurrenceID.IsZero() {
+			occurrences = append(occurrences, c)
+			continue
+		}
+
+		if err := d.newSeries(c); err != nil {
+			d.warn(InvalidComponent, raw.line, raw.name, err)
+			continue
+		}
+
+		if _, exists := series[c.primaryId]; exists {
+			d.warn(InvalidComponent, raw.line, raw.name, errors.New("duplicate UID"))
+			continue
+		}
+
+		series[c.primaryId] = c.task
+		tasks = append(tasks, c.task)
+		d.origins[c.task] = raw
+	}
+
+	// Occurrences may come before their series
+	for _, c := range occurrences {
+		if err := d.applyOccurrence(c, series[c.primaryId]); err != nil {
+			d.warn(InvalidComponent, c.raw.line, c.raw.name, err)
+		}
+	}
+
+	return tasks
+}
+
+// readProperties reads the properties of the component
+//
+// Unsupported properties, and EXDATE or RDATE values which cannot be read,
+// are reported and ignored; any other invalid property fails the component.
+// The RRULE of an occurrence and the cancellation of a series have no task
+// to apply to and are reported as well.
+func (d *Decoder) readProperties(raw *rawComponent) (*component, error) {
+	c := &component{raw: raw}
+
+	var (
+		uid, title, description string
+		start, end              time.Time
+		duration                time.Duration
+		priority                int
+		rule                    *domain.RecurrenceRule
+		completed, hasDesc      bool
+		allDay                  bool
+		ruleLine, cancelLine    *contentLine
+		errc                    error
+	)
+
+	for _, line := range raw.props {
+		var err error
+
+		switch line.name {
+		case "UID":
+			uid = line.value
+		case "SUMMARY":
+			title = unescapeText(line.value)
+		case "DESCRIPTION":
+			description, hasDesc = unescapeText(line.value), true
+		case "DTSTART":
+			start, err = d.parseTime(line.value, line)
+			allDay = isDate(line.value, line)
+		case "DTEND", "DUE":
+			end, err = d.parseTime(line.value, line)
+		case "DURATION":
+			duration, err = parseDuration(line.value)
+		case "PRIORITY":
+			// An invalid priority is left undefined rather than failing the component
+			p, err := parsePriority(line.value)
+			if err != nil {
+				d.warn(InvalidProperty, line.number, line.name, err)
+				continue
+			}
+
+			priority = p
+		case "RECURRENCE-ID":
+			c.recurrenceID, err = d.parseTime(line.value, line)
+		case "RRULE":
+			rule, err = domain.ParseRecurrenceRule(line.value)
+			ruleLine = line
+		case "EXDATE", "RDATE":
+			times, err := d.parseTimes(line)
+			if err != nil {
+				d.warn(InvalidProperty, line.number, line.name, err)
+				continue
+			}
+
+			if line.name == "EXDATE" {
+				c.exDates = append(c.exDates, times...)
+			} else {
+				c.rDates = append(c.rDates, times...)
+			}
+		case "STATUS":
+			switch strings.ToUpper(line.value) {
+			case "COMPLETED":
+				completed = true
+			case "CANCELLED":
+				c.cancelled, cancelLine = true, line
+			}
+		case "COMPLETED":
+			completed = true
+		case "DTSTAMP", "CREATED", "LAST-MODIFIED", "SEQUENCE":
+			// Metadata of the component itself
+		default:
+			d.warn(UnsupportedProperty, line.number, line.name, nil)
+		}
+
+		if err != nil {
+			errc = errors.Join(errc, &Warning{Kind: InvalidProperty, Line: line.number, Name: line.name, Err: err})
+		}
+	}
+
+	if uid == "" {
+		errc = errors.Join(errc, errors.New("UID is required"))
+	}
+
+	if start.IsZero() {
+		errc = errors.Join(errc, domain_errors.ErrTimeRequired)
+	}
+
+	if errc != nil {
+		return nil, errc
+	}
+
+	if rule != nil && !c.recurrenceID.IsZero() {
+		d.warn(UnsupportedProperty, ruleLine.number, ruleLine.name, errors.New("an occurrence follows the recurrence of its series"))
+	}
+
+	if c.cancelled && c.recurrenceID.IsZero() {
+		d.warn(UnsupportedProperty, cancelLine.number, cancelLine.name, errors.New("a cancelled series is imported as scheduled"))
+	}
+
+	if !hasDesc {
+		description = title
+	}
+
+	c.primaryId = primaryIdOf(uid)
+
+	var taskID *domain.TaskID
+	var err error
+	if c.recurrenceID.IsZero() {
+		taskID, err = (&domain.OriginalTaskIDFactory{}).CreateTaskID(c.primaryId.String())
+	} else {
+		taskID, err = domain.NewCopyTaskIDFactory(d.idSource).CreateOccurrenceTaskID(c.primaryId, c.recurrenceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rule != nil && allDay && !rule.GetUntil().IsZero() {
+		// A DATE UNTIL is the last day of an all-day series, in the zone of its dates
+		until := rule.GetUntil()
+		rule.WithUntil(time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, start.Location()))
+	}
+
+	if rule != nil && c.recurrenceID.IsZero() {
+		c.task, err = domain.NewRecurringTask(taskID, title, description, rule, start)
+	} else {
+		c.task, err = domain.NewTask(taskID, title, description, false, 0, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setEnd(c.task, allDay, end, duration); err != nil {
+		return nil, err
+	}
+
+	if err := c.task.SetPriority(priority); err != nil {
+		return nil, err
+	}
+
+	if completed {
+		c.task.Complete()
+	}
+
+	return c, nil
+}
+
+// parsePriority parses a PRIORITY value, 0 for undefined to 9 for the lowest
+func parsePriority(value string) (int, error) {
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < 0 || priority > 9 {
+		return 0, fmt.Errorf("invalid priority %q", value)
+	}
+
+	return priority, nil
+}
+
+// setEnd sets the end of the task from its DTEND, DUE or DURATION, if any
+//
+// A task starting on a DATE is an all-day task lasting one day unless its
+// end says otherwise.
+func setEnd(task *domain.Task, allDay bool, end time.Time, duration time.Duration) error {
+	if !end.IsZero() {
+		duration = end.Sub(task.GetTime())
+	}
+
+	if allDay {
+		days := 1
+		if duration != 0 {
+			// A day may be shortened or lengthened by daylight saving
+			days = int((duration + 12*time.Hour) / (24 * time.Hour))
+		}
+		return task.SetAllDay(days)
+	}
+
+	if duration == 0 {
+		return nil
+	}
+
+	return task.SetDuration(duration)
+}
+
+// newSeries applies the exceptions of an original task
+func (d *Decoder) newSeries(c *component) error {
+	var errc error
+	for _, exDate := range c.exDates {
+		errc = errors.Join(errc, c.task.ExcludeOccurrence(exDate))
+	}
+
+	for _, rDate := range c.rDates {
+		errc = errors.Join(errc, c.task.AddOccurrence(rDate))
+	}
+
+	return errc
+}
+
+// applyOccurrence overrides, or excludes when cancelled, the occurrence of the series
+func (d *Decoder) applyOccurrence(c *component, series *domain.Task) error {
+	if series == nil {
+		return domain_errors.ErrTaskNotFound
+	}
+
+	if c.cancelled {
+		return series.ExcludeOccurrence(c.recurrenceID)
+	}
+
+	return series.OverrideOccurrence(c.recurrenceID, c.task)
+}
+
+// primaryIdOf returns the primaryId the UID maps onto
+func primaryIdOf(uid string) uuid.UUID {
+	if id, err := uuid.Parse(uid); err == nil {
+		return id
+	}
+
+	return uuid.NewSHA1(uidNamespace, []byte(uid))
+}
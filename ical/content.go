@@ -0,0 +1,116 @@
+// Package ical encodes and decodes the calendar's tasks as RFC 5545 iCalendar text
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineOctets is the longest a content line may be, excluding the line break
+	maxLineOctets = 75
+	// crlf is the line break of every content line
+	crlf = "\r\n"
+
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+	dateLayout  = "20060102"
+)
+
+// contentWriter writes content lines, folding them and keeping the first error
+type contentWriter struct {
+	w   io.Writer
+	err error
+}
+
+// writeLine writes the property with its parameters and an already escaped value
+func (cw *contentWriter) writeLine(name, value string, params ...string) {
+	if cw.err != nil {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(name)
+	for _, param := range params {
+		line.WriteString(";")
+		line.WriteString(param)
+	}
+	line.WriteString(":")
+	line.WriteString(value)
+
+	_, cw.err = io.WriteString(cw.w, fold(line.String()))
+}
+
+// fold splits the line into lines of at most 75 octets, each continuation
+// starting with a space, without splitting a UTF-8 sequence
+func fold(line string) string {
+	var folded bytes.Buffer
+
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		folded.WriteString(line[:cut])
+		folded.WriteString(crlf + " ")
+		line = line[cut:]
+
+		// The leading space counts towards the continuation line
+		limit = maxLineOctets - 1
+	}
+
+	folded.WriteString(line)
+	folded.WriteString(crlf)
+
+	return folded.String()
+}
+
+// escapeText escapes a TEXT value
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(value)
+}
+
+// formatTime formats a DATE-TIME value in the given location
+//
+// UTC, and locations without an IANA name such as time.Local, are written in
+// UTC; every other location as local time with its TZID parameter.
+func formatTime(t time.Time, loc *time.Location) (value string, params []string) {
+	if !hasTZID(loc) {
+		return t.UTC().Format(utcLayout), nil
+	}
+
+	return t.In(loc).Format(localLayout), []string{"TZID=" + loc.String()}
+}
+
+// formatDate formats a DATE value, the date of the time in the given location
+func formatDate(t time.Time, loc *time.Location) (value string, params []string) {
+	return t.In(loc).Format(dateLayout), []string{"VALUE=DATE"}
+}
+
+// formatTimes formats a list of values in the given location with format
+func formatTimes(times []time.Time, loc *time.Location, format func(time.Time, *time.Location) (string, []string)) (value string, params []string) {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i], params = format(t, loc)
+	}
+
+	return strings.Join(values, ","), params
+}
+
+// hasTZID returns true if times in the location are written with a TZID
+func hasTZID(loc *time.Location) bool {
+	name := loc.String()
+	return name != "UTC" && name != "Local" && name != ""
+}
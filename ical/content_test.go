@@ -0,0 +1,63 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{
+			name: "Short line",
+			line: "SUMMARY:title",
+		},
+		{
+			name: "Exactly 75 octets",
+			line: "SUMMARY:" + strings.Repeat("a", 67),
+		},
+		{
+			name: "Long ASCII line",
+			line: "DESCRIPTION:" + strings.Repeat("abcdefghij", 20),
+		},
+		{
+			name: "Multi-byte characters across the fold",
+			line: "SUMMARY:" + strings.Repeat("ñ€😀", 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := fold(tt.line)
+			assert.True(t, strings.HasSuffix(folded, crlf))
+
+			lines := strings.Split(strings.TrimSuffix(folded, crlf), crlf)
+			for i, line := range lines {
+				assert.LessOrEqual(t, len(line), maxLineOctets)
+				assert.True(t, utf8.ValidString(line))
+				if i > 0 {
+					assert.True(t, strings.HasPrefix(line, " "))
+				}
+			}
+
+			// Unfolding restores the line
+			assert.Equal(t, tt.line, strings.ReplaceAll(strings.TrimSuffix(folded, crlf), crlf+" ", ""))
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne\nf`, escapeText("a\\b;c,d\ne\r\nf"))
+}
+
+func TestFormatOffset(t *testing.T) {
+	assert.Equal(t, "+0100", formatOffset(3600))
+	assert.Equal(t, "-0330", formatOffset(-3*3600-30*60))
+	assert.Equal(t, "+0000", formatOffset(0))
+	assert.Equal(t, "+001930", formatOffset(19*60+30))
+}
@@ -0,0 +1,476 @@
+package ical
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// uidNamespace derives the primaryId of the UIDs which are not UUIDs
+var uidNamespace = uuid.MustParse("8c4f2e0a-5b1d-4d3e-9a67-1f0b6c2d7e91")
+
+// Decoder reads tasks from RFC 5545 iCalendar content
+//
+// The content is read one line at a time, but the tasks of an object are
+// only built once its END:VCALENDAR is read, as an occurrence may come
+// before its series, and Decode returns the tasks of every object at once.
+//
+// Every VEVENT and VTODO becomes a task: a component without RECURRENCE-ID
+// is an original task whose primaryId is its UID, one with RECURRENCE-ID
+// overrides, or with STATUS:CANCELLED excludes, that occurrence of the
+// series with the same UID.
+// A UID which is not a UUID is mapped onto a UUID derived from it, so every
+// component of a series shares its primaryId. A component without
+// DESCRIPTION gets its SUMMARY as description.
+// Whatever cannot be imported is reported as a *Warning instead of failing
+// the import, only malformed content does.
+type Decoder struct {
+	cr *contentReader
+	// zones holds the VTIMEZONEs of the current object by TZID
+	zones map[string]*vtimezone
+	// origins holds the component every decoded task was read from
+	origins  map[*domain.Task]*rawComponent
+	warnings []*Warning
+	// idSource generates the secondaryIds of the overrides
+	idSource domain.IDSource
+	// zone is the time zone floating times and dates are read in
+	zone *time.Location
+}
+
+// NewDecoder creates a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{cr: newContentReader(r), origins: make(map[*domain.Task]*rawComponent)}
+}
+
+// WithIDSource sets the source of the secondaryIds of the overrides
+//
+// With a domain.DeterministicIDSource, an override gets the same ID every
+// time the content is imported.
+func (d *Decoder) WithIDSource(source domain.IDSource) *Decoder {
+	d.idSource = source
+	return d
+}
+
+// WithTimeZone sets the time zone floating times and dates are read in
+//
+// Without it, Decode reads them in the local time zone and DecodeInto in
+// the time zone of the calendar, if it has one.
+func (d *Decoder) WithTimeZone(zone *time.Location) *Decoder {
+	d.zone = zone
+	return d
+}
+
+// floatingZone returns the time zone floating times and dates are read in
+func (d *Decoder) floatingZone() *time.Location {
+	if d.zone == nil {
+		return time.Local
+	}
+
+	return d.zone
+}
+
+// rawComponent is a VEVENT or VTODO read but not turned into a task yet
+type rawComponent struct {
+	name  string
+	line  int
+	props []*contentLine
+}
+
+// warn records a warning
+func (d *Decoder) warn(kind WarningKind, line int, name string, err error) {
+	d.warnings = append(d.warnings, &Warning{Kind: kind, Line: line, Name: name, Err: err})
+}
+
+// Decode reads every iCalendar object of the content and returns their original tasks
+//
+// Overrides and exclusions are applied to the tasks of their series.
+// If the content is malformed, Decode returns ErrMalformed joined with the details.
+func (d *Decoder) Decode() ([]*domain.Task, []*Warning, error) {
+	var tasks []*domain.Task
+	objects := 0
+
+	for {
+		line, err := d.cr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, d.warnings, err
+		}
+
+		if line.name != "BEGIN" || !strings.EqualFold(line.value, "VCALENDAR") {
+			return nil, d.warnings, errors.Join(ErrMalformed, fmt.Errorf("line %d: expected BEGIN:VCALENDAR", line.number))
+		}
+
+		objectTasks, err := d.decodeCalendar()
+		if err != nil {
+			return nil, d.warnings, err
+		}
+
+		tasks = append(tasks, objectTasks...)
+		objects++
+	}
+
+	if objects == 0 {
+		return nil, d.warnings, errors.Join(ErrMalformed, errors.New("no VCALENDAR object"))
+	}
+
+	return tasks, d.warnings, nil
+}
+
+// DecodeInto reads every iCalendar object of the content and adds their tasks to the calendar
+//
+// A task the calendar rejects is reported as an InvalidComponent warning.
+func (d *Decoder) DecodeInto(ctx context.Context, c *domain.Calendar) ([]*Warning, error) {
+	if c == nil {
+		return nil, domain_errors.ErrCalendarCannotBeNil
+	}
+
+	if d.zone == nil {
+		d.zone = c.GetTimeZone()
+	}
+
+	tasks, _, err := d.Decode()
+	if err != nil {
+		return d.warnings, err
+	}
+
+	for _, task := range tasks {
+		if err := c.AddTask(ctx, task); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return d.warnings, ctxErr
+			}
+
+			raw := d.origins[task]
+			d.warn(InvalidComponent, raw.line, raw.name, err)
+		}
+	}
+
+	return d.warnings, nil
+}
+
+// decodeCalendar reads a VCALENDAR object up to its END and returns its tasks
+func (d *Decoder) decodeCalendar() ([]*domain.Task, error) {
+	d.zones = make(map[string]*vtimezone)
+	var raws []*rawComponent
+
+	for {
+		line, err := d.next("VCALENDAR")
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case line.name == "END" && strings.EqualFold(line.value, "VCALENDAR"):
+			return d.build(raws), nil
+		case line.name == "END":
+			return nil, errors.Join(ErrMalformed, fmt.Errorf("line %d: unexpected END:%s", line.number, line.value))
+		case line.name == "BEGIN":
+			switch name := strings.ToUpper(line.value); name {
+			case "VTIMEZONE":
+				err = d.readTimezone(line)
+			case "VEVENT", "VTODO":
+				var raw *rawComponent
+				raw, err = d.readComponent(line)
+				raws = append(raws, raw)
+			default:
+				d.warn(UnsupportedComponent, line.number, name, nil)
+				err = d.skipComponent(line)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		case line.name == "VERSION", line.name == "PRODID", line.name == "CALSCALE", line.name == "METHOD":
+		default:
+			d.warn(UnsupportedProperty, line.number, line.name, nil)
+		}
+	}
+}
+
+// next returns the next content line within the component
+func (d *Decoder) next(component string) (*contentLine, error) {
+	line, err := d.cr.next()
+	if err == io.EOF {
+		return nil, errors.Join(ErrMalformed, fmt.Errorf("unterminated %s", component))
+	}
+
+	return line, err
+}
+
+// readComponent reads the properties of a VEVENT or VTODO up to its END
+//
+// Nested components, such as VALARM, are skipped.
+func (d *Decoder) readComponent(begin *contentLine) (*rawComponent, error) {
+	raw := &rawComponent{name: strings.ToUpper(begin.value), line: begin.number}
+
+	for {
+		line, err := d.next(raw.name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch line.name {
+		case "END":
+			if !strings.EqualFold(line.value, raw.name) {
+				return nil, errors.Join(ErrMalformed, fmt.Errorf("line %d: unexpected END:%s", line.number, line.value))
+			}
+			return raw, nil
+		case "BEGIN":
+			d.warn(UnsupportedComponent, line.number, strings.ToUpper(line.value), nil)
+			if err := d.skipComponent(line); err != nil {
+				return nil, err
+			}
+		default:
+			raw.props = append(raw.props, line)
+		}
+	}
+}
+
+// skipComponent reads past the END of the component
+func (d *Decoder) skipComponent(begin *contentLine) error {
+	name := strings.ToUpper(begin.value)
+
+	for depth := 1; depth > 0; {
+		line, err := d.next(name)
+		if err != nil {
+			return err
+		}
+
+		switch line.name {
+		case "BEGIN":
+			depth++
+		case "END":
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// readTimezone reads a VTIMEZONE up to its END
+func (d *Decoder) readTimezone(begin *contentLine) error {
+	z := &vtimezone{}
+
+	for {
+		line, err := d.next("VTIMEZONE")
+		if err != nil {
+			return err
+		}
+
+		switch line.name {
+		case "END":
+			if z.tzid == "" {
+				d.warn(InvalidComponent, begin.number, "VTIMEZONE", errors.New("TZID is required"))
+				return nil
+			}
+
+			d.zones[z.tzid] = z
+			return nil
+		case "TZID":
+			z.tzid = strings.TrimPrefix(line.value, "/")
+		case "BEGIN":
+			name := strings.ToUpper(line.value)
+			if name != "STANDARD" && name != "DAYLIGHT" {
+				d.warn(UnsupportedComponent, line.number, name, nil)
+				if err := d.skipComponent(line); err != nil {
+					return err
+				}
+				continue
+			}
+
+			o, err := d.readObservance(line)
+			if err != nil {
+				return err
+			}
+			if o != nil {
+				z.observances = append(z.observances, *o)
+			}
+		}
+	}
+}
+
+// readObservance reads a STANDARD or DAYLIGHT observance up to its END
+//
+// An observance which cannot be read is reported and nil is returned.
+func (d *Decoder) readObservance(begin *contentLine) (*observance, error) {
+	name := strings.ToUpper(begin.value)
+	o := &observance{daylight: name == "DAYLIGHT"}
+
+	var errc error
+	hasOffset, hasOffsetFrom := false, false
+
+	for {
+		line, err := d.next(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch line.name {
+		case "END":
+			if o.onset.IsZero() || !hasOffset {
+				errc = errors.Join(errc, errors.New("DTSTART and TZOFFSETTO are required"))
+			}
+
+			if errc != nil {
+				d.warn(InvalidComponent, begin.number, name, errc)
+				return nil, nil
+			}
+
+			if !hasOffsetFrom {
+				o.offsetFrom = o.offsetTo
+			}
+
+			return o, nil
+		case "DTSTART":
+			o.onset, err = time.ParseInLocation(localLayout, line.value, time.UTC)
+		case "TZOFFSETFROM":
+			o.offsetFrom, err = parseOffset(line.value)
+			hasOffsetFrom = err == nil
+		case "TZOFFSETTO":
+			o.offsetTo, err = parseOffset(line.value)
+			hasOffset = err == nil
+		case "TZNAME":
+			o.name = unescapeText(line.value)
+		case "RRULE":
+			o.rule, err = domain.ParseRecurrenceRule(line.value)
+		case "RDATE":
+			for _, value := range strings.Split(line.value, ",") {
+				rDate, err := time.ParseInLocation(localLayout, value, time.UTC)
+				if err != nil {
+					errc = errors.Join(errc, err)
+					continue
+				}
+				o.rDates = append(o.rDates, rDate)
+			}
+		}
+
+		errc = errors.Join(errc, err)
+	}
+}
+
+// parseTime parses the DATE or DATE-TIME value of the line
+//
+// UTC times keep UTC, times with a TZID get its IANA location or else the
+// location its VTIMEZONE defines, and floating times and dates are read in
+// the time zone of the decoder.
+func (d *Decoder) parseTime(value string, line *contentLine) (time.Time, error) {
+	if isDate(value, line) {
+		return time.ParseInLocation(dateLayout, value, d.floatingZone())
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(utcLayout, value)
+	}
+
+	tzid := strings.TrimPrefix(line.param("TZID"), "/")
+	if tzid == "" {
+		return time.ParseInLocation(localLayout, value, d.floatingZone())
+	}
+
+	wall, err := time.ParseInLocation(localLayout, value, time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		z, exists := d.zones[tzid]
+		if !exists {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+
+		if loc, err = z.location(); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc), nil
+}
+
+// isDate returns true if the value of the line is a DATE rather than a DATE-TIME
+func isDate(value string, line *contentLine) bool {
+	return strings.EqualFold(line.param("VALUE"), "DATE") || len(value) == len(dateLayout)
+}
+
+// parseTimes parses every value of a list of DATE or DATE-TIME values
+func (d *Decoder) parseTimes(line *contentLine) ([]time.Time, error) {
+	var times []time.Time
+	for _, value := range strings.Split(line.value, ",") {
+		t, err := d.parseTime(value, line)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+
+	return times, nil
+}
+
+// parseDuration parses an RFC 5545 DURATION value, e.g. PT1H30M or P1W
+//
+// Days and weeks are taken as 24 hours and 7 days.
+func parseDuration(value string) (time.Duration, error) {
+	s := value
+	sign := time.Duration(1)
+
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	s = s[1:]
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var duration time.Duration
+	inTime := false
+	n := -1
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+
+		switch {
+		case ch >= '0' && ch <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(ch-'0')
+		case ch == 'T' && !inTime && n < 0:
+			inTime = true
+		default:
+			unit, known := units[ch]
+			// Hours, minutes and seconds only follow T, weeks and days precede it
+			isTimeUnit := ch == 'H' || ch == 'M' || ch == 'S'
+			if !known || n < 0 || isTimeUnit != inTime {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+
+			duration += time.Duration(n) * unit
+			n = -1
+		}
+	}
+
+	if n >= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * duration, nil
+}
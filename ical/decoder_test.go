@@ -0,0 +1,562 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// ics joins the content lines of an iCalendar object
+func ics(lines ...string) string {
+	return strings.Join(lines, crlf) + crlf
+}
+
+func TestDecoder_Decode_roundTrip(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 25, 9, 0, 0, 0, madrid)
+
+	rule, err := domain.ParseRecurrenceRule("FREQ=DAILY;COUNT=10")
+	assert.NoError(t, err)
+
+	series, err := domain.NewRecurringTask(domain.NewTaskID(), "Stand-up, daily", "Notes; line one\nline two", rule, start)
+	assert.NoError(t, err)
+	assert.NoError(t, series.ExcludeOccurrence(start.AddDate(0, 0, 1)))
+
+	id := series.GetID()
+	overrideID, err := (&domain.CopyTaskIDFactory{}).CreateTaskID(id.GetPrimaryID().String())
+	assert.NoError(t, err)
+
+	override, err := domain.NewTask(
+		overrideID,
+		strings.Repeat("Moved ", 20), "description",
+		false, 0,
+		start.AddDate(0, 0, 2).Add(time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, series.OverrideOccurrence(start.AddDate(0, 0, 2), override))
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).EncodeTask(series))
+
+	tasks, warnings, err := NewDecoder(&buf).Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, tasks, 1)
+
+	got := tasks[0]
+	gotID := got.GetID()
+	assert.Equal(t, id.GetPrimaryID(), gotID.GetPrimaryID())
+	assert.True(t, gotID.IsOriginal())
+	assert.Equal(t, series.GetTitle(), got.GetTitle())
+	assert.Equal(t, series.GetDescription(), got.GetDescription())
+	assert.True(t, start.Equal(got.GetTime()))
+	assert.Equal(t, "Europe/Madrid", got.GetTime().Location().String())
+	assert.Equal(t, rule.String(), got.GetRecurrenceRule().String())
+	assert.Len(t, got.GetExceptionDates(), 1)
+	assert.True(t, start.AddDate(0, 0, 1).Equal(got.GetExceptionDates()[0]))
+
+	gotOverride, exists := got.GetOverride(start.AddDate(0, 0, 2))
+	assert.True(t, exists)
+	assert.Equal(t, override.GetTitle(), gotOverride.GetTitle())
+	assert.True(t, override.GetTime().Equal(gotOverride.GetTime()))
+
+	gotOverrideID := gotOverride.GetID()
+	assert.False(t, gotOverrideID.IsOriginal())
+	assert.Equal(t, id.GetPrimaryID(), gotOverrideID.GetPrimaryID())
+}
+
+func TestDecoder_Decode_timezone(t *testing.T) {
+	// A Windows time zone name the time zone database doesn't know
+	content := ics(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:meeting@example.com",
+		"DTSTART;TZID=W. Europe Standard Time:20240710T090000",
+		"SUMMARY:Summer",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:other@example.com",
+		"DTSTART;TZID=W. Europe Standard Time:20240110T090000",
+		"SUMMARY:Winter",
+		"END:VEVENT",
+		"BEGIN:VTIMEZONE",
+		"TZID:W. Europe Standard Time",
+		"BEGIN:STANDARD",
+		"DTSTART:16011028T030000",
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:16010325T020000",
+		"RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"END:VCALENDAR",
+	)
+
+	tasks, warnings, err := NewDecoder(strings.NewReader(content)).Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, tasks, 2)
+
+	assert.Equal(t, time.Date(2024, time.July, 10, 7, 0, 0, 0, time.UTC), tasks[0].GetTime().UTC())
+	assert.Equal(t, time.Date(2024, time.January, 10, 8, 0, 0, 0, time.UTC), tasks[1].GetTime().UTC())
+
+	// The location follows the daylight saving changes of the VTIMEZONE
+	loc := tasks[0].GetTime().Location()
+	assert.Equal(t, "W. Europe Standard Time", loc.String())
+	assert.Equal(t, time.Date(2030, time.January, 10, 8, 0, 0, 0, time.UTC), time.Date(2030, time.January, 10, 9, 0, 0, 0, loc).UTC())
+	assert.Equal(t, time.Date(2030, time.March, 31, 1, 0, 0, 0, time.UTC), time.Date(2030, time.March, 31, 3, 0, 0, 0, loc).UTC())
+	assert.Equal(t, time.Date(2030, time.October, 27, 1, 0, 0, 0, time.UTC), time.Date(2030, time.October, 27, 2, 0, 0, 0, loc).UTC())
+
+	// A UID which is not a UUID maps onto the same primaryId every time
+	id := tasks[0].GetID()
+	assert.Equal(t, primaryIdOf("meeting@example.com"), id.GetPrimaryID())
+	assert.Equal(t, "Summer", tasks[0].GetDescription())
+}
+
+func TestDecoder_Decode_warnings(t *testing.T) {
+	content := ics(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"X-WR-CALNAME:Work",
+		"BEGIN:VTODO",
+		"UID:6a6c7d1e-1a0b-4c43-9a52-2c5a4b0f3a10",
+		"DTSTART:20240102T090000Z",
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"EXDATE:not-a-date",
+		"SUMMARY:Review",
+		"DESCRIPTION:Daily review",
+		"LOCATION:Office",
+		"STATUS:COMPLETED",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:6a6c7d1e-1a0b-4c43-9a52-2c5a4b0f3a10",
+		"RECURRENCE-ID:20240103T090000Z",
+		"DTSTART:20240103T090000Z",
+		"SUMMARY:Review",
+		"STATUS:CANCELLED",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"UID:missing-start",
+		"SUMMARY:No start",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:orphan",
+		"RECURRENCE-ID:20240103T090000Z",
+		"DTSTART:20240103T090000Z",
+		"SUMMARY:Orphan",
+		"END:VEVENT",
+		"BEGIN:VJOURNAL",
+		"BEGIN:X-NESTED",
+		"END:X-NESTED",
+		"END:VJOURNAL",
+		"END:VCALENDAR",
+	)
+
+	tasks, warnings, err := NewDecoder(strings.NewReader(content)).Decode()
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	task := tasks[0]
+	assert.True(t, task.IsCompleted())
+	assert.Equal(t, []time.Time{time.Date(2024, time.January, 3, 9, 0, 0, 0, time.UTC)}, task.GetExceptionDates())
+
+	type warning struct {
+		kind WarningKind
+		line int
+		name string
+	}
+	got := make([]warning, len(warnings))
+	for i, w := range warnings {
+		got[i] = warning{w.Kind, w.Line, w.Name}
+	}
+
+	assert.Equal(t, []warning{
+		{UnsupportedProperty, 3, "X-WR-CALNAME"},
+		{UnsupportedComponent, 13, "VALARM"},
+		{UnsupportedComponent, 34, "VJOURNAL"},
+		{InvalidProperty, 8, "EXDATE"},
+		{UnsupportedProperty, 11, "LOCATION"},
+		{InvalidComponent, 24, "VEVENT"},
+		{InvalidComponent, 28, "VEVENT"},
+	}, got)
+
+	assert.ErrorIs(t, warnings[5], domain_errors.ErrTimeRequired)
+	assert.ErrorIs(t, warnings[6], domain_errors.ErrTaskNotFound)
+}
+
+func TestDecoder_Decode_ignoredProperties(t *testing.T) {
+	content := ics(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:7b7d8e2f-2b1c-4d54-8b63-3d6b5c1f4b21",
+		"DTSTART:20240102T090000Z",
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"SUMMARY:Stand-up",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:7b7d8e2f-2b1c-4d54-8b63-3d6b5c1f4b21",
+		"RECURRENCE-ID:20240103T090000Z",
+		"DTSTART:20240103T100000Z",
+		"RRULE:FREQ=WEEKLY",
+		"SUMMARY:Moved stand-up",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"DTSTART:20240102T120000Z",
+		"SUMMARY:Lunch",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	tasks, warnings, err := NewDecoder(strings.NewReader(content)).Decode()
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	override, exists := tasks[0].GetOverride(time.Date(2024, time.January, 3, 9, 0, 0, 0, time.UTC))
+	assert.True(t, exists)
+	assert.Nil(t, override.GetRecurrenceRule())
+
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, UnsupportedProperty, warnings[0].Kind)
+		assert.Equal(t, 13, warnings[0].Line)
+		assert.Equal(t, "RRULE", warnings[0].Name)
+
+		assert.Equal(t, UnsupportedProperty, warnings[1].Kind)
+		assert.Equal(t, 20, warnings[1].Line)
+		assert.Equal(t, "STATUS", warnings[1].Name)
+	}
+}
+
+func TestDecoder_Decode_malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "Empty stream",
+			content: "",
+		},
+		{
+			name:    "Not a calendar",
+			content: ics("BEGIN:VEVENT", "END:VEVENT"),
+		},
+		{
+			name:    "Unterminated calendar",
+			content: ics("BEGIN:VCALENDAR", "VERSION:2.0"),
+		},
+		{
+			name:    "Mismatched END",
+			content: ics("BEGIN:VCALENDAR", "BEGIN:VEVENT", "END:VTODO", "END:VCALENDAR"),
+		},
+		{
+			name:    "Malformed line",
+			content: ics("BEGIN:VCALENDAR", "SUMMARY", "END:VCALENDAR"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewDecoder(strings.NewReader(tt.content)).Decode()
+			assert.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func TestDecoder_DecodeInto(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	task, err := domain.NewTask(domain.NewTaskID(), "title", "description", true, 24*time.Hour, start)
+	assert.NoError(t, err)
+
+	exported := domain.NewCalendar()
+	assert.NoError(t, exported.AddTask(context.Background(), task))
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).EncodeCalendar(exported))
+
+	c := domain.NewCalendar()
+	warnings, err := NewDecoder(&buf).DecodeInto(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	m, err := c.GetMonth(time.January, 2024)
+	assert.NoError(t, err)
+	assert.Len(t, m.GetTasks(), 31)
+
+	_, err = NewDecoder(&buf).DecodeInto(context.Background(), nil)
+	assert.ErrorIs(t, err, domain_errors.ErrCalendarCannotBeNil)
+}
+
+func TestDecoder_WithTimeZone(t *testing.T) {
+	content := ics(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:floating@example.com",
+		"DTSTART:20240110T090000",
+		"SUMMARY:Floating",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:date@example.com",
+		"DTSTART;VALUE=DATE:20240111",
+		"SUMMARY:Date",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		decode func(d *Decoder) ([]*domain.Task, error)
+		want   *time.Location
+	}{
+		{
+			name: "Local time zone by default",
+			decode: func(d *Decoder) ([]*domain.Task, error) {
+				tasks, _, err := d.Decode()
+				return tasks, err
+			},
+			want: time.Local,
+		},
+		{
+			name: "Decoder time zone",
+			decode: func(d *Decoder) ([]*domain.Task, error) {
+				tasks, _, err := d.WithTimeZone(tokyo).Decode()
+				return tasks, err
+			},
+			want: tokyo,
+		},
+		{
+			name: "Calendar time zone",
+			decode: func(d *Decoder) ([]*domain.Task, error) {
+				c := domain.NewCalendar()
+				if err := c.WithTimeZone(tokyo); err != nil {
+					return nil, err
+				}
+
+				if _, err := d.DecodeInto(context.Background(), c); err != nil {
+					return nil, err
+				}
+
+				return c.GetSeries(), nil
+			},
+			want: tokyo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := tt.decode(NewDecoder(strings.NewReader(content)))
+			assert.NoError(t, err)
+			assert.Len(t, tasks, 2)
+
+			times := make([]time.Time, 0, len(tasks))
+			for _, task := range tasks {
+				times = append(times, task.GetTime())
+			}
+
+			assert.ElementsMatch(t, []time.Time{
+				time.Date(2024, time.January, 10, 9, 0, 0, 0, tt.want),
+				time.Date(2024, time.January, 11, 0, 0, 0, 0, tt.want),
+			}, times)
+		})
+	}
+}
+
+func TestDecoder_WithIDSource(t *testing.T) {
+	content := ics(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTART:20240101T090000Z",
+		"RRULE:FREQ=DAILY;COUNT=5",
+		"SUMMARY:Stand-up",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"RECURRENCE-ID:20240103T090000Z",
+		"DTSTART:20240103T100000Z",
+		"SUMMARY:Moved",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	)
+
+	overrideID := func() domain.TaskID {
+		tasks, warnings, err := NewDecoder(strings.NewReader(content)).
+			WithIDSource(domain.NewDeterministicIDSource(nil)).
+			Decode()
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Len(t, tasks, 1)
+
+		override, exists := tasks[0].GetOverride(time.Date(2024, time.January, 3, 9, 0, 0, 0, time.UTC))
+		assert.True(t, exists)
+
+		return override.GetID()
+	}
+
+	// Importing the stream again gives the override the same ID
+	assert.Equal(t, overrideID(), overrideID())
+}
+
+func TestDecoder_Decode_end(t *testing.T) {
+	event := func(lines ...string) string {
+		return ics(append(append([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"BEGIN:VEVENT",
+			"UID:meeting@example.com",
+			"SUMMARY:Meeting",
+		}, lines...), "END:VEVENT", "END:VCALENDAR")...)
+	}
+
+	tests := []struct {
+		name         string
+		content      string
+		wantDuration time.Duration
+		wantAllDay   bool
+	}{
+		{
+			name:         "DTEND",
+			content:      event("DTSTART:20240110T090000Z", "DTEND:20240110T103000Z"),
+			wantDuration: 90 * time.Minute,
+		},
+		{
+			name:         "DURATION",
+			content:      event("DTSTART:20240110T090000Z", "DURATION:P1DT2H"),
+			wantDuration: 26 * time.Hour,
+		},
+		{
+			name:         "DUE",
+			content:      event("DTSTART:20240110T090000Z", "DUE:20240110T091500Z"),
+			wantDuration: 15 * time.Minute,
+		},
+		{
+			name:         "All-day without an end",
+			content:      event("DTSTART;VALUE=DATE:20240110"),
+			wantDuration: 24 * time.Hour,
+			wantAllDay:   true,
+		},
+		{
+			name:         "All-day with DTEND",
+			content:      event("DTSTART;VALUE=DATE:20240110", "DTEND;VALUE=DATE:20240113"),
+			wantDuration: 72 * time.Hour,
+			wantAllDay:   true,
+		},
+		{
+			name:         "All-day with DURATION",
+			content:      event("DTSTART;VALUE=DATE:20240110", "DURATION:P1W"),
+			wantDuration: 7 * 24 * time.Hour,
+			wantAllDay:   true,
+		},
+		{
+			name:    "Without an end",
+			content: event("DTSTART:20240110T090000Z"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, warnings, err := NewDecoder(strings.NewReader(tt.content)).Decode()
+			assert.NoError(t, err)
+			assert.Empty(t, warnings)
+			assert.Len(t, tasks, 1)
+
+			assert.Equal(t, tt.wantDuration, tasks[0].GetDuration())
+			assert.Equal(t, tt.wantAllDay, tasks[0].IsAllDay())
+		})
+	}
+
+	// An end before the start fails the component
+	tasks, warnings, err := NewDecoder(strings.NewReader(event("DTSTART:20240110T090000Z", "DTEND:20240110T080000Z"))).Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+	assert.Len(t, warnings, 1)
+	assert.ErrorIs(t, warnings[0], domain_errors.ErrInvalidEndTime)
+}
+
+func TestDecoder_Decode_priority(t *testing.T) {
+	event := func(priority string) string {
+		return ics(
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"BEGIN:VEVENT",
+			"UID:meeting@example.com",
+			"SUMMARY:Meeting",
+			"DTSTART:20240110T090000Z",
+			"PRIORITY:"+priority,
+			"END:VEVENT",
+			"END:VCALENDAR")
+	}
+
+	tasks, warnings, err := NewDecoder(strings.NewReader(event("1"))).Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, 1, tasks[0].GetPriority())
+
+	// A priority out of range is left undefined
+	tasks, warnings, err = NewDecoder(strings.NewReader(event("12"))).Decode()
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, 0, tasks[0].GetPriority())
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, InvalidProperty, warnings[0].Kind)
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT1H30M", want: 90 * time.Minute},
+		{value: "P2D", want: 48 * time.Hour},
+		{value: "P1W", want: 7 * 24 * time.Hour},
+		{value: "P1DT12H", want: 36 * time.Hour},
+		{value: "PT45S", want: 45 * time.Second},
+		{value: "-PT15M", want: -15 * time.Minute},
+		{value: "+PT15M", want: 15 * time.Minute},
+		{value: "P", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "1H", wantErr: true},
+		{value: "P1H", wantErr: true},
+		{value: "PT1D", wantErr: true},
+		{value: "P1", wantErr: true},
+		{value: "P1X", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDuration(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
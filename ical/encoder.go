@@ -0,0 +1,292 @@
+package ical
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+// prodID identifies the product which created the iCalendar object
+const prodID = "-//sosalejandro//go-calendar//EN"
+
+// ErrInvalidComponent is returned when tasks are encoded as an unknown component
+var ErrInvalidComponent = errors.New("invalid iCalendar component")
+
+// Component is the iCalendar component tasks are encoded as
+type Component string
+
+const (
+	// VEvent encodes tasks as events
+	VEvent Component = "VEVENT"
+	// VTodo encodes tasks as to-dos, which also carry their completion
+	VTodo Component = "VTODO"
+)
+
+// Encoder writes tasks as an RFC 5545 iCalendar object
+//
+// The TaskID of a task maps to the UID and RECURRENCE-ID of its component:
+// a series is written with the primaryId as UID and its recurrence rule and
+// exceptions, every override or copy of it as a component with the same UID
+// and the time of its occurrence as RECURRENCE-ID.
+type Encoder struct {
+	w         io.Writer
+	component Component
+	now       func() time.Time
+}
+
+// NewEncoder creates an encoder writing VEVENT components to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w, component: VEvent, now: time.Now}
+}
+
+// WithComponent sets the component tasks are encoded as and returns the encoder
+func (e *Encoder) WithComponent(component Component) *Encoder {
+	e.component = component
+	return e
+}
+
+// WithClock sets the clock used for DTSTAMP and returns the encoder
+func (e *Encoder) WithClock(now func() time.Time) *Encoder {
+	e.now = now
+	return e
+}
+
+// entry is a task to be written and how
+type entry struct {
+	task *domain.Task
+	// uid is the UID of the component
+	uid string
+	// recurrenceID is the occurrence the component replaces, if any
+	recurrenceID time.Time
+	// series is true if the recurrence rule and exceptions are written
+	series bool
+}
+
+// EncodeTask writes the task as an iCalendar object
+//
+// An original task is written together with its overrides, a copy as the
+// single occurrence of its series it stands for.
+func (e *Encoder) EncodeTask(task *domain.Task) error {
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	id := task.GetID()
+	if !id.IsOriginal() {
+		return e.encode(occurrenceEntry(task))
+	}
+
+	return e.encode(seriesEntries(task)...)
+}
+
+// EncodeMonth writes the tasks placed in the month as an iCalendar object
+//
+// Every task is written as a standalone component with its TaskID, in its
+// String form, as UID; the recurrence of its series is not written. Use
+// EncodeCalendar to export the series themselves.
+func (e *Encoder) EncodeMonth(m *domain.Month) error {
+	if m == nil {
+		return domain_errors.ErrMonthCannotBeNil
+	}
+
+	tasks := m.GetTasks()
+	entries := make([]entry, 0, len(tasks))
+	for _, task := range tasks {
+		id := task.GetID()
+		entries = append(entries, entry{task: task, uid: id.String()})
+	}
+
+	return e.encode(entries...)
+}
+
+// EncodeCalendar writes every series of the calendar as an iCalendar object
+//
+// The completed occurrences of each series are written along with it, every
+// other occurrence follows from its recurrence rule.
+func (e *Encoder) EncodeCalendar(c *domain.Calendar) error {
+	if c == nil {
+		return domain_errors.ErrCalendarCannotBeNil
+	}
+
+	var entries []entry
+	for _, task := range c.GetSeries() {
+		entries = append(entries, seriesEntries(task)...)
+	}
+
+	for _, task := range c.GetCompletedOccurrences() {
+		entries = append(entries, occurrenceEntry(task))
+	}
+
+	return e.encode(entries...)
+}
+
+// seriesEntries returns the entries of an original task and its overrides
+func seriesEntries(task *domain.Task) []entry {
+	id := task.GetID()
+	entries := []entry{{task: task, uid: id.GetPrimaryID().String(), series: true}}
+
+	for _, override := range task.GetOverrides() {
+		entries = append(entries, occurrenceEntry(override))
+	}
+
+	return entries
+}
+
+// occurrenceEntry returns the entry of a copy replacing its occurrence
+func occurrenceEntry(task *domain.Task) entry {
+	id := task.GetID()
+	return entry{task: task, uid: id.GetPrimaryID().String(), recurrenceID: task.GetOccurrenceTime()}
+}
+
+// encode writes the entries as an iCalendar object
+func (e *Encoder) encode(entries ...entry) error {
+	if e.component != VEvent && e.component != VTodo {
+		return ErrInvalidComponent
+	}
+
+	cw := &contentWriter{w: e.w}
+	dtstamp := e.now().UTC().Format(utcLayout)
+
+	cw.writeLine("BEGIN", "VCALENDAR")
+	cw.writeLine("VERSION", "2.0")
+	cw.writeLine("PRODID", prodID)
+	cw.writeLine("CALSCALE", "GREGORIAN")
+
+	for _, z := range zoneRanges(entries) {
+		cw.writeTimezone(z)
+	}
+
+	for _, en := range entries {
+		e.writeEntry(cw, en, dtstamp)
+	}
+
+	cw.writeLine("END", "VCALENDAR")
+
+	return cw.err
+}
+
+// writeEntry writes the component of a single entry
+func (e *Encoder) writeEntry(cw *contentWriter, en entry, dtstamp string) {
+	task := en.task
+	loc := task.GetTime().Location()
+
+	cw.writeLine("BEGIN", string(e.component))
+	cw.writeLine("UID", escapeText(en.uid))
+	cw.writeLine("DTSTAMP", dtstamp)
+
+	// All-day tasks are written as dates, without a time zone
+	format := formatTime
+	if task.IsAllDay() {
+		format = formatDate
+	}
+
+	value, params := format(task.GetTime(), loc)
+	cw.writeLine("DTSTART", value, params...)
+
+	if task.GetDuration() > 0 {
+		// A to-do is due when it ends
+		name := "DTEND"
+		if e.component == VTodo {
+			name = "DUE"
+		}
+
+		value, params := format(task.GetEndTime(), loc)
+		cw.writeLine(name, value, params...)
+	}
+
+	if !en.recurrenceID.IsZero() {
+		value, params := format(en.recurrenceID, loc)
+		cw.writeLine("RECURRENCE-ID", value, params...)
+	}
+
+	cw.writeLine("SUMMARY", escapeText(task.GetTitle()))
+	cw.writeLine("DESCRIPTION", escapeText(task.GetDescription()))
+
+	if priority := task.GetPriority(); priority != 0 {
+		cw.writeLine("PRIORITY", strconv.Itoa(priority))
+	}
+
+	if en.series {
+		if rule := task.GetRecurrenceRule(); rule != nil {
+			cw.writeLine("RRULE", formatRule(rule, task.IsAllDay(), loc))
+		}
+
+		if exDates := task.GetExceptionDates(); len(exDates) > 0 {
+			value, params := formatTimes(exDates, loc, format)
+			cw.writeLine("EXDATE", value, params...)
+		}
+
+		if rDates := task.GetRecurrenceDates(); len(rDates) > 0 {
+			value, params := formatTimes(rDates, loc, format)
+			cw.writeLine("RDATE", value, params...)
+		}
+	}
+
+	// Only to-dos can be completed
+	if e.component == VTodo {
+		if task.IsCompleted() {
+			// The time of completion is not tracked, so COMPLETED is not written
+			cw.writeLine("STATUS", "COMPLETED")
+		} else {
+			cw.writeLine("STATUS", "NEEDS-ACTION")
+		}
+	}
+
+	cw.writeLine("END", string(e.component))
+}
+
+// formatRule formats the RRULE value of a series
+//
+// The UNTIL of an all-day series is written as a DATE, like its DTSTART:
+// the date of the last day it may occur on.
+func formatRule(rule *domain.RecurrenceRule, allDay bool, loc *time.Location) string {
+	value := rule.String()
+	until := rule.GetUntil()
+	if !allDay || until.IsZero() {
+		return value
+	}
+
+	parts := strings.Split(value, ";")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "UNTIL=") {
+			parts[i] = "UNTIL=" + until.In(loc).Format(dateLayout)
+		}
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// zoneRanges returns the locations written with a TZID by the entries, in order of use
+func zoneRanges(entries []entry) []*zoneRange {
+	var zones []*zoneRange
+	byName := make(map[string]*zoneRange)
+
+	for _, en := range entries {
+		loc := en.task.GetTime().Location()
+		if !hasTZID(loc) || en.task.IsAllDay() {
+			continue
+		}
+
+		z, exists := byName[loc.String()]
+		if !exists {
+			year := en.task.GetTime().Year()
+			z = &zoneRange{loc: loc, fromYear: year, toYear: year}
+			byName[loc.String()] = z
+			zones = append(zones, z)
+		}
+
+		times := append([]time.Time{en.task.GetTime(), en.task.GetEndTime(), en.recurrenceID}, en.task.GetExceptionDates()...)
+		for _, t := range append(times, en.task.GetRecurrenceDates()...) {
+			if !t.IsZero() {
+				z.include(t)
+			}
+		}
+	}
+
+	return zones
+}
@@ -0,0 +1,319 @@
+package ical
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var exportTime = time.Date(2024, time.February, 1, 12, 0, 0, 0, time.UTC)
+
+// encode runs the encoding and returns its unfolded content lines
+func encode(t *testing.T, component Component, run func(e *Encoder) error) []string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf).WithComponent(component).WithClock(func() time.Time { return exportTime })
+	assert.NoError(t, run(e))
+
+	content := strings.ReplaceAll(buf.String(), crlf+" ", "")
+	return strings.Split(strings.TrimSuffix(content, crlf), crlf)
+}
+
+func TestEncoder_EncodeTask(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 25, 9, 0, 0, 0, madrid)
+
+	rule, err := domain.ParseRecurrenceRule("FREQ=DAILY;COUNT=10")
+	assert.NoError(t, err)
+
+	series, err := domain.NewRecurringTask(domain.NewTaskID(), "Stand-up, daily", "Notes; line one\nline two", rule, start)
+	assert.NoError(t, err)
+	assert.NoError(t, series.ExcludeOccurrence(start.AddDate(0, 0, 1)))
+
+	id := series.GetID()
+	uid := id.GetPrimaryID().String()
+
+	overrideID, err := (&domain.CopyTaskIDFactory{}).CreateTaskID(uid)
+	assert.NoError(t, err)
+
+	override, err := domain.NewTask(
+		overrideID,
+		"Moved", "description",
+		false, 0,
+		start.AddDate(0, 0, 2).Add(time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, series.OverrideOccurrence(start.AddDate(0, 0, 2), override))
+
+	lines := encode(t, VEvent, func(e *Encoder) error { return e.EncodeTask(series) })
+
+	assert.Equal(t, []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + prodID,
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Madrid",
+		"BEGIN:STANDARD",
+		"DTSTART:20240101T000000",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0100",
+		"TZNAME:CET",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:20240331T020000",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"TZNAME:CEST",
+		"END:DAYLIGHT",
+		"BEGIN:STANDARD",
+		"DTSTART:20241027T030000",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"TZNAME:CET",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART;TZID=Europe/Madrid:20240325T090000",
+		`SUMMARY:Stand-up\, daily`,
+		`DESCRIPTION:Notes\; line one\nline two`,
+		"RRULE:FREQ=DAILY;COUNT=10",
+		"EXDATE;TZID=Europe/Madrid:20240326T090000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART;TZID=Europe/Madrid:20240327T100000",
+		"RECURRENCE-ID;TZID=Europe/Madrid:20240327T090000",
+		"SUMMARY:Moved",
+		"DESCRIPTION:description",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, lines)
+}
+
+func TestEncoder_EncodeTask_todo(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		completed bool
+		want      []string
+	}{
+		{
+			name: "Pending",
+			want: []string{"STATUS:NEEDS-ACTION"},
+		},
+		{
+			name:      "Completed",
+			completed: true,
+			want:      []string{"STATUS:COMPLETED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := domain.RehydrateTask(domain.NewTaskID(), "title", "description", false, 0, nil, tt.completed, start)
+			assert.NoError(t, err)
+
+			lines := encode(t, VTodo, func(e *Encoder) error { return e.EncodeTask(task) })
+
+			assert.Contains(t, lines, "BEGIN:VTODO")
+			assert.Contains(t, lines, "DTSTART:20240101T090000Z")
+			assert.NotContains(t, lines, "BEGIN:VTIMEZONE")
+			for _, line := range tt.want {
+				assert.Contains(t, lines, line)
+			}
+
+			// The time of completion is unknown
+			for _, line := range lines {
+				assert.NotContains(t, line, "COMPLETED:")
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeTask_allDayUntil(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 25, 0, 0, 0, 0, newYork)
+	until := time.Date(2024, time.March, 28, 0, 0, 0, 0, newYork)
+
+	rule, err := domain.NewRecurrenceRule(domain.Daily, 1)
+	assert.NoError(t, err)
+
+	task, err := domain.NewRecurringTask(domain.NewTaskID(), "title", "description", rule.WithUntil(until), start)
+	assert.NoError(t, err)
+	assert.NoError(t, task.SetAllDay(1))
+
+	var buf bytes.Buffer
+	assert.NoError(t, NewEncoder(&buf).EncodeTask(task))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), crlf), crlf)
+	assert.Contains(t, lines, "DTSTART;VALUE=DATE:20240325")
+	assert.Contains(t, lines, "RRULE:FREQ=DAILY;UNTIL=20240328")
+
+	// The last day is still an occurrence once imported again
+	tasks, warnings, err := NewDecoder(&buf).WithTimeZone(newYork).Decode()
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, tasks, 1)
+	assert.True(t, tasks[0].IsAllDay())
+
+	occurrences, err := tasks[0].GetRecurrenceRule().All(tasks[0].GetTime())
+	assert.NoError(t, err)
+	assert.Len(t, occurrences, 4)
+	assert.True(t, until.Equal(occurrences[3]))
+}
+
+func TestEncoder_EncodeTask_end(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	start := time.Date(2024, time.March, 29, 9, 0, 0, 0, madrid)
+
+	tests := []struct {
+		name      string
+		component Component
+		set       func(task *domain.Task) error
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name:      "Event with an end",
+			component: VEvent,
+			set:       func(task *domain.Task) error { return task.SetDuration(90 * time.Minute) },
+			want:      []string{"DTSTART;TZID=Europe/Madrid:20240329T090000", "DTEND;TZID=Europe/Madrid:20240329T103000"},
+		},
+		{
+			name:      "To-do with an end",
+			component: VTodo,
+			set:       func(task *domain.Task) error { return task.SetDuration(90 * time.Minute) },
+			want:      []string{"DUE;TZID=Europe/Madrid:20240329T103000"},
+			wantNot:   []string{"DTEND;TZID=Europe/Madrid:20240329T103000"},
+		},
+		{
+			name:      "All-day event",
+			component: VEvent,
+			set:       func(task *domain.Task) error { return task.SetAllDay(3) },
+			want:      []string{"DTSTART;VALUE=DATE:20240329", "DTEND;VALUE=DATE:20240401"},
+			wantNot:   []string{"BEGIN:VTIMEZONE"},
+		},
+		{
+			name:      "Without an end",
+			component: VEvent,
+			set:       func(task *domain.Task) error { return nil },
+			wantNot:   []string{"DTEND;TZID=Europe/Madrid:20240329T090000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := domain.NewTask(domain.NewTaskID(), "title", "description", false, 0, start)
+			assert.NoError(t, err)
+			assert.NoError(t, tt.set(task))
+
+			lines := encode(t, tt.component, func(e *Encoder) error { return e.EncodeTask(task) })
+
+			for _, line := range tt.want {
+				assert.Contains(t, lines, line)
+			}
+			for _, line := range tt.wantNot {
+				assert.NotContains(t, lines, line)
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeTask_priority(t *testing.T) {
+	task, err := domain.NewTask(domain.NewTaskID(), "title", "description", false, 0, time.Date(2024, time.March, 29, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	lines := encode(t, VEvent, func(e *Encoder) error { return e.EncodeTask(task) })
+	for _, line := range lines {
+		assert.NotContains(t, line, "PRIORITY")
+	}
+
+	assert.NoError(t, task.SetPriority(2))
+	lines = encode(t, VEvent, func(e *Encoder) error { return e.EncodeTask(task) })
+	assert.Contains(t, lines, "PRIORITY:2")
+}
+
+func TestEncoder_EncodeMonth(t *testing.T) {
+	start := time.Date(2024, time.January, 30, 9, 0, 0, 0, time.UTC)
+
+	task, err := domain.NewTask(domain.NewTaskID(), "title", "description", true, 24*time.Hour, start)
+	assert.NoError(t, err)
+
+	c := domain.NewCalendar()
+	assert.NoError(t, c.AddTaskBetween(context.Background(), task, start, start.AddDate(0, 0, 4)))
+
+	m, err := c.GetMonth(time.January, 2024)
+	assert.NoError(t, err)
+
+	lines := encode(t, VEvent, func(e *Encoder) error { return e.EncodeMonth(m) })
+
+	var uids, starts []string
+	for _, line := range lines {
+		if value, ok := strings.CutPrefix(line, "UID:"); ok {
+			uids = append(uids, value)
+		}
+		if value, ok := strings.CutPrefix(line, "DTSTART:"); ok {
+			starts = append(starts, value)
+		}
+		assert.False(t, strings.HasPrefix(line, "RRULE"))
+	}
+
+	tasks := m.GetTasks()
+	assert.Len(t, uids, len(tasks))
+	for i, task := range tasks {
+		id := task.GetID()
+		assert.Equal(t, id.String(), uids[i])
+	}
+	assert.Equal(t, []string{"20240130T090000Z", "20240131T090000Z"}, starts)
+}
+
+func TestEncoder_EncodeCalendar(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	task, err := domain.NewTask(domain.NewTaskID(), "title", "description", true, 48*time.Hour, start)
+	assert.NoError(t, err)
+
+	c := domain.NewCalendar()
+	assert.NoError(t, c.AddTask(ctx, task))
+
+	tasks, err := c.GetTasksOn(start.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	tasks[0].Complete()
+
+	lines := encode(t, VTodo, func(e *Encoder) error { return e.EncodeCalendar(c) })
+
+	assert.Contains(t, lines, "RRULE:FREQ=DAILY;INTERVAL=2")
+	assert.Contains(t, lines, "RECURRENCE-ID:20240103T090000Z")
+	assert.Contains(t, lines, "STATUS:COMPLETED")
+	assert.Equal(t, 2, strings.Count(strings.Join(lines, "\n"), "BEGIN:VTODO"))
+}
+
+func TestEncoder_errors(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+
+	assert.ErrorIs(t, e.EncodeTask(nil), domain_errors.ErrTaskCannotBeNil)
+	assert.ErrorIs(t, e.EncodeMonth(nil), domain_errors.ErrMonthCannotBeNil)
+	assert.ErrorIs(t, e.EncodeCalendar(nil), domain_errors.ErrCalendarCannotBeNil)
+	assert.ErrorIs(t, e.WithComponent("VJOURNAL").EncodeCalendar(domain.NewCalendar()), ErrInvalidComponent)
+	assert.Empty(t, buf.String())
+}
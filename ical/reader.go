@@ -0,0 +1,161 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// contentLine is an unfolded content line
+type contentLine struct {
+	// number is the line its first physical line is on
+	number int
+	name   string
+	// params holds the parameter values by upper case name
+	params map[string]string
+	value  string
+}
+
+// param returns the value of the parameter, empty if it is absent
+func (l *contentLine) param(name string) string {
+	return l.params[name]
+}
+
+// contentReader reads the unfolded content lines of a stream one at a time
+type contentReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// newContentReader creates a content reader reading from r
+func newContentReader(r io.Reader) *contentReader {
+	return &contentReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next content line
+//
+// At the end of the stream next returns io.EOF. If a line cannot be parsed,
+// it returns ErrMalformed joined with the details.
+func (cr *contentReader) next() (*contentLine, error) {
+	for {
+		raw, err := cr.readPhysical()
+		if err != nil {
+			return nil, err
+		}
+
+		number := cr.line
+
+		// Continuation lines start with a space or a tab
+		for {
+			b, err := cr.r.Peek(1)
+			if err != nil || (b[0] != ' ' && b[0] != '\t') {
+				break
+			}
+
+			continuation, err := cr.readPhysical()
+			if err != nil {
+				return nil, err
+			}
+			raw += continuation[1:]
+		}
+
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		line, err := parseContentLine(raw)
+		if err != nil {
+			return nil, errors.Join(ErrMalformed, fmt.Errorf("line %d: %w", number, err))
+		}
+		line.number = number
+
+		return line, nil
+	}
+}
+
+// readPhysical reads a single physical line without its line break
+func (cr *contentReader) readPhysical() (string, error) {
+	raw, err := cr.r.ReadString('\n')
+	if err != nil && (err != io.EOF || raw == "") {
+		return "", err
+	}
+
+	cr.line++
+
+	return strings.TrimRight(raw, "\r\n"), nil
+}
+
+// parseContentLine parses a line of the form NAME;PARAM=VALUE:VALUE
+func parseContentLine(raw string) (*contentLine, error) {
+	end := strings.IndexAny(raw, ";:")
+	if end <= 0 {
+		return nil, fmt.Errorf("missing property name in %q", raw)
+	}
+
+	line := &contentLine{name: strings.ToUpper(raw[:end]), params: make(map[string]string)}
+	rest := raw[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok || name == "" || strings.ContainsAny(name, ":;") {
+			return nil, fmt.Errorf("malformed parameter of %s", line.name)
+		}
+		rest = value
+
+		var val strings.Builder
+		for rest != "" && rest[0] != ';' && rest[0] != ':' {
+			if rest[0] != '"' {
+				n := strings.IndexAny(rest, `;:"`)
+				if n < 0 {
+					n = len(rest)
+				}
+				val.WriteString(rest[:n])
+				rest = rest[n:]
+				continue
+			}
+
+			// Quoted values may contain ':', ';' and ','
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return nil, fmt.Errorf("unterminated quoted parameter of %s", line.name)
+			}
+			val.WriteString(rest[1 : closing+1])
+			rest = rest[closing+2:]
+		}
+
+		line.params[strings.ToUpper(name)] = val.String()
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return nil, fmt.Errorf("missing value of %s", line.name)
+	}
+	line.value = rest[1:]
+
+	return line, nil
+}
+
+// unescapeText reverts the escaping of a TEXT value
+func unescapeText(value string) string {
+	var text strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			text.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			text.WriteByte('\n')
+		default:
+			text.WriteByte(value[i])
+		}
+	}
+
+	return text.String()
+}
@@ -0,0 +1,94 @@
+package ical
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContentLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantName   string
+		wantParams map[string]string
+		wantValue  string
+		wantErr    bool
+	}{
+		{
+			name:       "Simple property",
+			raw:        "summary:Stand-up",
+			wantName:   "SUMMARY",
+			wantParams: map[string]string{},
+			wantValue:  "Stand-up",
+		},
+		{
+			name:       "Parameters",
+			raw:        "DTSTART;VALUE=DATE-TIME;TZID=Europe/Madrid:20240101T090000",
+			wantName:   "DTSTART",
+			wantParams: map[string]string{"VALUE": "DATE-TIME", "TZID": "Europe/Madrid"},
+			wantValue:  "20240101T090000",
+		},
+		{
+			name:       "Quoted parameter",
+			raw:        `ATTENDEE;CN="Doe, John: CEO";ROLE=CHAIR:mailto:john@example.com`,
+			wantName:   "ATTENDEE",
+			wantParams: map[string]string{"CN": "Doe, John: CEO", "ROLE": "CHAIR"},
+			wantValue:  "mailto:john@example.com",
+		},
+		{
+			name:    "Missing value",
+			raw:     "SUMMARY",
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated quote",
+			raw:     `X-A;P="value:x`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := parseContentLine(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, line.name)
+			assert.Equal(t, tt.wantParams, line.params)
+			assert.Equal(t, tt.wantValue, line.value)
+		})
+	}
+}
+
+func TestContentReader_next(t *testing.T) {
+	content := "BEGIN:VEVENT\r\nDESCRIPTION:first\r\n  second\r\n\tthird\r\n\r\nSUMMARY:ñ\r\n €\nEND:VEVENT"
+	cr := newContentReader(strings.NewReader(content))
+
+	var lines []*contentLine
+	for {
+		line, err := cr.next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		lines = append(lines, line)
+	}
+
+	assert.Len(t, lines, 4)
+	assert.Equal(t, "first second"+"third", lines[1].value)
+	assert.Equal(t, 2, lines[1].number)
+	assert.Equal(t, "ñ€", lines[2].value)
+	assert.Equal(t, 6, lines[2].number)
+	assert.Equal(t, "END", lines[3].name)
+}
+
+func TestUnescapeText(t *testing.T) {
+	assert.Equal(t, "a\\b;c,d\ne\nf", unescapeText(`a\\b\;c\,d\ne\Nf`))
+	assert.Equal(t, "a\nb", unescapeText(escapeText("a\nb")))
+}
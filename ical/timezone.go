@@ -0,0 +1,267 @@
+package ical
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sosalejandro/go-calendar/domain"
+)
+
+// zoneRange is a location used by the encoded tasks and the years it is used in
+type zoneRange struct {
+	loc              *time.Location
+	fromYear, toYear int
+}
+
+// include extends the range to the year of the time
+func (z *zoneRange) include(t time.Time) {
+	year := t.In(z.loc).Year()
+	if year < z.fromYear {
+		z.fromYear = year
+	}
+	if year > z.toYear {
+		z.toYear = year
+	}
+}
+
+// writeTimezone writes the VTIMEZONE of the location for the years of the range
+//
+// Every offset change within those years is written as its own observance,
+// derived from the time zone database.
+func (cw *contentWriter) writeTimezone(z *zoneRange) {
+	cw.writeLine("BEGIN", "VTIMEZONE")
+	cw.writeLine("TZID", z.loc.String())
+
+	start := time.Date(z.fromYear, time.January, 1, 0, 0, 0, 0, z.loc)
+	_, offset := start.Zone()
+	cw.writeObservance(start, offset)
+
+	end := time.Date(z.toYear+1, time.January, 1, 0, 0, 0, 0, z.loc)
+	for t := start; ; {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+
+		cw.writeObservance(next, offset)
+		_, offset = next.Zone()
+		t = next
+	}
+
+	cw.writeLine("END", "VTIMEZONE")
+}
+
+// writeObservance writes the STANDARD or DAYLIGHT observance starting at the
+// given time, coming from the given offset
+func (cw *contentWriter) writeObservance(start time.Time, offsetFrom int) {
+	kind := "STANDARD"
+	if start.IsDST() {
+		kind = "DAYLIGHT"
+	}
+
+	name, offsetTo := start.Zone()
+
+	cw.writeLine("BEGIN", kind)
+	// DTSTART is the local time of the change, before it takes effect
+	cw.writeLine("DTSTART", start.In(time.FixedZone("", offsetFrom)).Format(localLayout))
+	cw.writeLine("TZOFFSETFROM", formatOffset(offsetFrom))
+	cw.writeLine("TZOFFSETTO", formatOffset(offsetTo))
+	cw.writeLine("TZNAME", escapeText(name))
+	cw.writeLine("END", kind)
+}
+
+// formatOffset formats a UTC offset in seconds as a UTC-OFFSET value
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	hours, minutes, seconds := offset/3600, offset/60%60, offset%60
+	if seconds != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, hours, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%s%02d%02d", sign, hours, minutes)
+}
+
+// zoneUntil bounds the offset changes a VTIMEZONE rule is expanded to
+var zoneUntil = time.Date(2200, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// vtimezone is a time zone read from a VTIMEZONE component
+type vtimezone struct {
+	tzid        string
+	observances []observance
+	// loc caches the location built from the observances
+	loc *time.Location
+}
+
+// observance is a STANDARD or DAYLIGHT period of a VTIMEZONE
+//
+// Times are wall clock times of the zone, read as UTC.
+type observance struct {
+	onset      time.Time
+	rule       *domain.RecurrenceRule
+	rDates     []time.Time
+	offsetFrom int
+	offsetTo   int
+	name       string
+	daylight   bool
+}
+
+// transition is the instant an observance takes effect
+type transition struct {
+	at   int64
+	zone int
+}
+
+// onsets returns the instants the observance takes effect, up to zoneUntil
+//
+// Its onsets are wall clock times of the offset in effect before them.
+func (o *observance) onsets() []int64 {
+	walls := append([]time.Time{o.onset}, o.rDates...)
+	if o.rule != nil {
+		occurrences, err := o.rule.Between(o.onset, o.onset, zoneUntil)
+		if err == nil {
+			walls = append(walls, occurrences...)
+		}
+	}
+
+	onsets := make([]int64, 0, len(walls))
+	for _, wall := range walls {
+		onsets = append(onsets, wall.Unix()-int64(o.offsetFrom))
+	}
+
+	return onsets
+}
+
+// location returns the location of the zone, following every offset change
+// its observances define up to zoneUntil
+//
+// Before the first change, the zone has the offset of one of its standard observances.
+func (z *vtimezone) location() (*time.Location, error) {
+	if z.loc != nil {
+		return z.loc, nil
+	}
+
+	if len(z.observances) == 0 {
+		return nil, fmt.Errorf("time zone %s has no observances", z.tzid)
+	}
+
+	var zones []observance
+	var transitions []transition
+	for _, o := range z.observances {
+		zone := slices.IndexFunc(zones, func(other observance) bool {
+			return other.offsetTo == o.offsetTo && other.daylight == o.daylight && other.name == o.name
+		})
+		if zone < 0 {
+			zone = len(zones)
+			zones = append(zones, o)
+		}
+
+		for _, at := range o.onsets() {
+			transitions = append(transitions, transition{at: at, zone: zone})
+		}
+	}
+
+	slices.SortStableFunc(transitions, func(a, b transition) int { return cmp.Compare(a.at, b.at) })
+	transitions = slices.CompactFunc(transitions, func(a, b transition) bool { return a.at == b.at })
+
+	data, err := tzData(zones, transitions)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %s: %w", z.tzid, err)
+	}
+
+	loc, err := time.LoadLocationFromTZData(z.tzid, data)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %s: %w", z.tzid, err)
+	}
+
+	z.loc = loc
+	return loc, nil
+}
+
+// tzData encodes the zones and their transitions as version 2 TZif data (RFC 8536)
+func tzData(zones []observance, transitions []transition) ([]byte, error) {
+	var names []byte
+	nameIndex := make([]int, len(zones))
+	for i, zone := range zones {
+		name := zone.name
+		if name == "" {
+			name = formatOffset(zone.offsetTo)
+		}
+
+		nameIndex[i] = len(names)
+		names = append(append(names, name...), 0)
+	}
+
+	// Zones and their names are referenced by single bytes
+	if len(zones) > 256 || len(names) > 256 {
+		return nil, errors.New("too many observances")
+	}
+
+	header := func(buf []byte, transitions, zones, names int) []byte {
+		buf = append(buf, "TZif2"...)
+		buf = append(buf, make([]byte, 15)...)
+		// UT/local and standard/wall indicators, leap seconds
+		buf = binary.BigEndian.AppendUint32(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(transitions))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(zones))
+		return binary.BigEndian.AppendUint32(buf, uint32(names))
+	}
+
+	// The version 1 block is left empty, readers of version 2 skip it
+	buf := header(nil, 0, 0, 0)
+	buf = header(buf, len(transitions), len(zones), len(names))
+
+	for _, tr := range transitions {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(tr.at))
+	}
+	for _, tr := range transitions {
+		buf = append(buf, byte(tr.zone))
+	}
+	for i, zone := range zones {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(int32(zone.offsetTo)))
+		isDST := byte(0)
+		if zone.daylight {
+			isDST = 1
+		}
+		buf = append(buf, isDST, byte(nameIndex[i]))
+	}
+	buf = append(buf, names...)
+
+	// No footer: after the last transition its offset stays in effect
+	return append(buf, '\n', '\n'), nil
+}
+
+// parseOffset parses a UTC-OFFSET value such as "+0100" or "-033000"
+func parseOffset(value string) (int, error) {
+	if (len(value) != 5 && len(value) != 7) || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC offset %q", value)
+	}
+
+	parts := make([]int, 0, 3)
+	for i := 1; i < len(value); i += 2 {
+		n, err := strconv.Atoi(value[i : i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", value)
+		}
+		parts = append(parts, n)
+	}
+	parts = append(parts, 0)
+
+	offset := parts[0]*3600 + parts[1]*60 + parts[2]
+	if value[0] == '-' {
+		offset = -offset
+	}
+
+	return offset, nil
+}
@@ -0,0 +1,67 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMalformed is returned when the content is not a well formed iCalendar object
+var ErrMalformed = errors.New("malformed iCalendar content")
+
+// WarningKind is the kind of problem a Warning reports
+type WarningKind int
+
+const (
+	// UnsupportedComponent is a component which was skipped with all its content
+	UnsupportedComponent WarningKind = iota
+	// UnsupportedProperty is a property which was ignored
+	UnsupportedProperty
+	// InvalidProperty is a property whose value could not be read and was ignored
+	InvalidProperty
+	// InvalidComponent is a component which could not be turned into a task
+	InvalidComponent
+)
+
+// String returns the name of the kind
+func (k WarningKind) String() string {
+	switch k {
+	case UnsupportedComponent:
+		return "unsupported component"
+	case UnsupportedProperty:
+		return "unsupported property"
+	case InvalidProperty:
+		return "invalid property"
+	case InvalidComponent:
+		return "invalid component"
+	default:
+		return fmt.Sprintf("WarningKind(%d)", int(k))
+	}
+}
+
+// Warning reports part of an iCalendar object which was not imported
+//
+// Warnings don't fail the import, the rest of the object is still decoded.
+type Warning struct {
+	// Kind is the kind of problem
+	Kind WarningKind
+	// Line is the line the component or property starts on
+	Line int
+	// Name is the name of the component or property
+	Name string
+	// Err is the underlying error, if any
+	Err error
+}
+
+// Error returns the warning message including its line
+func (w *Warning) Error() string {
+	if w.Err == nil {
+		return fmt.Sprintf("line %d: %s %s", w.Line, w.Kind, w.Name)
+	}
+
+	return fmt.Sprintf("line %d: %s %s: %v", w.Line, w.Kind, w.Name, w.Err)
+}
+
+// Unwrap returns the underlying error
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
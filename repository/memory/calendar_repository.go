@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+var _ domain.CalendarRepository = (*CalendarRepository)(nil)
+
+// seriesRecord is the stored form of a series of a calendar
+type seriesRecord struct {
+	task     taskRecord
+	windowed bool
+	from, to time.Time
+}
+
+// calendarRecord is the stored form of a calendar
+type calendarRecord struct {
+	series []seriesRecord
+	// occurrences are the completed copies of the series
+	occurrences []taskRecord
+	zone        *time.Location
+	conflicts   domain.ConflictPolicy
+	// horizon is how far the unbounded series are placed at least
+	horizon time.Time
+}
+
+// CalendarRepository is an in-memory domain.CalendarRepository
+//
+// Only the series of a calendar and their completed occurrences are stored,
+// Load regenerates every other occurrence. A CalendarRepository is safe for
+// concurrent use.
+type CalendarRepository struct {
+	mu        sync.RWMutex
+	calendars map[uuid.UUID]calendarRecord
+}
+
+// NewCalendarRepository creates a new empty calendar repository
+func NewCalendarRepository() *CalendarRepository {
+	return &CalendarRepository{calendars: make(map[uuid.UUID]calendarRecord)}
+}
+
+// Save inserts the calendar or replaces the stored calendar with the same ID
+func (r *CalendarRepository) Save(ctx context.Context, calendar *domain.Calendar) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if calendar == nil {
+		return domain_errors.ErrCalendarCannotBeNil
+	}
+
+	record := calendarRecord{
+		zone:      calendar.GetTimeZone(),
+		conflicts: calendar.GetConflictPolicy(),
+		horizon:   calendar.GetHorizon(),
+	}
+	for _, task := range calendar.GetSeries() {
+		id := task.GetID()
+
+		series := seriesRecord{task: newTaskRecord(task)}
+		series.from, series.to, series.windowed = calendar.GetSeriesWindow(id.GetPrimaryID())
+
+		record.series = append(record.series, series)
+	}
+
+	for _, task := range calendar.GetCompletedOccurrences() {
+		record.occurrences = append(record.occurrences, newTaskRecord(task))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calendars[calendar.GetID()] = record
+
+	return nil
+}
+
+// Load returns the calendar with the given ID
+//
+// If there is no such calendar, Load returns domain_errors.ErrCalendarNotFound.
+// A series or occurrence which cannot be restored doesn't stop the others:
+// Load then returns the calendar with everything else restored, together
+// with the joined errors of the records left out.
+func (r *CalendarRepository) Load(ctx context.Context, id uuid.UUID) (*domain.Calendar, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	record, exists := r.calendars[id]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, domain_errors.ErrCalendarNotFound
+	}
+
+	calendar, err := domain.RehydrateCalendar(id)
+	if err != nil {
+		return nil, err
+	}
+	// The calendar has no tasks to move yet
+	if err := calendar.WithTimeZone(record.zone); err != nil {
+		return nil, err
+	}
+
+	// The series are placed up to the horizon as they are added
+	errc := calendar.ExtendHorizon(ctx, record.horizon)
+	for _, series := range record.series {
+		task, err := series.task.task()
+		if err != nil {
+			errc = errors.Join(errc, err)
+			continue
+		}
+
+		if series.windowed {
+			err = calendar.AddTaskBetween(ctx, task, series.from, series.to)
+		} else {
+			err = calendar.AddTask(ctx, task)
+		}
+		errc = errors.Join(errc, err)
+	}
+
+	occurrences := make([]*domain.Task, 0, len(record.occurrences))
+	for _, occurrence := range record.occurrences {
+		task, err := occurrence.task()
+		if err != nil {
+			errc = errors.Join(errc, err)
+			continue
+		}
+
+		occurrences = append(occurrences, task)
+	}
+	errc = errors.Join(errc, calendar.RestoreOccurrences(occurrences...))
+
+	// The stored series were accepted when they were added
+	calendar.WithConflictPolicy(record.conflicts)
+
+	return calendar, errc
+}
+
+// Delete removes the calendar with the given ID
+//
+// If there is no such calendar, Delete returns domain_errors.ErrCalendarNotFound.
+func (r *CalendarRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.calendars[id]; !exists {
+		return domain_errors.ErrCalendarNotFound
+	}
+
+	delete(r.calendars, id)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarRepository_roundTrip(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	daily, err := domain.NewTask(domain.NewTaskID(), "daily", "description", true, 24*time.Hour, start)
+	assert.NoError(t, err)
+	single := newTestTask(t, start.Add(time.Hour))
+
+	c := domain.NewCalendar()
+	assert.NoError(t, c.AddTaskBetween(ctx, daily, start, start.AddDate(0, 0, 7)))
+	assert.NoError(t, c.AddTask(ctx, single))
+
+	target, err := c.GetTasksOn(start.AddDate(0, 0, 3))
+	assert.NoError(t, err)
+	assert.NoError(t, c.DeleteTask(ctx, target[0], domain.ThisOccurrence))
+
+	done, err := c.GetTasksOn(start.AddDate(0, 0, 5))
+	assert.NoError(t, err)
+	assert.NoError(t, c.CompleteOccurrence(ctx, done[0]))
+
+	repo := NewCalendarRepository()
+	assert.NoError(t, repo.Save(ctx, c))
+
+	got, err := repo.Load(ctx, c.GetID())
+	assert.NoError(t, err)
+	assert.Equal(t, c.GetID(), got.GetID())
+
+	want := c.GetSeries()
+	assert.Len(t, got.GetSeries(), len(want))
+	for i, task := range got.GetSeries() {
+		assert.Equal(t, want[i].GetID(), task.GetID())
+		assert.Equal(t, want[i].GetExceptionDates(), task.GetExceptionDates())
+	}
+
+	// Completed occurrences are stored, every other one is regenerated
+	completed := got.GetCompletedOccurrences()
+	assert.Len(t, completed, 1)
+	assert.Equal(t, done[0].GetID(), completed[0].GetID())
+
+	for day := 0; day < 7; day++ {
+		date := start.AddDate(0, 0, day)
+		// A day emptied by an edit is not recreated
+		wantTasks, _ := c.GetTasksOn(date)
+		gotTasks, _ := got.GetTasksOn(date)
+		assert.Len(t, gotTasks, len(wantTasks), "day %d", day+1)
+	}
+}
+
+func TestCalendarRepository_Load_partial(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	c := domain.NewCalendar()
+	first, second := newTestTask(t, start), newTestTask(t, start.AddDate(0, 0, 1))
+	assert.NoError(t, c.AddTask(ctx, first))
+	assert.NoError(t, c.AddTask(ctx, second))
+	c.WithConflictPolicy(domain.RejectConflicts)
+
+	repo := NewCalendarRepository()
+	assert.NoError(t, repo.Save(ctx, c))
+
+	// Corrupt the record of the second series
+	record := repo.calendars[c.GetID()]
+	for i, series := range record.series {
+		if series.task.primaryId == second.GetID().GetPrimaryID() {
+			record.series[i].task.title = ""
+		}
+	}
+
+	got, err := repo.Load(ctx, c.GetID())
+	assert.ErrorIs(t, err, domain_errors.ErrTitleRequired)
+	assert.NotNil(t, got)
+
+	series := got.GetSeries()
+	assert.Len(t, series, 1)
+	assert.Equal(t, first.GetID(), series[0].GetID())
+	assert.Equal(t, domain.RejectConflicts, got.GetConflictPolicy())
+
+	_, err = got.GetTasksOn(start.AddDate(0, 0, 1))
+	assert.ErrorIs(t, err, domain_errors.ErrDayNotFound)
+}
+
+func TestCalendarRepository_timeZone(t *testing.T) {
+	ctx := context.Background()
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	// 20:00 UTC is already the next day in Tokyo
+	task := newTestTask(t, time.Date(2024, time.January, 1, 20, 0, 0, 0, time.UTC))
+
+	c := domain.NewCalendar()
+	assert.NoError(t, c.WithTimeZone(tokyo))
+	assert.NoError(t, c.AddTask(ctx, task))
+
+	repo := NewCalendarRepository()
+	assert.NoError(t, repo.Save(ctx, c))
+
+	got, err := repo.Load(ctx, c.GetID())
+	assert.NoError(t, err)
+	assert.Equal(t, tokyo, got.GetTimeZone())
+
+	tasks, err := got.GetTasksOn(time.Date(2024, time.January, 2, 0, 0, 0, 0, tokyo))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestCalendarRepository_conflictPolicy(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	// Overlapping tasks added before the policy was set
+	c := domain.NewCalendar()
+	assert.NoError(t, c.AddTask(ctx, newTestTask(t, start)))
+	assert.NoError(t, c.AddTask(ctx, newTestTask(t, start)))
+	c.WithConflictPolicy(domain.RejectConflicts)
+
+	repo := NewCalendarRepository()
+	assert.NoError(t, repo.Save(ctx, c))
+
+	got, err := repo.Load(ctx, c.GetID())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RejectConflicts, got.GetConflictPolicy())
+	assert.Len(t, got.GetSeries(), 2)
+
+	assert.ErrorIs(t, got.AddTask(ctx, newTestTask(t, start)), domain_errors.ErrConflict)
+}
+
+func TestCalendarRepository_horizon(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	extended := start.AddDate(2, 0, 0)
+
+	daily, err := domain.NewTask(domain.NewTaskID(), "daily", "description", true, 24*time.Hour, start)
+	assert.NoError(t, err)
+
+	c := domain.NewCalendar()
+	assert.NoError(t, c.AddTask(ctx, daily))
+	assert.NoError(t, c.ExtendHorizon(ctx, extended))
+
+	repo := NewCalendarRepository()
+	assert.NoError(t, repo.Save(ctx, c))
+
+	got, err := repo.Load(ctx, c.GetID())
+	assert.NoError(t, err)
+	assert.Equal(t, extended, got.GetHorizon())
+
+	tasks, err := got.GetTasksOn(extended.AddDate(0, 0, -1))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestCalendarRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCalendarRepository()
+	c := domain.NewCalendar()
+
+	assert.NoError(t, repo.Save(ctx, c))
+	assert.NoError(t, repo.Delete(ctx, c.GetID()))
+
+	_, err := repo.Load(ctx, c.GetID())
+	assert.ErrorIs(t, err, domain_errors.ErrCalendarNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, uuid.New()), domain_errors.ErrCalendarNotFound)
+	assert.ErrorIs(t, repo.Save(ctx, nil), domain_errors.ErrCalendarCannotBeNil)
+}
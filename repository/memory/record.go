@@ -0,0 +1,129 @@
+// Package memory provides in-memory adapters for the repository ports of the domain
+package memory
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosalejandro/go-calendar/domain"
+)
+
+// taskRecord is the stored form of a task
+//
+// Records are detached from the tasks they were made from, so a saved task
+// changed afterwards doesn't change what is stored.
+type taskRecord struct {
+	primaryId         uuid.UUID
+	secondaryId       uuid.UUID
+	original          bool
+	title             string
+	description       string
+	repeating         bool
+	repeatingInterval time.Duration
+	// rule is the RRULE of the task, empty if it does not repeat
+	rule           string
+	completed      bool
+	time           time.Time
+	duration       time.Duration
+	allDay         bool
+	priority       int
+	occurrenceTime time.Time
+	exDates        []time.Time
+	rDates         []time.Time
+	overrides      []taskRecord
+}
+
+// newTaskRecord creates the record of the task
+func newTaskRecord(task *domain.Task) taskRecord {
+	id := task.GetID()
+	repeating, interval := task.IsRepeating()
+
+	r := taskRecord{
+		primaryId:         id.GetPrimaryID(),
+		secondaryId:       id.GetSecondaryID(),
+		original:          id.IsOriginal(),
+		title:             task.GetTitle(),
+		description:       task.GetDescription(),
+		repeating:         repeating,
+		repeatingInterval: interval,
+		completed:         task.IsCompleted(),
+		time:              task.GetTime(),
+		duration:          task.GetDuration(),
+		allDay:            task.IsAllDay(),
+		priority:          task.GetPriority(),
+		occurrenceTime:    task.GetOccurrenceTime(),
+		exDates:           task.GetExceptionDates(),
+		rDates:            append([]time.Time(nil), task.GetRecurrenceDates()...),
+	}
+
+	if rule := task.GetRecurrenceRule(); rule != nil {
+		r.rule = rule.String()
+	}
+
+	for _, override := range task.GetOverrides() {
+		r.overrides = append(r.overrides, newTaskRecord(override))
+	}
+
+	return r
+}
+
+// task rehydrates the task of the record
+func (r taskRecord) task() (*domain.Task, error) {
+	id, err := domain.RehydrateTaskID(r.primaryId, r.secondaryId, r.original)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule *domain.RecurrenceRule
+	if r.rule != "" {
+		if rule, err = domain.ParseRecurrenceRule(r.rule); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := domain.RehydrateTask(
+		id,
+		r.title, r.description,
+		r.repeating, r.repeatingInterval,
+		rule,
+		r.completed,
+		r.time)
+	if err != nil {
+		return nil, err
+	}
+
+	var errc error
+	switch {
+	case r.allDay:
+		errc = task.SetAllDay(int(r.duration / (24 * time.Hour)))
+	case r.duration != 0:
+		errc = task.SetDuration(r.duration)
+	}
+
+	errc = errors.Join(errc, task.SetPriority(r.priority))
+
+	for _, exDate := range r.exDates {
+		errc = errors.Join(errc, task.ExcludeOccurrence(exDate))
+	}
+
+	for _, rDate := range r.rDates {
+		errc = errors.Join(errc, task.AddOccurrence(rDate))
+	}
+
+	for _, record := range r.overrides {
+		override, err := record.task()
+		if err != nil {
+			errc = errors.Join(errc, err)
+			continue
+		}
+
+		errc = errors.Join(errc, task.OverrideOccurrence(record.occurrenceTime, override))
+	}
+
+	if errc != nil {
+		return nil, errc
+	}
+
+	return task, nil
+}
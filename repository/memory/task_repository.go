@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+)
+
+var _ domain.TaskRepository = (*TaskRepository)(nil)
+
+// TaskRepository is an in-memory domain.TaskRepository
+//
+// A TaskRepository is safe for concurrent use.
+type TaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[domain.TaskID]taskRecord
+}
+
+// NewTaskRepository creates a new empty task repository
+func NewTaskRepository() *TaskRepository {
+	return &TaskRepository{tasks: make(map[domain.TaskID]taskRecord)}
+}
+
+// Save inserts the task or replaces the stored task with the same TaskID
+func (r *TaskRepository) Save(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if task == nil {
+		return domain_errors.ErrTaskCannotBeNil
+	}
+
+	record := newTaskRecord(task)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tasks[task.GetID()] = record
+
+	return nil
+}
+
+// FindByID returns the task with the given TaskID
+//
+// If there is no such task, FindByID returns domain_errors.ErrTaskNotFound.
+func (r *TaskRepository) FindByID(ctx context.Context, id domain.TaskID) (*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	record, exists := r.tasks[id]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, domain_errors.ErrTaskNotFound
+	}
+
+	return record.task()
+}
+
+// FindInRange returns the stored tasks whose time falls within [from, to), sorted by time
+//
+// Tasks at the same time are ordered by priority, then by ID, as domain.CompareTasks orders them.
+// If to is not after from, FindInRange returns domain_errors.ErrInvalidRange.
+func (r *TaskRepository) FindInRange(ctx context.Context, from, to time.Time) ([]*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !to.After(from) {
+		return nil, domain_errors.ErrInvalidRange
+	}
+
+	r.mu.RLock()
+	records := make([]taskRecord, 0)
+	for _, record := range r.tasks {
+		if !record.time.Before(from) && record.time.Before(to) {
+			records = append(records, record)
+		}
+	}
+	r.mu.RUnlock()
+
+	tasks := make([]*domain.Task, 0, len(records))
+	for _, record := range records {
+		task, err := record.task()
+		if err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	slices.SortFunc(tasks, domain.CompareTasks)
+
+	return tasks, nil
+}
+
+// Delete removes the task with the given TaskID
+//
+// If there is no such task, Delete returns domain_errors.ErrTaskNotFound.
+func (r *TaskRepository) Delete(ctx context.Context, id domain.TaskID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tasks[id]; !exists {
+		return domain_errors.ErrTaskNotFound
+	}
+
+	delete(r.tasks, id)
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sosalejandro/go-calendar/domain"
+	domain_errors "github.com/sosalejandro/go-calendar/domain/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTask(t *testing.T, taskTime time.Time) *domain.Task {
+	t.Helper()
+
+	task, err := domain.NewTask(
+		domain.NewTaskID(),
+		"title", "description",
+		false, 0,
+		taskTime)
+	assert.NoError(t, err)
+
+	return task
+}
+
+func TestTaskRepository_roundTrip(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	rule, err := domain.ParseRecurrenceRule("FREQ=DAILY;COUNT=10")
+	assert.NoError(t, err)
+
+	series, err := domain.NewRecurringTask(domain.NewTaskID(), "series", "description", rule, start)
+	assert.NoError(t, err)
+	assert.NoError(t, series.ExcludeOccurrence(start.AddDate(0, 0, 2)))
+	assert.NoError(t, series.AddOccurrence(start.AddDate(0, 0, 20)))
+
+	id := series.GetID()
+	overrideID, err := (&domain.CopyTaskIDFactory{}).CreateTaskID(id.GetPrimaryID().String())
+	assert.NoError(t, err)
+
+	override, err := domain.NewTask(
+		overrideID,
+		"moved", "description",
+		false, 0,
+		start.AddDate(0, 0, 4).Add(time.Hour))
+	assert.NoError(t, err)
+	override.Complete()
+	assert.NoError(t, override.SetDuration(90*time.Minute))
+	assert.NoError(t, override.SetPriority(1))
+	assert.NoError(t, series.OverrideOccurrence(start.AddDate(0, 0, 3), override))
+	assert.NoError(t, series.SetDuration(time.Hour))
+
+	repo := NewTaskRepository()
+	assert.NoError(t, repo.Save(ctx, series))
+
+	// Changes after saving are not stored
+	assert.NoError(t, series.ExcludeOccurrence(start.AddDate(0, 0, 5)))
+
+	got, err := repo.FindByID(ctx, id)
+	assert.NoError(t, err)
+
+	assert.Equal(t, id, got.GetID())
+	assert.Equal(t, "series", got.GetTitle())
+	assert.Equal(t, rule.String(), got.GetRecurrenceRule().String())
+	assert.Equal(t, []time.Time{start.AddDate(0, 0, 2)}, got.GetExceptionDates())
+	assert.Equal(t, []time.Time{start.AddDate(0, 0, 20)}, got.GetRecurrenceDates())
+
+	gotOverride, exists := got.GetOverride(start.AddDate(0, 0, 3))
+	assert.True(t, exists)
+	assert.Equal(t, override.GetID(), gotOverride.GetID())
+	assert.Equal(t, "moved", gotOverride.GetTitle())
+	assert.True(t, gotOverride.IsCompleted())
+	assert.Equal(t, start.AddDate(0, 0, 4).Add(time.Hour), gotOverride.GetTime())
+	assert.Equal(t, 90*time.Minute, gotOverride.GetDuration())
+	assert.Equal(t, 1, gotOverride.GetPriority())
+	assert.Equal(t, time.Hour, got.GetDuration())
+
+	allDay := newTestTask(t, start)
+	assert.NoError(t, allDay.SetAllDay(2))
+	assert.NoError(t, repo.Save(ctx, allDay))
+
+	gotAllDay, err := repo.FindByID(ctx, allDay.GetID())
+	assert.NoError(t, err)
+	assert.True(t, gotAllDay.IsAllDay())
+	assert.Equal(t, allDay.GetEndTime(), gotAllDay.GetEndTime())
+}
+
+func TestTaskRepository_FindInRange(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	repo := NewTaskRepository()
+	tasks := make([]*domain.Task, 4)
+	for i := len(tasks) - 1; i >= 0; i-- {
+		tasks[i] = newTestTask(t, start.AddDate(0, 0, i))
+		assert.NoError(t, repo.Save(ctx, tasks[i]))
+	}
+
+	// Tasks at the same time, saved from the lowest priority
+	sameTime := make([]*domain.Task, 3)
+	for i, priority := range []int{0, 5, 1} {
+		sameTime[2-i] = newTestTask(t, start.AddDate(0, 0, 10))
+		assert.NoError(t, sameTime[2-i].SetPriority(priority))
+		assert.NoError(t, repo.Save(ctx, sameTime[2-i]))
+	}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		want    []*domain.Task
+		wantErr error
+	}{
+		{
+			name: "Sorted by time",
+			from: start,
+			to:   start.AddDate(0, 0, 3),
+			want: tasks[:3],
+		},
+		{
+			name: "Excluding the end",
+			from: start.AddDate(0, 0, 1),
+			to:   start.AddDate(0, 0, 2),
+			want: tasks[1:2],
+		},
+		{
+			name: "Same time by priority",
+			from: start.AddDate(0, 0, 10),
+			to:   start.AddDate(0, 0, 11),
+			want: sameTime,
+		},
+		{
+			name: "Empty range",
+			from: start.AddDate(1, 0, 0),
+			to:   start.AddDate(2, 0, 0),
+			want: []*domain.Task{},
+		},
+		{
+			name:    "Invalid range",
+			from:    start,
+			to:      start,
+			wantErr: domain_errors.ErrInvalidRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindInRange(ctx, tt.from, tt.to)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+
+			assert.Len(t, got, len(tt.want))
+			for i := range got {
+				assert.Equal(t, tt.want[i].GetID(), got[i].GetID())
+			}
+		})
+	}
+}
+
+func TestTaskRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewTaskRepository()
+	task := newTestTask(t, time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, repo.Save(ctx, task))
+	assert.NoError(t, repo.Delete(ctx, task.GetID()))
+
+	_, err := repo.FindByID(ctx, task.GetID())
+	assert.ErrorIs(t, err, domain_errors.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, task.GetID()), domain_errors.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Save(ctx, nil), domain_errors.ErrTaskCannotBeNil)
+}